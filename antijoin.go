@@ -0,0 +1,62 @@
+package duckdb
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeleteMissing deletes the rows of the table of value without a match in
+// source, a sub-query or the name of a table, to sync a table to its source:
+//
+//	duckdb.DeleteMissing(db, &Product{}, db.Table("staging_products").Select("sku"), "sku")
+//	// DELETE FROM "products" WHERE NOT EXISTS (SELECT 1 FROM (SELECT sku FROM "staging_products") AS "source" WHERE "source"."sku" = "products"."sku")
+//
+// Rows are matched by columns, the primary key of value by default, which
+// source must select under the same names. Conditions set on db restrict the
+// rows deleted, and soft deletes apply as for Delete.
+func DeleteMissing(db *gorm.DB, value interface{}, source interface{}, columns ...string) *gorm.DB {
+	tx := db.Session(&gorm.Session{})
+
+	var from string
+	switch source.(type) {
+	case *gorm.DB:
+		from = "(?)"
+	case string:
+		source = clause.Table{Name: source.(string)}
+		from = "?"
+	default:
+		tx.AddError(fmt.Errorf("unsupported source %T, expected a sub-query or table name", source))
+		return tx
+	}
+
+	if len(columns) == 0 {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(value); err != nil {
+			tx.AddError(err)
+			return tx
+		}
+		if len(stmt.Schema.PrimaryFieldDBNames) == 0 {
+			tx.AddError(fmt.Errorf("%w: no columns to match %s by", gorm.ErrPrimaryKeyRequired, stmt.Table))
+			return tx
+		}
+		columns = stmt.Schema.PrimaryFieldDBNames
+	}
+
+	var (
+		conditions = make([]string, len(columns))
+		vars       = []interface{}{source, clause.Table{Name: "source"}}
+	)
+	for i, column := range columns {
+		conditions[i] = "? = ?"
+		vars = append(vars,
+			clause.Column{Table: "source", Name: column},
+			clause.Column{Table: clause.CurrentTable, Name: column},
+		)
+	}
+	return tx.Where(
+		"NOT EXISTS (SELECT 1 FROM "+from+" AS ? WHERE "+strings.Join(conditions, " AND ")+")", vars...,
+	).Delete(value)
+}
@@ -0,0 +1,81 @@
+package duckdb
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type syncProduct struct {
+	SKU   string `gorm:"primaryKey"`
+	Name  string
+	Stock int
+}
+
+type syncPrice struct {
+	Region string `gorm:"primaryKey"`
+	SKU    string `gorm:"primaryKey"`
+	Price  float64
+}
+
+func TestDeleteMissing(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&syncProduct{}, &syncPrice{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Exec("CREATE TABLE staging_products AS SELECT * FROM (VALUES ('a', 1), ('c', 0)) AS t(sku, stock)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	t.Run("sub-query", func(t *testing.T) {
+		products := []syncProduct{{SKU: "a", Stock: 1}, {SKU: "b", Stock: 2}, {SKU: "c"}, {SKU: "d", Stock: 4}}
+		if err := db.Create(&products).Error; err != nil {
+			t.Fatalf("failed to create records, got error %v", err)
+		}
+
+		result := DeleteMissing(db.Where("stock > ?", 3), &syncProduct{}, db.Table("staging_products").Select("sku"))
+		if result.Error != nil || result.RowsAffected != 1 {
+			t.Errorf("expected 1 row deleted, got %d, error %v", result.RowsAffected, result.Error)
+		}
+		var skus []string
+		if err := db.Model(&syncProduct{}).Order("sku").Pluck("sku", &skus).Error; err != nil || strings.Join(skus, ",") != "a,b,c" {
+			t.Errorf("expected the unmatched row outside the conditions to be kept, got %v, error %v", skus, err)
+		}
+
+		result = DeleteMissing(db, &syncProduct{}, db.Table("staging_products").Where("stock > 0").Select("sku"))
+		if result.Error != nil || result.RowsAffected != 2 {
+			t.Errorf("expected 2 rows deleted, got %d, error %v", result.RowsAffected, result.Error)
+		}
+	})
+
+	t.Run("table and columns", func(t *testing.T) {
+		if err := db.Exec("CREATE TABLE price_sources AS SELECT * FROM (VALUES ('eu', 'a'), ('us', 'b')) AS t(region, sku)").Error; err != nil {
+			t.Fatalf("failed to create table, got error %v", err)
+		}
+		prices := []syncPrice{{Region: "eu", SKU: "a"}, {Region: "eu", SKU: "b"}, {Region: "us", SKU: "b"}}
+		if err := db.Create(&prices).Error; err != nil {
+			t.Fatalf("failed to create records, got error %v", err)
+		}
+
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB { return DeleteMissing(tx, &syncPrice{}, "price_sources") })
+		if !strings.Contains(sql, `NOT EXISTS (SELECT 1 FROM "price_sources" AS "source" WHERE "source"."region" = "sync_prices"."region" AND "source"."sku" = "sync_prices"."sku")`) {
+			t.Errorf("expected anti-join on the primary key, got %v", sql)
+		}
+		result := DeleteMissing(db, &syncPrice{}, "price_sources")
+		if result.Error != nil || result.RowsAffected != 1 {
+			t.Errorf("expected 1 row deleted, got %d, error %v", result.RowsAffected, result.Error)
+		}
+
+		result = DeleteMissing(db, &syncPrice{}, "price_sources", "region")
+		if result.Error != nil || result.RowsAffected != 0 {
+			t.Errorf("expected no rows deleted, got %d, error %v", result.RowsAffected, result.Error)
+		}
+	})
+
+	t.Run("unsupported source", func(t *testing.T) {
+		if err := DeleteMissing(db, &syncPrice{}, 1).Error; err == nil {
+			t.Errorf("expected an error for an unsupported source")
+		}
+	})
+}
@@ -0,0 +1,235 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAppenderUnsupported is returned by NewAppender when the driver in use does
+// not provide DuckDB's appender API
+var ErrAppenderUnsupported = errors.New("the DuckDB driver does not support appenders")
+
+// DuplicateKeyError is returned by appenders when appended rows violate a
+// primary key or unique constraint, which discards all rows appended since the
+// last flush. It matches gorm.ErrDuplicatedKey with errors.Is.
+type DuplicateKeyError struct {
+	// Key is the conflicting key as reported by DuckDB, e.g. `id: 2`
+	Key string
+	// Rows are the indexes of the conflicting models of AppendModels, known
+	// when they were retried with AppenderOptions.InsertFallback
+	Rows []int
+	Err  error
+}
+
+func (e *DuplicateKeyError) Error() string {
+	if len(e.Rows) > 0 {
+		return fmt.Sprintf("duplicate key %q in rows %v: %v", e.Key, e.Rows, e.Err)
+	}
+	return fmt.Sprintf("duplicate key %q: %v", e.Key, e.Err)
+}
+
+func (e *DuplicateKeyError) Unwrap() []error {
+	return []error{gorm.ErrDuplicatedKey, e.Err}
+}
+
+var duplicateKeyPattern = regexp.MustCompile(`(?i)duplicate key "((?:[^"]|"")*)"`)
+
+// duplicateKeyError wraps err in a DuplicateKeyError if it reports a primary
+// key or unique constraint violation
+func duplicateKeyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var duplicate *DuplicateKeyError
+	if errors.As(err, &duplicate) {
+		return err
+	}
+	if match := duplicateKeyPattern.FindStringSubmatch(err.Error()); match != nil {
+		return &DuplicateKeyError{Key: strings.ReplaceAll(match[1], `""`, `"`), Err: err}
+	}
+	return err
+}
+
+// driverAppender is the appender API of the DuckDB driver
+type driverAppender interface {
+	AppendRow(args ...driver.Value) error
+	Flush() error
+	Close() error
+}
+
+// AppenderOptions bounds how long rows stay buffered in an Appender
+type AppenderOptions struct {
+	// FlushRows flushes after this many rows, zero leaves flushing to the driver
+	FlushRows int
+	// FlushInterval flushes rows that have been pending for this long, zero
+	// disables time based flushing
+	FlushInterval time.Duration
+	// InsertFallback makes AppendModels retry the rows of a flush that failed
+	// on a duplicate key one by one with INSERT statements, inserting all rows
+	// but the conflicting ones, which its DuplicateKeyError lists
+	InsertFallback bool
+}
+
+// Appender loads rows into a table through DuckDB's appender API, which is much
+// faster than INSERT statements. It holds a connection of the pool until closed
+// and is safe for concurrent use.
+type Appender struct {
+	mu       sync.Mutex
+	conn     *sql.Conn
+	shared   bool
+	appender driverAppender
+	options  AppenderOptions
+	pending  int
+	appended int
+	flushed  int
+	timer    *time.Timer
+	err      error
+}
+
+// NewAppender returns an appender for table, which may be qualified by a
+// schema. Without options, Config.AppenderFlushRows and
+// Config.AppenderFlushInterval apply. In a transaction of db, the rows are
+// appended in it.
+func NewAppender(db *gorm.DB, table string, options ...AppenderOptions) (*Appender, error) {
+	return newAppender(db, nil, table, options...)
+}
+
+// newAppender returns an appender for table on conn, which is left open by
+// Close, or on a connection of the pool without conn
+func newAppender(db *gorm.DB, conn *sql.Conn, table string, options ...AppenderOptions) (*Appender, error) {
+	a := &Appender{conn: conn, shared: conn != nil}
+	if len(options) > 0 {
+		a.options = options[0]
+	} else if dialector, ok := dialectorOf(db); ok {
+		a.options = AppenderOptions{FlushRows: dialector.AppenderFlushRows, FlushInterval: dialector.AppenderFlushInterval}
+	}
+
+	ctx := context.Background()
+	if db.Statement.Context != nil {
+		ctx = db.Statement.Context
+	}
+	// in transactions, the rows are appended on their connection, as with
+	// Config.ReadConns the pool has no other read-write connection to take
+	if tx, ok := db.Statement.ConnPool.(*interruptibleTx); ok && a.conn == nil {
+		a.conn, a.shared = tx.conn, true
+	}
+	if a.conn == nil {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		if a.conn, err = sqlDB.Conn(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	schema, name := "", table
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		schema, name = table[:i], table[i+1:]
+	}
+	if err := a.conn.Raw(func(driverConn interface{}) (err error) {
+		a.appender, err = newDriverAppender(driverConn, schema, name)
+		return err
+	}); err != nil {
+		a.release()
+		return nil, err
+	}
+	return a, nil
+}
+
+// AppendRow buffers a row, flushing according to the appender options
+func (a *Appender) AppendRow(values ...driver.Value) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.err != nil {
+		return a.err
+	}
+	if err := a.appender.AppendRow(values...); err != nil {
+		return err
+	}
+
+	a.appended++
+	a.pending++
+	if a.options.FlushRows > 0 && a.pending >= a.options.FlushRows {
+		return a.flush()
+	}
+	if a.options.FlushInterval > 0 && a.timer == nil {
+		a.timer = time.AfterFunc(a.options.FlushInterval, func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			if a.timer != nil && a.err == nil {
+				a.err = a.flush()
+			}
+		})
+	}
+	return nil
+}
+
+// Flush writes the buffered rows to the table
+func (a *Appender) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.err != nil {
+		return a.err
+	}
+	return a.flush()
+}
+
+func (a *Appender) flush() error {
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	a.pending = 0
+	if err := a.appender.Flush(); err != nil {
+		return duplicateKeyError(err)
+	}
+	a.flushed = a.appended
+	return nil
+}
+
+// progress returns the number of rows appended and the number of them flushed
+func (a *Appender) progress() (appended, flushed int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.appended, a.flushed
+}
+
+// Close flushes the buffered rows and releases the connection
+func (a *Appender) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	err := duplicateKeyError(a.appender.Close())
+	if err == nil && a.err == nil {
+		a.flushed = a.appended
+	}
+	if a.err == nil {
+		a.err = err
+	}
+	return errors.Join(err, a.release())
+}
+
+// release returns the connection of the appender to the pool, unless it is
+// shared
+func (a *Appender) release() error {
+	if a.shared {
+		return nil
+	}
+	return a.conn.Close()
+}
@@ -0,0 +1,92 @@
+package duckdb
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+const useAppenderKey = "duckdb:use_appender"
+
+// appenderUnsupportedTypes are the column types whose values the appender path
+// cannot convert, or the driver cannot append at all
+var appenderUnsupportedTypes = regexp.MustCompile(`(?i)^(STRUCT|MAP|UNION)\(|^(BIT|UHUGEINT|VARINT)$`)
+
+// WithAppender returns a scope that creates slices of records through the
+// appender instead of INSERT statements, as Config.UseAppender does for all
+// creates, e.g. db.Scopes(duckdb.WithAppender()).CreateInBatches(rows, 10000)
+func WithAppender() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.InstanceSet(useAppenderKey, true)
+	}
+}
+
+// create runs gorm's create, unless the records are loaded through the appender
+// as enabled by Config.UseAppender or WithAppender. Creates fall back to INSERT
+// statements when the appender cannot take them: for single records, upserts,
+// RETURNING clauses, selected or omitted fields and tables with columns the
+// appender does not support.
+func (dialector Dialector) create(db *gorm.DB) {
+	if !dialector.appendCreate(db) {
+		createCallback(db)
+	}
+}
+
+// appendCreate appends the records of db when the appender applies, and reports
+// whether it did
+func (dialector Dialector) appendCreate(db *gorm.DB) bool {
+	stmt := db.Statement
+	if _, ok := db.InstanceGet(useAppenderKey); !ok && !dialector.UseAppender {
+		return false
+	}
+	if db.Error != nil || db.DryRun || stmt.Schema == nil || stmt.SQL.Len() > 0 ||
+		len(stmt.Selects) > 0 || len(stmt.Omits) > 0 {
+		return false
+	}
+	if kind := stmt.ReflectValue.Kind(); kind != reflect.Slice && kind != reflect.Array ||
+		indirectType(stmt.ReflectValue.Type()) != stmt.Schema.ModelType {
+		return false
+	}
+	if _, ok := stmt.Clauses["ON CONFLICT"]; ok {
+		return false
+	}
+	// keys generated as UUIDs are returned by returnUUIDKeys, and filled in by
+	// the appender too
+	if _, ok := stmt.Clauses["RETURNING"]; ok && generatedUUIDKey(stmt.Schema) == nil {
+		return false
+	}
+
+	// in transactions, the rows are appended on their connection
+	var conn *sql.Conn
+	switch pool := stmt.ConnPool.(type) {
+	case *interruptibleTx:
+		conn = pool.conn
+	case *connPool:
+	default:
+		return false
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true, Context: stmt.Context})
+	tx.Statement.Table = stmt.Table
+	columns, err := loadAppenderColumns(tx, stmt.Table)
+	if err != nil {
+		db.AddError(err)
+		return true
+	}
+	for _, column := range columns {
+		if appenderUnsupportedTypes.MatchString(column.DataType) {
+			return false
+		}
+	}
+
+	rows, err := appendModels(tx, conn, stmt.Dest)
+	if errors.Is(err, ErrAppenderUnsupported) && rows == 0 {
+		return false
+	}
+	db.RowsAffected += rows
+	db.AddError(err)
+	return true
+}
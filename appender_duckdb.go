@@ -0,0 +1,32 @@
+//go:build cgo && !duckdb_nodriver
+
+package duckdb
+
+import (
+	"database/sql/driver"
+	"math/big"
+
+	goduckdb "github.com/marcboeker/go-duckdb"
+)
+
+func newDriverAppender(driverConn interface{}, schema, table string) (driverAppender, error) {
+	if c, ok := driverConn.(*conn); ok {
+		driverConn = c.Conn
+	}
+	if _, ok := driverConn.(*goduckdb.Conn); !ok {
+		return nil, ErrAppenderUnsupported
+	}
+	return goduckdb.NewAppenderFromConn(driverConn.(driver.Conn), schema, table)
+}
+
+func newDecimalValue(width, scale uint8, value *big.Int) driver.Value {
+	return goduckdb.Decimal{Width: width, Scale: scale, Value: value}
+}
+
+func newUUIDValue(id [16]byte) driver.Value {
+	return goduckdb.UUID(id)
+}
+
+func newIntervalValue(micros int64) driver.Value {
+	return goduckdb.Interval{Micros: micros}
+}
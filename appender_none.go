@@ -0,0 +1,24 @@
+//go:build !cgo || duckdb_nodriver
+
+package duckdb
+
+import (
+	"database/sql/driver"
+	"math/big"
+)
+
+func newDriverAppender(driverConn interface{}, schema, table string) (driverAppender, error) {
+	return nil, ErrAppenderUnsupported
+}
+
+func newDecimalValue(width, scale uint8, value *big.Int) driver.Value {
+	return value.String()
+}
+
+func newUUIDValue(id [16]byte) driver.Value {
+	return id[:]
+}
+
+func newIntervalValue(micros int64) driver.Value {
+	return micros
+}
@@ -0,0 +1,288 @@
+package duckdb
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func countRows(t *testing.T, db *gorm.DB, table string) (count int64) {
+	t.Helper()
+	if err := db.Table(table).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count rows, got error %v", err)
+	}
+	return
+}
+
+func TestAppender(t *testing.T) {
+	db := openTestDB(t, Config{AppenderFlushRows: 2})
+	if err := db.Exec("CREATE TABLE appender_records (id INTEGER, name VARCHAR)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	t.Run("flush rows", func(t *testing.T) {
+		appender, err := NewAppender(db, "main.appender_records")
+		if err != nil {
+			t.Fatalf("failed to create appender, got error %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			if err := appender.AppendRow(int32(i), "a"); err != nil {
+				t.Fatalf("failed to append row, got error %v", err)
+			}
+		}
+		if count := countRows(t, db, "appender_records"); count != 2 {
+			t.Errorf("expected 2 flushed rows, got %v", count)
+		}
+		if err := appender.Flush(); err != nil {
+			t.Fatalf("failed to flush, got error %v", err)
+		}
+		if count := countRows(t, db, "appender_records"); count != 3 {
+			t.Errorf("expected 3 flushed rows, got %v", count)
+		}
+		if err := appender.Close(); err != nil {
+			t.Errorf("failed to close appender, got error %v", err)
+		}
+	})
+
+	t.Run("flush interval", func(t *testing.T) {
+		appender, err := NewAppender(db, "appender_records", AppenderOptions{FlushInterval: 10 * time.Millisecond})
+		if err != nil {
+			t.Fatalf("failed to create appender, got error %v", err)
+		}
+		defer appender.Close()
+
+		if err := appender.AppendRow(int32(10), "b"); err != nil {
+			t.Fatalf("failed to append row, got error %v", err)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for countRows(t, db, "appender_records") != 4 {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the pending row to be flushed")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	if _, err := NewAppender(db, "missing_table"); err == nil {
+		t.Errorf("expected an error for a missing table")
+	}
+}
+
+type appenderStatus int
+
+type appenderModel struct {
+	ID     uint
+	Price  float64 `gorm:"type:DECIMAL(10,2)"`
+	Ref    string  `gorm:"type:UUID"`
+	At     time.Time
+	Data   []byte
+	Tags   []string `gorm:"type:VARCHAR[]"`
+	Wait   time.Duration
+	Note   *string
+	Status appenderStatus
+}
+
+func TestAppendModels(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec(`CREATE SEQUENCE appender_models_seq START 10;
+		CREATE TABLE appender_models (id INTEGER DEFAULT nextval('appender_models_seq'), price DECIMAL(10,2), ref UUID,
+		at TIMESTAMP, data BLOB, tags VARCHAR[], wait INTERVAL, note VARCHAR, status INTEGER)`).Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	note := "n"
+	at := time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+	models := []*appenderModel{
+		{Price: 12.345, Ref: "7d444840-9dc0-11d1-b245-5ffdce74fad2", At: at, Data: []byte{1, 2}, Tags: []string{"a", "b"}, Wait: 90 * time.Second, Note: &note, Status: 2},
+		{ID: 3, Price: -0.5, Ref: "{00000000-0000-0000-0000-000000000001}", At: at},
+	}
+	rows, err := AppendModels(db, &models)
+	if err != nil || rows != 2 {
+		t.Fatalf("failed to append models, got %v rows, error %v", rows, err)
+	}
+	if models[0].ID != 10 || models[1].ID != 3 {
+		t.Errorf("expected generated keys to be written back, got %v and %v", models[0].ID, models[1].ID)
+	}
+
+	var got []string
+	if err := db.Raw(`SELECT concat_ws('|', id, price, ref, epoch(at), hex(data), tags, wait, coalesce(note, 'NULL'), status)
+		FROM appender_models ORDER BY id`).Find(&got).Error; err != nil {
+		t.Fatalf("failed to read rows, got error %v", err)
+	}
+	want := []string{
+		"3|-0.50|00000000-0000-0000-0000-000000000001|1714534200.0||00:00:00|NULL|0",
+		"10|12.35|7d444840-9dc0-11d1-b245-5ffdce74fad2|1714534200.0|0102|[a, b]|00:01:30|n|2",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected rows\n%v\ngot\n%v", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	t.Run("row error context", func(t *testing.T) {
+		_, err := AppendModels(db, []appenderModel{{Ref: "7d444840-9dc0-11d1-b245-5ffdce74fad2"}, {Ref: "not-a-uuid"}})
+		if err == nil || !strings.Contains(err.Error(), `row 1, column "ref"`) {
+			t.Errorf("expected an error naming the row and column, got %v", err)
+		}
+	})
+}
+
+type appenderKeyed struct {
+	ID   uint `gorm:"primaryKey;autoIncrement:false"`
+	Name string
+}
+
+func TestAppendModels_duplicateKey(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE TABLE appender_keyeds (id INTEGER PRIMARY KEY, name VARCHAR)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	if err := db.Create(&appenderKeyed{ID: 2, Name: "existing"}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	models := []appenderKeyed{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}, {ID: 4, Name: "d"}, {ID: 3, Name: "e"}}
+	rows, err := AppendModels(db, models)
+	var duplicate *DuplicateKeyError
+	if !errors.Is(err, gorm.ErrDuplicatedKey) || !errors.As(err, &duplicate) || duplicate.Key != "id: 2" || rows != 0 {
+		t.Fatalf("expected a duplicate key error, got %v rows, error %v", rows, err)
+	}
+	if count := countRows(t, db, "appender_keyeds"); count != 1 {
+		t.Errorf("expected the batch to be discarded, got %v rows", count)
+	}
+
+	rows, err = AppendModels(db, models, AppenderOptions{FlushRows: 2, InsertFallback: true})
+	if !errors.As(err, &duplicate) || !reflect.DeepEqual(duplicate.Rows, []int{1, 4}) || rows != 3 {
+		t.Fatalf("expected the conflicting rows 1 and 4, got %v rows, error %v", rows, err)
+	}
+	var names []string
+	if err := db.Model(&appenderKeyed{}).Order("id").Pluck("name", &names).Error; err != nil {
+		t.Fatalf("failed to read rows, got error %v", err)
+	}
+	if strings.Join(names, ",") != "a,existing,c,d" {
+		t.Errorf("expected all but the conflicting rows, got %v", names)
+	}
+}
+
+type appenderCreated struct {
+	ID   uint
+	Name string
+}
+
+func TestDialector_create_appender(t *testing.T) {
+	db := openTestDB(t, Config{UseAppender: true})
+	if err := db.Exec(`CREATE SEQUENCE appender_createds_seq START 1;
+		CREATE TABLE appender_createds (id INTEGER DEFAULT nextval('appender_createds_seq') PRIMARY KEY, name VARCHAR)`).Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	create := func(t *testing.T, tx *gorm.DB, records []appenderCreated) (inserted bool) {
+		t.Helper()
+		recorder := &sqlRecorder{Interface: logger.Discard}
+		result := tx.Session(&gorm.Session{Logger: recorder}).CreateInBatches(records, 2)
+		if result.Error != nil || result.RowsAffected != int64(len(records)) {
+			t.Fatalf("failed to create records, got %v rows, error %v", result.RowsAffected, result.Error)
+		}
+		for i, record := range records {
+			if record.ID == 0 {
+				t.Errorf("expected the key of record %d to be set", i)
+			}
+		}
+		return recorder.contains("INSERT INTO")
+	}
+
+	if create(t, db, []appenderCreated{{Name: "a"}, {Name: "b"}, {Name: "c"}}) {
+		t.Errorf("expected the records to be appended")
+	}
+	if count := countRows(t, db, "appender_createds"); count != 3 {
+		t.Errorf("expected 3 rows, got %v", count)
+	}
+
+	t.Run("transaction", func(t *testing.T) {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if create(t, tx, []appenderCreated{{Name: "d"}, {Name: "e"}}) {
+				t.Errorf("expected the records to be appended in the transaction")
+			}
+			if count := countRows(t, tx, "appender_createds"); count != 5 {
+				t.Errorf("expected 5 rows in the transaction, got %v", count)
+			}
+			return errors.New("rollback")
+		})
+		if err == nil {
+			t.Fatalf("expected the transaction to fail")
+		}
+		if count := countRows(t, db, "appender_createds"); count != 3 {
+			t.Errorf("expected the rows to be rolled back, got %v rows", count)
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		if err := db.Exec("ALTER TABLE appender_createds ADD COLUMN attributes MAP(VARCHAR, INTEGER)").Error; err != nil {
+			t.Fatalf("failed to add column, got error %v", err)
+		}
+		defer db.Exec("ALTER TABLE appender_createds DROP COLUMN attributes")
+		if !create(t, db, []appenderCreated{{Name: "f"}, {Name: "g"}}) {
+			t.Errorf("expected the records to be inserted")
+		}
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		db := openTestDB(t, Config{})
+		if err := db.AutoMigrate(&appenderCreated{}); err != nil {
+			t.Fatalf("failed to migrate, got error %v", err)
+		}
+		if !create(t, db, []appenderCreated{{Name: "f"}}) {
+			t.Errorf("expected the records to be inserted without the scope")
+		}
+		if create(t, db.Scopes(WithAppender()), []appenderCreated{{Name: "g"}, {Name: "h"}, {Name: "i"}}) {
+			t.Errorf("expected the records to be appended with the scope")
+		}
+	})
+}
+
+type appenderTimed struct {
+	gorm.Model
+	Name         string
+	CreatedUnix  int64 `gorm:"autoCreateTime"`
+	UpdatedMilli int64 `gorm:"autoUpdateTime:milli"`
+}
+
+func TestDialector_create_appenderAutoTimes(t *testing.T) {
+	db := openTestDB(t, Config{UseAppender: true})
+	if err := db.AutoMigrate(&appenderTimed{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	created := now.Add(-time.Hour)
+	records := []appenderTimed{{Name: "a"}, {Name: "b"}, {Model: gorm.Model{CreatedAt: created}, Name: "c"}}
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	tx := db.Session(&gorm.Session{Logger: recorder, NowFunc: func() time.Time { return now }})
+	if err := tx.CreateInBatches(records, 2).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+	if recorder.contains("INSERT INTO") {
+		t.Errorf("expected the records to be appended")
+	}
+	for i, record := range records {
+		if record.UpdatedAt != now || record.CreatedUnix != now.Unix() || record.UpdatedMilli != now.UnixMilli() {
+			t.Errorf("expected the times of record %d to be set, got %+v", i, record)
+		}
+	}
+	if !records[0].CreatedAt.Equal(now) || !records[2].CreatedAt.Equal(created) {
+		t.Errorf("expected zero creation times to be set, got %v and %v", records[0].CreatedAt, records[2].CreatedAt)
+	}
+
+	var stored []appenderTimed
+	if err := db.Order("id").Find(&stored).Error; err != nil || len(stored) != 3 {
+		t.Fatalf("failed to find records, got %d, error %v", len(stored), err)
+	}
+	for i, record := range stored {
+		if !record.CreatedAt.Equal(records[i].CreatedAt) || !record.UpdatedAt.Equal(now) || record.CreatedUnix != now.Unix() || record.UpdatedMilli != now.UnixMilli() {
+			t.Errorf("expected the times of record %d to be stored, got %+v", i, record)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package duckdb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// appenderColumn is a table column as reported by duckdb_columns()
+type appenderColumn struct {
+	Name      string
+	DataType  string
+	Default   sql.NullString
+	Precision sql.NullInt64
+	Scale     sql.NullInt64
+}
+
+// loadAppenderColumns returns the columns of table in their physical order,
+// which is the order the appender expects values in
+func loadAppenderColumns(db *gorm.DB, table string) (columns []appenderColumn, err error) {
+	schemaCondition, args := "schema_name = current_schema()", []interface{}{}
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		schemaCondition, args, table = "schema_name = ?", append(args, table[:i]), table[i+1:]
+	}
+	rows, err := db.Session(&gorm.Session{NewDB: true}).Raw(
+		"SELECT column_name, data_type, column_default, numeric_precision, numeric_scale FROM duckdb_columns() WHERE "+
+			schemaCondition+" AND table_name = ? ORDER BY column_index",
+		append(args, table)...,
+	).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column appenderColumn
+		if err := rows.Scan(&column.Name, &column.DataType, &column.Default, &column.Precision, &column.Scale); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %q does not exist", table)
+	}
+	return columns, nil
+}
+
+// AppendModels loads value, a model or a slice of models, into its table through
+// the appender and returns the number of rows appended. Field values are
+// converted as on the SQL path, zero creation and update times are set to the
+// current time as gorm's create does, and zero fields that have a database
+// default get it, with generated keys written back into value. Hooks and associations
+// are skipped, and in a transaction of db the rows are appended in it.
+//
+// Options replace the appender options of the config. A flush that violates a
+// primary key or unique constraint discards the rows appended since the last
+// flush and fails with a DuplicateKeyError, unless InsertFallback retries them.
+func AppendModels(db *gorm.DB, value interface{}, options ...AppenderOptions) (int64, error) {
+	return appendModels(db, nil, value, options...)
+}
+
+// appendModels appends value through appenders on conn, or on connections of
+// the pool without conn
+func appendModels(db *gorm.DB, conn *sql.Conn, value interface{}, options ...AppenderOptions) (int64, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(value); err != nil {
+		return 0, err
+	}
+	table := stmt.Table
+	if db.Statement != nil && db.Statement.Table != "" {
+		table = db.Statement.Table
+	}
+
+	reflectValue := reflect.Indirect(reflect.ValueOf(value))
+	var models []reflect.Value
+	switch reflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < reflectValue.Len(); i++ {
+			models = append(models, reflect.Indirect(reflectValue.Index(i)))
+		}
+	case reflect.Struct:
+		models = append(models, reflectValue)
+	default:
+		return 0, fmt.Errorf("unsupported value %T, expected a model or a slice of models", value)
+	}
+	if len(models) == 0 {
+		return 0, nil
+	}
+	for i, model := range models {
+		// models passed by value are filled in on a copy
+		if !model.CanAddr() {
+			models[i] = reflect.New(model.Type()).Elem()
+			models[i].Set(model)
+		}
+	}
+	if err := setAutoTimes(db, stmt, models); err != nil {
+		return 0, err
+	}
+
+	columns, err := loadAppenderColumns(db, table)
+	if err != nil {
+		return 0, err
+	}
+	fields := make([]*schema.Field, len(columns))
+	for i, column := range columns {
+		fields[i] = stmt.Schema.LookUpField(column.Name)
+		if fields[i] != nil && fields[i].HasDefaultValue && column.Default.Valid {
+			if err := fillDefaults(db, stmt, fields[i], column, models); err != nil {
+				return 0, fmt.Errorf("column %q: %w", column.Name, err)
+			}
+		}
+	}
+
+	var (
+		ctx       = stmt.Context
+		row       = make([]driver.Value, len(columns))
+		rows      int64
+		duplicate *DuplicateKeyError
+	)
+	// start is the index of the first model given to the current appender
+	for start := 0; start < len(models); {
+		appender, err := newAppender(db, conn, table, options...)
+		if err != nil {
+			return rows, err
+		}
+
+		for i := start; i < len(models) && err == nil; i++ {
+			for j, column := range columns {
+				row[j] = nil
+				if fields[j] == nil || !fields[j].Creatable {
+					continue
+				}
+				fieldValue, _ := fields[j].ValueOf(ctx, models[i])
+				// lists and arrays are appended as they are rather than as
+				// the JSON of their serializer
+				switch fields[j].Serializer.(type) {
+				case ListSerializer, ArraySerializer:
+					fieldValue = fields[j].ReflectValueOf(ctx, models[i]).Interface()
+				}
+				if row[j], err = appenderValue(column.DataType, column, fieldValue); err != nil {
+					appender.Close()
+					return rows, fmt.Errorf("row %d, column %q: %w", i, column.Name, err)
+				}
+			}
+			if err = appender.AppendRow(row...); err != nil && !errors.As(err, new(*DuplicateKeyError)) {
+				appender.Close()
+				return rows, fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		if err == nil {
+			err = appender.Close()
+		} else {
+			appender.Close()
+		}
+		appended, flushed := appender.progress()
+		rows += int64(flushed)
+		if err == nil {
+			break
+		}
+
+		var conflict *DuplicateKeyError
+		if !errors.As(err, &conflict) || len(options) == 0 || !options[0].InsertFallback {
+			return rows, err
+		}
+		if duplicate == nil {
+			duplicate = conflict
+		}
+		failed := models[start+flushed : start+appended]
+		inserted, conflicts, err := insertModels(db, table, failed)
+		rows += inserted
+		if err != nil {
+			return rows, err
+		}
+		for _, index := range conflicts {
+			duplicate.Rows = append(duplicate.Rows, start+flushed+index)
+		}
+		start += appended
+	}
+	if duplicate != nil {
+		return rows, duplicate
+	}
+	return rows, nil
+}
+
+// insertModels inserts models one by one without hooks and associations, and
+// returns the indexes of the models rejected for a duplicate key
+func insertModels(db *gorm.DB, table string, models []reflect.Value) (inserted int64, conflicts []int, err error) {
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	for i, model := range models {
+		if !model.CanAddr() {
+			addressable := reflect.New(model.Type()).Elem()
+			addressable.Set(model)
+			model = addressable
+		}
+		result := tx.Table(table).Omit(clause.Associations).Create(model.Addr().Interface())
+		if err := duplicateKeyError(result.Error); err != nil {
+			if !errors.As(err, new(*DuplicateKeyError)) {
+				return inserted, conflicts, fmt.Errorf("row %d: %w", i, err)
+			}
+			conflicts = append(conflicts, i)
+			continue
+		}
+		inserted += result.RowsAffected
+	}
+	return inserted, conflicts, nil
+}
+
+// setAutoTimes sets the zero autoCreateTime and autoUpdateTime fields of models
+// to the time of db's NowFunc, in the unit of the field, like gorm's create.
+// Fields with a database default are left to it.
+func setAutoTimes(db *gorm.DB, stmt *gorm.Statement, models []reflect.Value) error {
+	now := db.NowFunc()
+	for _, field := range stmt.Schema.Fields {
+		if field.AutoCreateTime == 0 && field.AutoUpdateTime == 0 || field.HasDefaultValue && field.DefaultValueInterface == nil {
+			continue
+		}
+		for _, model := range models {
+			if _, isZero := field.ValueOf(stmt.Context, model); isZero {
+				if err := field.Set(stmt.Context, model, now); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fillDefaults evaluates the database default of column for the models whose
+// field is zero, as an INSERT omitting the field would, and sets it on them
+func fillDefaults(db *gorm.DB, stmt *gorm.Statement, field *schema.Field, column appenderColumn, models []reflect.Value) error {
+	var targets []reflect.Value
+	for _, model := range models {
+		if _, isZero := field.ValueOf(stmt.Context, model); isZero {
+			targets = append(targets, model)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	expr := column.Default.String
+	if field.IndirectFieldType.Kind() == reflect.String {
+		expr = "CAST(" + expr + " AS VARCHAR)"
+	}
+	// the count is inlined, DuckDB fails to commit nextval() in prepared statements
+	rows, err := db.Session(&gorm.Session{NewDB: true}).Raw(fmt.Sprintf("SELECT %s FROM range(%d)", expr, len(targets))).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for i := 0; rows.Next(); i++ {
+		var value interface{}
+		if err := rows.Scan(&value); err != nil {
+			return err
+		}
+		if err := field.Set(stmt.Context, targets[i], value); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+var listTypePattern = regexp.MustCompile(`^(.+)\[\d*\]$`)
+
+// appenderValue converts value, as returned for a model field, into the form
+// the appender accepts for a column of dataType. Pointers and driver.Valuer
+// implementations are resolved first, as database/sql does on the SQL path.
+func appenderValue(dataType string, column appenderColumn, value interface{}) (driver.Value, error) {
+	reflectValue := reflect.ValueOf(value)
+	if !reflectValue.IsValid() || (reflectValue.Kind() == reflect.Ptr && reflectValue.IsNil()) {
+		return nil, nil
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		return appenderValue(dataType, column, v)
+	}
+	for reflectValue.Kind() == reflect.Ptr {
+		if reflectValue.IsNil() {
+			return nil, nil
+		}
+		reflectValue = reflectValue.Elem()
+	}
+	value = reflectValue.Interface()
+
+	upper := strings.ToUpper(dataType)
+	switch {
+	case listTypePattern.MatchString(upper):
+		return listValue(listTypePattern.FindStringSubmatch(dataType)[1], column, reflectValue)
+	case strings.HasPrefix(upper, "DECIMAL"), strings.HasPrefix(upper, "NUMERIC"):
+		return decimalValue(column, reflectValue)
+	case upper == "UUID":
+		return uuidValue(reflectValue)
+	case upper == "INTERVAL":
+		if d, ok := value.(time.Duration); ok {
+			return newIntervalValue(d.Microseconds()), nil
+		}
+		return nil, fmt.Errorf("cannot convert %T to INTERVAL", value)
+	}
+	return primitiveValue(reflectValue), nil
+}
+
+// primitiveValue unwraps named basic types, which the appender only accepts
+// in their predeclared form
+func primitiveValue(v reflect.Value) driver.Value {
+	switch value := v.Interface().(type) {
+	case time.Time, []byte, string, bool:
+		return value
+	case big.Int:
+		return &value
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes()
+		}
+	}
+	return v.Interface()
+}
+
+func listValue(elemType string, column appenderColumn, v reflect.Value) (driver.Value, error) {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("cannot convert %s to a list", v.Type())
+	}
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return nil, nil
+	}
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		value, err := appenderValue(elemType, column, v.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		list[i] = value
+	}
+	return list, nil
+}
+
+func decimalValue(column appenderColumn, v reflect.Value) (driver.Value, error) {
+	var text string
+	switch v.Kind() {
+	case reflect.String:
+		text = v.String()
+	case reflect.Float32:
+		text = strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		text = strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		text = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		text = strconv.FormatUint(v.Uint(), 10)
+	default:
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			text = s.String()
+		}
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(text))
+	if !ok {
+		return nil, fmt.Errorf("cannot convert %s %q to %s", v.Type(), text, column.DataType)
+	}
+
+	precision, scale := column.Precision.Int64, column.Scale.Int64
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(scale), nil)))
+	unscaled, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	// round half away from zero like DuckDB's casts
+	if remainder.Abs(remainder).Lsh(remainder, 1).Cmp(r.Denom()) >= 0 {
+		unscaled.Add(unscaled, big.NewInt(int64(r.Sign())))
+	}
+	if digits := len(new(big.Int).Abs(unscaled).String()); precision > 0 && int64(digits) > precision {
+		return nil, fmt.Errorf("value %s is out of range for %s", text, column.DataType)
+	}
+	return newDecimalValue(uint8(precision), uint8(scale), unscaled), nil
+}
+
+func uuidValue(v reflect.Value) (driver.Value, error) {
+	var id [16]byte
+	switch {
+	case v.Kind() == reflect.Array && v.Len() == 16 && v.Type().Elem().Kind() == reflect.Uint8:
+		reflect.Copy(reflect.ValueOf(id[:]), v)
+		return newUUIDValue(id), nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 && v.Len() == 16:
+		copy(id[:], v.Bytes())
+		return newUUIDValue(id), nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		return parseUUID(string(v.Bytes()))
+	case v.Kind() == reflect.String:
+		return parseUUID(v.String())
+	}
+	return nil, fmt.Errorf("cannot convert %s to UUID", v.Type())
+}
+
+func parseUUID(s string) (driver.Value, error) {
+	var id [16]byte
+	text := strings.ReplaceAll(strings.Trim(s, "{}"), "-", "")
+	if len(text) != 32 {
+		return nil, fmt.Errorf("invalid UUID %q", s)
+	}
+	if _, err := hex.Decode(id[:], []byte(text)); err != nil {
+		return nil, fmt.Errorf("invalid UUID %q", s)
+	}
+	return newUUIDValue(id), nil
+}
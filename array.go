@@ -0,0 +1,90 @@
+package duckdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ArraySerializerName is the serializer storing a Go array or slice field in a
+// fixed-size ARRAY column, such as the embeddings of vector searches. Arrays
+// are sized after their type, slices after their type tag:
+//
+//	type Document struct {
+//		ID        uint
+//		Position  [3]float64 `gorm:"serializer:array"`
+//		Embedding []float32  `gorm:"serializer:array;type:float[384]"`
+//	}
+//	// CREATE TABLE "documents" ("id" UBIGINT, "position" DOUBLE[3], "embedding" float[384], ...)
+//
+// Elements are converted like the fields of EmbeddedStructSerializer. Nil
+// slices are stored as NULL, and values of another size fail to be written.
+// Like LIST columns, ARRAY columns can't be updated in tables with a primary
+// key.
+const ArraySerializerName = "array"
+
+func init() {
+	schema.RegisterSerializer(ArraySerializerName, ArraySerializer{})
+}
+
+// ArraySerializer converts array and slice fields to and from ARRAY columns;
+// values are bound as JSON and cast to the column's type
+type ArraySerializer struct{}
+
+// Scan assigns the ARRAY value read from the database to the field
+func (ArraySerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	return EmbeddedStructSerializer{}.Scan(ctx, field, dst, dbValue)
+}
+
+// Value returns the field as JSON, leaving nil slices NULL
+func (ArraySerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	return ListSerializer{}.Value(ctx, field, dst, fieldValue)
+}
+
+// isArray reports whether field has the array serializer
+func isArray(field *schema.Field) bool {
+	_, ok := field.Serializer.(ArraySerializer)
+	return ok
+}
+
+// ArrayValue binds a Go array or slice as a fixed-size ARRAY, built with Array
+type ArrayValue struct {
+	values interface{}
+}
+
+// Array binds values, a Go array or slice, as an ARRAY of their length, e.g. to
+// compare an embedding with those of a column:
+//
+//	db.Clauses(clause.OrderBy{Expression: clause.Expr{
+//		SQL:  "array_cosine_distance(embedding, ?)",
+//		Vars: []interface{}{duckdb.Array(query)},
+//	}}).Limit(10).Find(&documents)
+func Array(values interface{}) ArrayValue {
+	return ArrayValue{values: values}
+}
+
+// Build binds the elements as JSON cast to the ARRAY type of the values
+func (a ArrayValue) Build(builder clause.Builder) {
+	v := reflect.Indirect(reflect.ValueOf(a.values))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		builder.AddError(fmt.Errorf("unsupported value %T, expected an array or a slice", a.values))
+		return
+	}
+	elemType, err := structColumnType(v.Type().Elem())
+	if err != nil {
+		builder.AddError(err)
+		return
+	}
+	data, err := json.Marshal(structJSONValue(v))
+	if err != nil {
+		builder.AddError(err)
+		return
+	}
+	builder.AddVar(builder, string(data))
+	builder.WriteString("::JSON::" + elemType + "[" + strconv.Itoa(v.Len()) + "]")
+}
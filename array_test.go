@@ -0,0 +1,89 @@
+package duckdb
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type arrayDocument struct {
+	ID        uint
+	Position  [3]float64 `gorm:"serializer:array"`
+	Embedding []float32  `gorm:"serializer:array;type:float[4]"`
+}
+
+func TestArraySerializer(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&arrayDocument{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var types []string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'array_documents' AND column_name <> 'id' ORDER BY column_index").Scan(&types).Error; err != nil {
+		t.Fatalf("failed to query column types, got error %v", err)
+	}
+	if expected := []string{"DOUBLE[3]", "FLOAT[4]"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected ARRAY columns %v, got %v", expected, types)
+	}
+
+	documents := []arrayDocument{
+		{Position: [3]float64{1, 2.5, 3}, Embedding: []float32{1, 0, 0, 0}},
+		{Position: [3]float64{0, 0, 1}, Embedding: []float32{0, 1, 0, 0}},
+		{},
+	}
+	if err := db.Create(&documents).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+	var found []arrayDocument
+	if err := db.Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if !reflect.DeepEqual(found, documents) {
+		t.Errorf("expected %+v, got %+v", documents, found)
+	}
+
+	var nearest arrayDocument
+	if err := db.Where("embedding IS NOT NULL").Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:  "array_distance(embedding, ?)",
+		Vars: []interface{}{Array([]float32{0, 0.9, 0.1, 0})},
+	}}).Take(&nearest).Error; err != nil {
+		t.Fatalf("failed to find nearest record, got error %v", err)
+	}
+	if nearest.ID != documents[1].ID {
+		t.Errorf("expected nearest record %d, got %d", documents[1].ID, nearest.ID)
+	}
+
+	if err := db.Create(&arrayDocument{Embedding: []float32{1}}).Error; err == nil {
+		t.Errorf("expected an embedding of another size to fail")
+	}
+
+	recorder := &sqlRecorder{Interface: db.Logger}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&arrayDocument{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected ARRAY columns to be left as they are, got %v", recorder.sql)
+	}
+}
+
+func TestArraySerializer_appender(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&arrayDocument{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	documents := []arrayDocument{
+		{ID: 1, Position: [3]float64{1, 2, 3}, Embedding: []float32{0.5, 0, 0, 1}},
+		{ID: 2},
+	}
+	if _, err := AppendModels(db, &documents); err != nil {
+		t.Fatalf("failed to append records, got error %v", err)
+	}
+	var found []arrayDocument
+	if err := db.Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if !reflect.DeepEqual(found, documents) {
+		t.Errorf("expected %+v, got %+v", documents, found)
+	}
+}
@@ -0,0 +1,55 @@
+package duckdb
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// AttachOptions configure how Attach opens a database
+type AttachOptions struct {
+	// Type is the storage extension reading the file, e.g. sqlite or postgres,
+	// a DuckDB database by default
+	Type string
+	// ReadOnly attaches the database read-only
+	ReadOnly bool
+	// IfNotExists leaves an already attached database of the alias alone
+	IfNotExists bool
+}
+
+// Attach attaches the database at path under alias while db is open:
+//
+//	duckdb.Attach(db, "archive.duckdb", "archive", duckdb.AttachOptions{ReadOnly: true})
+//	db.Table("archive.orders").Find(&orders)
+//
+// Attached databases belong to the DuckDB instance, so they are visible to every
+// connection of the pool, and the Migrator finds the tables of names qualified
+// by alias, e.g. archive.orders or archive.main.orders.
+func Attach(db *gorm.DB, path, alias string, options ...AttachOptions) error {
+	var opts AttachOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	sql := "ATTACH "
+	if opts.IfNotExists {
+		sql += "IF NOT EXISTS "
+	}
+	sql += quoteString(path) + " AS " + quoteIdentifier(alias)
+	var settings []string
+	if opts.Type != "" {
+		settings = append(settings, "TYPE "+quoteIdentifier(opts.Type))
+	}
+	if opts.ReadOnly {
+		settings = append(settings, "READ_ONLY")
+	}
+	if len(settings) > 0 {
+		sql += " (" + strings.Join(settings, ", ") + ")"
+	}
+	return db.Session(&gorm.Session{NewDB: true}).Exec(sql).Error
+}
+
+// Detach detaches the database attached under alias
+func Detach(db *gorm.DB, alias string) error {
+	return db.Session(&gorm.Session{NewDB: true}).Exec("DETACH " + quoteIdentifier(alias)).Error
+}
@@ -0,0 +1,59 @@
+package duckdb
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+type attachedOrder struct {
+	Code  string  `gorm:"primaryKey"`
+	Total float64 `gorm:"index"`
+}
+
+func TestAttach(t *testing.T) {
+	db := openTestDB(t, Config{})
+	path := filepath.Join(t.TempDir(), "archive.duckdb")
+	if err := Attach(db, path, "archive"); err != nil {
+		t.Fatalf("failed to attach, got error %v", err)
+	}
+	if err := Attach(db, path, "archive", AttachOptions{IfNotExists: true}); err != nil {
+		t.Fatalf("expected attaching again to be skipped, got error %v", err)
+	}
+
+	tx := db.Table("archive.attached_orders")
+	for i := 0; i < 2; i++ {
+		if err := tx.AutoMigrate(&attachedOrder{}); err != nil {
+			t.Fatalf("failed to migrate (%d), got error %v", i, err)
+		}
+	}
+	if !tx.Migrator().HasTable(&attachedOrder{}) || db.Migrator().HasTable(&attachedOrder{}) {
+		t.Errorf("expected the table in the attached database only")
+	}
+	if !tx.Migrator().HasIndex(&attachedOrder{}, "Total") {
+		t.Errorf("expected the index in the attached database")
+	}
+	if !db.Table("archive.main.attached_orders").Migrator().HasTable(&attachedOrder{}) {
+		t.Errorf("expected the table by its fully qualified name")
+	}
+	if err := tx.Create(&attachedOrder{Code: "a", Total: 10}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	if err := Detach(db, "archive"); err != nil {
+		t.Fatalf("failed to detach, got error %v", err)
+	}
+	if err := db.Table("archive.attached_orders").Find(&[]attachedOrder{}).Error; err == nil {
+		t.Errorf("expected the detached table to be gone")
+	}
+
+	if err := Attach(db, path, "archive", AttachOptions{ReadOnly: true}); err != nil {
+		t.Fatalf("failed to attach read-only, got error %v", err)
+	}
+	var count int64
+	if err := tx.Model(&attachedOrder{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected the rows to be kept, got %d, error %v", count, err)
+	}
+	if err := tx.Create(&attachedOrder{Code: "b", Total: 20}).Error; err == nil {
+		t.Errorf("expected writes to a read-only database to fail")
+	}
+}
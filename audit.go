@@ -0,0 +1,62 @@
+package duckdb
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MigrationsLogTable is the table Config.AuditDDL records the statements of the
+// Migrator into
+const MigrationsLogTable = "schema_migrations_log"
+
+const ddlStartKey = "duckdb:ddl_start"
+
+// MigrationLog is a statement recorded into MigrationsLogTable
+type MigrationLog struct {
+	ExecutedAt time.Time
+	Statement  string
+	// DurationUS is the execution time in microseconds, the duration column is
+	// an INTERVAL
+	DurationUS int64 `gorm:"column:duration_us;->"`
+}
+
+func (MigrationLog) TableName() string {
+	return MigrationsLogTable
+}
+
+// MigrationLogs returns the statements recorded by Config.AuditDDL, oldest first
+func MigrationLogs(db *gorm.DB) (logs []MigrationLog, err error) {
+	err = db.Model(&MigrationLog{}).Select("executed_at, statement, epoch_us(duration) AS duration_us").
+		Order("executed_at").Find(&logs).Error
+	return logs, err
+}
+
+// auditDDL records a statement executed by the Migrator into MigrationsLogTable,
+// in the same transaction if any
+func (dialector Dialector) auditDDL(db *gorm.DB) {
+	if !dialector.AuditDDL || db.Error != nil || db.DryRun || !isMigratorStatement(db) {
+		return
+	}
+	start, ok := db.InstanceGet(ddlStartKey)
+	if !ok {
+		return
+	}
+	executedAt := start.(time.Time)
+
+	tx := unmarkedDB(db)
+	if err := tx.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (executed_at TIMESTAMP NOT NULL, statement VARCHAR NOT NULL, duration INTERVAL NOT NULL)",
+		quoteIdentifier(MigrationsLogTable),
+	)).Error; err != nil {
+		db.AddError(err)
+		return
+	}
+	if err := tx.Exec(
+		fmt.Sprintf("INSERT INTO %s VALUES (?, ?, to_microseconds(?))", quoteIdentifier(MigrationsLogTable)),
+		executedAt, dialector.Explain(db.Statement.SQL.String(), db.Statement.Vars...), time.Since(executedAt).Microseconds(),
+	).Error; err != nil {
+		db.AddError(err)
+	}
+}
@@ -0,0 +1,42 @@
+package duckdb
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestAuditDDL(t *testing.T) {
+	db := openTestDB(t, Config{AuditDDL: true})
+	if err := db.AutoMigrate(&hookRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Table("hook_records").AutoMigrate(&hookRecordV2{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Exec("CREATE TABLE unrelated (id INTEGER)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	logs, err := MigrationLogs(db)
+	if err != nil {
+		t.Fatalf("failed to read the log, got error %v", err)
+	}
+	if len(logs) != 2 || !strings.HasPrefix(logs[0].Statement, `CREATE TABLE "hook_records"`) ||
+		!strings.HasPrefix(logs[1].Statement, `ALTER TABLE "hook_records" ALTER COLUMN "score" TYPE DOUBLE`) {
+		t.Fatalf("expected the CREATE and ALTER statements to be logged, got %+v", logs)
+	}
+	for _, log := range logs {
+		if log.ExecutedAt.IsZero() || log.DurationUS <= 0 {
+			t.Errorf("expected a timestamp and duration, got %+v", log)
+		}
+	}
+
+	if err := db.Table("dry_run").Session(&gorm.Session{DryRun: true}).AutoMigrate(&hookRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if logs, _ := MigrationLogs(db); len(logs) != 2 {
+		t.Errorf("expected dry runs not to be logged, got %+v", logs)
+	}
+}
@@ -0,0 +1,123 @@
+package duckdb
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FindInBatches is like gorm's FindInBatches but pages by key ranges instead of
+// offsets for every kind of table: by the primary key, which may be composite,
+// or by DuckDB's rowid for tables without one. Processing a whole table stays
+// linear, and limits set on db are not supported outside the single primary
+// key case, which gorm handles itself.
+func FindInBatches(db *gorm.DB, dest interface{}, batchSize int, fc func(tx *gorm.DB, batch int) error) *gorm.DB {
+	tx := db.Session(&gorm.Session{})
+
+	model := tx.Statement.Model
+	if model == nil {
+		model = dest
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		tx.AddError(err)
+		return tx
+	}
+
+	switch len(stmt.Schema.PrimaryFields) {
+	case 1:
+		return tx.FindInBatches(dest, batchSize, fc)
+	case 0:
+		return findInRowIDBatches(tx, stmt, dest, batchSize, fc)
+	default:
+		return findInKeysetBatches(tx, stmt, dest, batchSize, fc)
+	}
+}
+
+// findInKeysetBatches pages through a table with a composite primary key using
+// row comparisons such as WHERE (a, b) > (?, ?)
+func findInKeysetBatches(tx *gorm.DB, stmt *gorm.Statement, dest interface{}, batchSize int, fc func(tx *gorm.DB, batch int) error) *gorm.DB {
+	var (
+		primaryFields = stmt.Schema.PrimaryFields
+		columns       = make([]string, len(primaryFields))
+		placeholders  = make([]string, len(primaryFields))
+		rowsAffected  int64
+	)
+	for i, field := range primaryFields {
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}})
+		columns[i] = stmt.Quote(clause.Column{Table: clause.CurrentTable, Name: field.DBName})
+		placeholders[i] = "?"
+	}
+	tx = tx.Session(&gorm.Session{})
+	keysetSQL := "(" + strings.Join(columns, ",") + ") > (" + strings.Join(placeholders, ",") + ")"
+
+	queryDB := tx
+	for batch := 1; ; batch++ {
+		result := queryDB.Limit(batchSize).Find(dest)
+		rowsAffected += result.RowsAffected
+		if result.Error != nil {
+			tx.AddError(result.Error)
+			break
+		}
+		if result.RowsAffected == 0 {
+			break
+		}
+
+		fcTx := result.Session(&gorm.Session{NewDB: true})
+		fcTx.RowsAffected = result.RowsAffected
+		if tx.AddError(fc(fcTx, batch)) != nil || int(result.RowsAffected) < batchSize {
+			break
+		}
+
+		results := reflect.Indirect(reflect.ValueOf(dest))
+		last := results.Index(results.Len() - 1)
+		values := make([]interface{}, len(primaryFields))
+		for i, field := range primaryFields {
+			values[i], _ = field.ValueOf(tx.Statement.Context, last)
+		}
+		queryDB = tx.Where(clause.Expr{SQL: keysetSQL, Vars: values})
+	}
+
+	tx.RowsAffected = rowsAffected
+	return tx
+}
+
+// findInRowIDBatches pages through a table without primary key by ranges of
+// rowid. Batches may hold fewer rows than batchSize where rows were deleted.
+func findInRowIDBatches(tx *gorm.DB, stmt *gorm.Statement, dest interface{}, batchSize int, fc func(tx *gorm.DB, batch int) error) *gorm.DB {
+	var maxRowID sql.NullInt64
+	if err := tx.Session(&gorm.Session{NewDB: true}).Table(stmt.Table).Select("max(rowid)").Scan(&maxRowID).Error; err != nil {
+		tx.AddError(err)
+		return tx
+	}
+
+	var (
+		rowsAffected int64
+		batch        int
+	)
+	tx = tx.Order("rowid").Session(&gorm.Session{})
+	for start := int64(0); maxRowID.Valid && start <= maxRowID.Int64; start += int64(batchSize) {
+		result := tx.Where("rowid >= ? AND rowid < ?", start, start+int64(batchSize)).Find(dest)
+		rowsAffected += result.RowsAffected
+		if result.Error != nil {
+			tx.AddError(result.Error)
+			break
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		batch++
+		fcTx := result.Session(&gorm.Session{NewDB: true})
+		fcTx.RowsAffected = result.RowsAffected
+		if tx.AddError(fc(fcTx, batch)) != nil {
+			break
+		}
+	}
+
+	tx.RowsAffected = rowsAffected
+	return tx
+}
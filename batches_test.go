@@ -0,0 +1,74 @@
+package duckdb
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type batchCompositeRecord struct {
+	TenantID int `gorm:"primaryKey"`
+	ID       int `gorm:"primaryKey"`
+}
+
+type batchEvent struct {
+	Name string
+	Seq  int
+}
+
+func TestFindInBatches(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE TABLE batch_composite_records (tenant_id INTEGER, id INTEGER, PRIMARY KEY (tenant_id, id))").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	if err := db.Exec("INSERT INTO batch_composite_records SELECT range % 3, range FROM range(25)").Error; err != nil {
+		t.Fatalf("failed to insert records, got error %v", err)
+	}
+	if err := db.Exec("CREATE TABLE batch_events AS SELECT 'e' || range AS name, range AS seq FROM range(25)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	if err := db.Exec("DELETE FROM batch_events WHERE seq BETWEEN 10 AND 19").Error; err != nil {
+		t.Fatalf("failed to delete records, got error %v", err)
+	}
+
+	t.Run("composite primary key", func(t *testing.T) {
+		var (
+			records []batchCompositeRecord
+			seen    = map[int]bool{}
+			batches int
+		)
+		result := FindInBatches(db, &records, 10, func(tx *gorm.DB, batch int) error {
+			batches = batch
+			for _, record := range records {
+				if seen[record.ID] {
+					t.Errorf("record %v visited twice", record.ID)
+				}
+				seen[record.ID] = true
+			}
+			return nil
+		})
+		if result.Error != nil || result.RowsAffected != 25 || len(seen) != 25 || batches != 3 {
+			t.Errorf("expected 25 records in 3 batches, got %v records, %v batches, rows affected %v, error %v",
+				len(seen), batches, result.RowsAffected, result.Error)
+		}
+	})
+
+	t.Run("without primary key", func(t *testing.T) {
+		var (
+			events []batchEvent
+			seen   = map[int]bool{}
+		)
+		result := FindInBatches(db, &events, 10, func(tx *gorm.DB, batch int) error {
+			for _, event := range events {
+				if seen[event.Seq] {
+					t.Errorf("event %v visited twice", event.Seq)
+				}
+				seen[event.Seq] = true
+			}
+			return nil
+		})
+		if result.Error != nil || result.RowsAffected != 15 || len(seen) != 15 {
+			t.Errorf("expected 15 events, got %v, rows affected %v, error %v", len(seen), result.RowsAffected, result.Error)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package duckdb
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// isDateField reports whether field has the date type tag and is stored in a
+// DATE column
+func isDateField(field *schema.Field) bool {
+	return strings.EqualFold(string(field.DataType), "date")
+}
+
+// isTimeOfDayField reports whether field has the time type tag and is stored in
+// a TIME column. gorm gives such fields the data type of time.Time fields, so
+// the tag itself is checked.
+func isTimeOfDayField(field *schema.Field) bool {
+	return strings.EqualFold(field.TagSettings["TYPE"], "time")
+}
+
+// bindValue returns the value of field as it is bound to its column, for the
+// columns whose values the driver would bind as other types: durations of
+// INTERVAL columns as intervals, and times of DATE and TIME columns as text in
+// their own zone, so that they are truncated to the date and time they show
+// rather than to those in UTC
+func bindValue(field *schema.Field, value interface{}) interface{} {
+	switch {
+	case isIntervalField(field):
+		return intervalValue(value)
+	case isDateField(field):
+		return timeText(value, time.DateOnly)
+	case isTimeOfDayField(field):
+		return timeText(value, "15:04:05.999999")
+	}
+	return value
+}
+
+// timeText formats times with layout, leaving other values as they are
+func timeText(value interface{}, layout string) interface{} {
+	switch t := value.(type) {
+	case time.Time:
+		return t.Format(layout)
+	case *time.Time:
+		if t != nil {
+			return t.Format(layout)
+		}
+	}
+	return value
+}
+
+// bindValues binds the values of the columns in values with bindValue
+func bindValues(stmt *gorm.Statement, values clause.Values) clause.Values {
+	if stmt.Schema == nil {
+		return values
+	}
+	var fields []*schema.Field
+	var indexes []int
+	for i, column := range values.Columns {
+		if field := stmt.Schema.LookUpField(column.Name); field != nil &&
+			(isIntervalField(field) || isDateField(field) || isTimeOfDayField(field)) {
+			fields = append(fields, field)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 {
+		return values
+	}
+
+	rows := make([][]interface{}, len(values.Values))
+	for i, row := range values.Values {
+		rows[i] = append([]interface{}{}, row...)
+		for j, index := range indexes {
+			rows[i][index] = bindValue(fields[j], row[index])
+		}
+	}
+	values.Values = rows
+	return values
+}
@@ -0,0 +1,75 @@
+package duckdb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type bindShift struct {
+	ID     uint
+	Day    time.Time  `gorm:"type:date"`
+	Starts time.Time  `gorm:"type:time"`
+	Ends   *time.Time `gorm:"type:TIME"`
+	Until  *time.Time `gorm:"type:date"`
+}
+
+func TestDateAndTimeColumns(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&bindShift{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var types []string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'bind_shifts' AND column_name <> 'id' ORDER BY column_index").Scan(&types).Error; err != nil {
+		t.Fatalf("failed to query column types, got error %v", err)
+	}
+	if expected := []string{"DATE", "TIME", "TIME", "DATE"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected DATE and TIME columns %v, got %v", expected, types)
+	}
+
+	// the date and time in Tokyo fall on the previous day in UTC
+	tokyo := time.FixedZone("JST", 9*60*60)
+	starts := time.Date(2024, 5, 1, 0, 30, 15, 250000000, tokyo)
+	ends := starts.Add(8 * time.Hour)
+	shift := bindShift{Day: starts, Starts: starts, Ends: &ends}
+	if err := db.Create(&shift).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	var text string
+	if err := db.Raw("SELECT concat_ws(' ', day, starts, ends, until) FROM bind_shifts").Scan(&text).Error; err != nil {
+		t.Fatalf("failed to read values, got error %v", err)
+	}
+	if text != "2024-05-01 00:30:15.25 08:30:15.25" {
+		t.Errorf("expected the date and times in their own zone, got %q", text)
+	}
+
+	var found bindShift
+	if err := db.First(&found, shift.ID).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if !found.Day.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || found.Until != nil {
+		t.Errorf("expected the date at midnight, got %v, %v", found.Day, found.Until)
+	}
+	if h, m, s := found.Starts.Clock(); h != 0 || m != 30 || s != 15 || found.Ends == nil || found.Ends.Hour() != 8 {
+		t.Errorf("expected the times of day, got %v, %v", found.Starts, found.Ends)
+	}
+
+	until := time.Date(2024, 6, 30, 23, 0, 0, 0, tokyo)
+	if err := db.Model(&found).Update("until", &until).Error; err != nil {
+		t.Fatalf("failed to update record, got error %v", err)
+	}
+	if err := db.Raw("SELECT until::VARCHAR FROM bind_shifts").Scan(&text).Error; err != nil || text != "2024-06-30" {
+		t.Errorf("expected the updated date in its own zone, got %q, error %v", text, err)
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&bindShift{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected DATE and TIME columns to be left as they are, got %v", recorder.sql)
+	}
+}
@@ -0,0 +1,69 @@
+package duckdb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// Bitstring is a string of bits such as "0101", stored in a BIT column:
+//
+//	type Device struct {
+//		ID    uint
+//		Flags duckdb.Bitstring
+//	}
+//
+// String fields with the bit or bitstring type tag get BIT columns too. As the
+// driver cannot read BIT values, queries of models with such fields select BIT
+// columns as text, see selectTextColumns; raw queries must cast them to VARCHAR.
+type Bitstring string
+
+// GormDataType returns the data type of Bitstring fields
+func (Bitstring) GormDataType() string {
+	return "bit"
+}
+
+// Len returns the number of bits of b
+func (b Bitstring) Len() int {
+	return len(b)
+}
+
+// Bit reports whether bit i of b, counted from the left, is set
+func (b Bitstring) Bit(i int) bool {
+	return b[i] == '1'
+}
+
+// Scan assigns a BIT value read as text to b
+func (b *Bitstring) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case string:
+		*b = Bitstring(value)
+	case []byte:
+		*b = Bitstring(value)
+	case nil:
+		*b = ""
+	default:
+		return fmt.Errorf("unsupported value %T, expected a BIT value read as text", src)
+	}
+	return nil
+}
+
+// Value returns b as text, which DuckDB casts to the BIT column, or NULL for
+// the empty bitstring, which BIT columns cannot hold
+func (b Bitstring) Value() (driver.Value, error) {
+	if b == "" {
+		return nil, nil
+	}
+	if strings.Trim(string(b), "01") != "" {
+		return nil, fmt.Errorf("invalid bitstring %q, expected only 0 and 1", string(b))
+	}
+	return string(b), nil
+}
+
+// isBitField reports whether field is stored in a BIT column: it is a
+// Bitstring or has the bit or bitstring type tag
+func isBitField(field *schema.Field) bool {
+	return strings.EqualFold(string(field.DataType), "bit") || strings.EqualFold(string(field.DataType), "bitstring")
+}
@@ -0,0 +1,67 @@
+package duckdb
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type bitDevice struct {
+	ID     uint
+	Flags  Bitstring
+	Mask   string `gorm:"type:bitstring"`
+	Labels *Bitstring
+}
+
+func TestBitstring(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&bitDevice{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var types []string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'bit_devices' AND column_name <> 'id' ORDER BY column_index").Scan(&types).Error; err != nil {
+		t.Fatalf("failed to query column types, got error %v", err)
+	}
+	if expected := []string{"BIT", "BIT", "BIT"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected BIT columns %v, got %v", expected, types)
+	}
+
+	devices := []bitDevice{{Flags: "0101", Mask: "1111000011"}, {Flags: "1", Mask: "0"}, {Mask: "1"}}
+	if err := db.Create(&devices).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+	var found []bitDevice
+	if err := db.Where("flags = ?", Bitstring("0101")).Find(&found).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if !reflect.DeepEqual(found, devices[:1]) {
+		t.Errorf("expected %+v, got %+v", devices[:1], found)
+	}
+	if flags := found[0].Flags; flags.Len() != 4 || flags.Bit(0) || !flags.Bit(1) {
+		t.Errorf("expected bits 0101, got %q", flags)
+	}
+
+	var count int64
+	if err := db.Model(&bitDevice{}).Where("bit_count(mask) > ?", 3).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected 1 record with more than 3 bits set, got %d, error %v", count, err)
+	}
+
+	var nulls int64
+	db.Raw("SELECT count(*) FROM bit_devices WHERE flags IS NULL").Scan(&nulls)
+	if nulls != 1 {
+		t.Errorf("expected empty bitstrings to be stored as NULL, got %d NULL rows", nulls)
+	}
+
+	if err := db.Create(&bitDevice{Flags: "012"}).Error; err == nil {
+		t.Errorf("expected an invalid bitstring to fail")
+	}
+
+	recorder := &sqlRecorder{Interface: db.Logger}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&bitDevice{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected BIT columns to be left as they are, got %v", recorder.sql)
+	}
+}
@@ -0,0 +1,209 @@
+package duckdb
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// defaultBlobChunkSize is the size of the chunks blobs are written and read in
+// by default, a multiple of 3 like every chunk size
+const defaultBlobChunkSize = 3 << 18
+
+// blobChunksTable is the temporary table WriteBlob stages chunks in
+const blobChunksTable = "gorm_blob_chunks"
+
+// BlobOptions configure how WriteBlob and OpenBlob transfer a blob
+type BlobOptions struct {
+	// ChunkSize is the number of bytes sent or received per statement, 768KiB by
+	// default. It is rounded down to a multiple of 3, as the chunks are cut from
+	// and joined in base64, which encodes 3 bytes in 4 digits
+	ChunkSize int
+}
+
+func (o BlobOptions) chunkSize() int {
+	if o.ChunkSize >= 3 {
+		return o.ChunkSize - o.ChunkSize%3
+	} else if o.ChunkSize > 0 {
+		return 3
+	}
+	return defaultBlobChunkSize
+}
+
+// WriteBlob sets the BLOB column of the row of model, found by its primary key,
+// to the content of r, which is read a chunk at a time so that large payloads
+// are never held in memory whole:
+//
+//	file, _ := os.Open("scan.tiff")
+//	defer file.Close()
+//	n, err := duckdb.WriteBlob(db, &Document{ID: 1}, "content", file)
+//
+// The chunks are staged in a temporary table and joined into the column in a
+// transaction, so the column keeps its previous value when r fails. DuckDB has
+// no aggregate concatenating blobs, so they are joined in base64 by string_agg,
+// in time linear in the size of the blob. It returns the number of bytes
+// written.
+func WriteBlob(db *gorm.DB, model interface{}, column string, r io.Reader, options ...BlobOptions) (int64, error) {
+	var opts BlobOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	var written int64
+	err := db.Session(&gorm.Session{NewDB: true}).Transaction(func(tx *gorm.DB) error {
+		row, field, err := blobRow(tx, model, column)
+		if err != nil {
+			return err
+		}
+		chunks := clause.Table{Name: blobChunksTable}
+		if err := tx.Exec("CREATE OR REPLACE TEMP TABLE ? (seq INTEGER, data BLOB)", chunks).Error; err != nil {
+			return err
+		}
+		defer tx.Exec("DROP TABLE IF EXISTS ?", chunks)
+
+		buf := make([]byte, opts.chunkSize())
+		for seq := 0; ; seq++ {
+			n, err := io.ReadFull(r, buf)
+			if n > 0 {
+				if err := tx.Exec("INSERT INTO ? VALUES (?, ?)", chunks, seq, buf[:n]).Error; err != nil {
+					return err
+				}
+				written += int64(n)
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			} else if err != nil {
+				return err
+			}
+		}
+
+		value := interface{}([]byte{})
+		if written > 0 {
+			value = tx.Raw("SELECT from_base64(string_agg(to_base64(data), '' ORDER BY seq)) FROM ?", chunks)
+		}
+		result := row.UpdateColumn(field.DBName, value)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return written, nil
+}
+
+// BlobReader reads a blob a chunk at a time, as returned by OpenBlob
+type BlobReader struct {
+	rows  *sql.Rows
+	size  int64
+	chunk []byte
+}
+
+// OpenBlob returns a reader of the BLOB column of the row of model, found by its
+// primary key, receiving the blob a chunk at a time so that large payloads are
+// never held in memory whole:
+//
+//	blob, err := duckdb.OpenBlob(db, &Document{ID: 1}, "content")
+//	if err != nil {
+//		...
+//	}
+//	defer blob.Close()
+//	io.Copy(w, blob)
+//
+// A NULL blob reads as empty. The reader holds a connection of the pool until
+// it is closed.
+func OpenBlob(db *gorm.DB, model interface{}, column string, options ...BlobOptions) (*BlobReader, error) {
+	var opts BlobOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true})
+	row, field, err := blobRow(tx, model, column)
+	if err != nil {
+		return nil, err
+	}
+	var size []sql.NullInt64
+	if err := row.Session(&gorm.Session{}).Select("octet_length(?)", clause.Column{Name: field.DBName}).Find(&size).Error; err != nil {
+		return nil, err
+	} else if len(size) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	// DuckDB has no substring of blobs, so the chunks are cut from its base64
+	// encoding, encoded once and 4 digits per 3 bytes
+	digits := 4 * int64(opts.chunkSize()/3)
+	chunks := (4*((size[0].Int64+2)/3) + digits - 1) / digits
+	rows, err := tx.Raw("SELECT from_base64(substring(blob.digits, chunks.i * ? + 1, ?)) FROM (?) AS blob, range(0, ?) AS chunks(i) ORDER BY chunks.i",
+		digits, digits, row.Select("to_base64(?) AS digits", clause.Column{Name: field.DBName}), chunks).Rows()
+	if err != nil {
+		return nil, err
+	}
+	return &BlobReader{rows: rows, size: size[0].Int64}, nil
+}
+
+// Size returns the length of the blob in bytes
+func (b *BlobReader) Size() int64 {
+	return b.size
+}
+
+// Read reads the next bytes of the blob, receiving the next chunk when the
+// current one is consumed
+func (b *BlobReader) Read(p []byte) (int, error) {
+	for len(b.chunk) == 0 {
+		if !b.rows.Next() {
+			if err := b.rows.Err(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+		if err := b.rows.Scan(&b.chunk); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, b.chunk)
+	b.chunk = b.chunk[n:]
+	return n, nil
+}
+
+// Close releases the connection of the reader
+func (b *BlobReader) Close() error {
+	return b.rows.Close()
+}
+
+// blobRow returns db restricted to the row of model by its primary key, and the
+// field of column
+func blobRow(db *gorm.DB, model interface{}, column string) (*gorm.DB, *schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, nil, err
+	}
+	field := stmt.Schema.LookUpField(column)
+	if field == nil || field.DBName == "" {
+		return nil, nil, fmt.Errorf("%w: %s", gorm.ErrInvalidField, column)
+	}
+	if len(stmt.Schema.PrimaryFields) == 0 {
+		return nil, nil, fmt.Errorf("%w: no key to find the row of %s by", gorm.ErrPrimaryKeyRequired, stmt.Table)
+	}
+
+	reflectValue := reflect.Indirect(reflect.ValueOf(model))
+	if reflectValue.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("unsupported model %T, expected a struct", model)
+	}
+	conditions := make([]clause.Expression, len(stmt.Schema.PrimaryFields))
+	for i, field := range stmt.Schema.PrimaryFields {
+		value, zero := field.ValueOf(stmt.Context, reflectValue)
+		if zero {
+			return nil, nil, fmt.Errorf("%w: %s is not set", gorm.ErrPrimaryKeyRequired, field.Name)
+		}
+		conditions[i] = clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value}
+	}
+	// a new model, so that only the conditions find the row
+	return db.Model(reflect.New(stmt.Schema.ModelType).Interface()).Where(clause.And(conditions...)), field, nil
+}
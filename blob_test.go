@@ -0,0 +1,92 @@
+package duckdb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type blobDocument struct {
+	Code    string `gorm:"primaryKey"`
+	Content []byte
+}
+
+type failingReader struct {
+	io.Reader
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestBlob(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&blobDocument{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Create(&[]blobDocument{{Code: "a"}, {Code: "b", Content: []byte("old")}}).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+
+	content := make([]byte, 10000)
+	for i := range content {
+		content[i] = byte(i * 7)
+	}
+	// chunk sizes are rounded down to multiples of 3
+	for _, chunkSize := range []int{1000, 3000} {
+		options := BlobOptions{ChunkSize: chunkSize}
+		if n, err := WriteBlob(db, &blobDocument{Code: "a"}, "content", bytes.NewReader(content), options); err != nil || n != int64(len(content)) {
+			t.Fatalf("expected %d bytes written, got %d, error %v", len(content), n, err)
+		}
+		var doc blobDocument
+		if err := db.First(&doc, "code = ?", "a").Error; err != nil || !bytes.Equal(doc.Content, content) {
+			t.Errorf("expected the blob to be written in chunks of %d, got %d bytes, error %v", chunkSize, len(doc.Content), err)
+		}
+
+		blob, err := OpenBlob(db, &blobDocument{Code: "a"}, "content", options)
+		if err != nil {
+			t.Fatalf("failed to open blob, got error %v", err)
+		}
+		got, err := io.ReadAll(blob)
+		blob.Close()
+		if err != nil || blob.Size() != int64(len(content)) || !bytes.Equal(got, content) {
+			t.Errorf("expected the blob to be read in chunks of %d, got %d of %d bytes, error %v", chunkSize, len(got), blob.Size(), err)
+		}
+	}
+	options := BlobOptions{ChunkSize: 3000}
+
+	if _, err := WriteBlob(db, &blobDocument{Code: "b"}, "content", failingReader{bytes.NewReader(content)}, options); err == nil {
+		t.Errorf("expected a failing reader to fail")
+	}
+	var old blobDocument
+	if err := db.First(&old, "code = ?", "b").Error; err != nil || string(old.Content) != "old" {
+		t.Errorf("expected the blob to be left alone, got %d bytes, error %v", len(old.Content), err)
+	}
+	if n, err := WriteBlob(db, &blobDocument{Code: "b"}, "content", bytes.NewReader(nil)); err != nil || n != 0 {
+		t.Errorf("expected an empty blob to be written, got %d, error %v", n, err)
+	}
+	if blob, err := OpenBlob(db, &blobDocument{Code: "b"}, "content"); err != nil {
+		t.Errorf("failed to open empty blob, got error %v", err)
+	} else if got, err := io.ReadAll(blob); err != nil || len(got) != 0 {
+		t.Errorf("expected an empty blob, got %q, error %v", got, err)
+	} else {
+		blob.Close()
+	}
+
+	if _, err := WriteBlob(db, &blobDocument{Code: "c"}, "content", bytes.NewReader(content)); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected a missing row to fail, got %v", err)
+	}
+	if _, err := OpenBlob(db, &blobDocument{Code: "c"}, "content"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected a missing row to fail, got %v", err)
+	}
+	if _, err := OpenBlob(db, &blobDocument{}, "content"); !errors.Is(err, gorm.ErrPrimaryKeyRequired) {
+		t.Errorf("expected a model without key to fail, got %v", err)
+	}
+}
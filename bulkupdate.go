@@ -0,0 +1,171 @@
+package duckdb
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// bulkUpdateAlias names the VALUES table of BulkUpdate
+const bulkUpdateAlias = "bulk_values"
+
+// BulkUpdate updates the rows of values, a slice of models, to their field
+// values in a single statement, joining a VALUES table on the primary key:
+//
+//	duckdb.BulkUpdate(db, &products, "name", "stock")
+//	// UPDATE "products" SET "name"="bulk_values"."name","stock"="bulk_values"."stock"
+//	// FROM (VALUES (CAST(? AS VARCHAR),...),...) AS "bulk_values"("sku","name","stock")
+//	// WHERE "products"."sku" = "bulk_values"."sku"
+//
+// Columns are the columns updated, by default all updatable columns but the
+// creation time and soft delete ones. Fields tracking the update time are set
+// to the current time. Hooks are skipped and soft deleted rows are left alone
+// as for Updates. Conditions set on db restrict the rows updated; their columns
+// must be qualified by the table name, as bulk_values has the same columns.
+func BulkUpdate(db *gorm.DB, values interface{}, columns ...string) *gorm.DB {
+	tx := db.Session(&gorm.Session{SkipHooks: true})
+
+	reflectValue := reflect.Indirect(reflect.ValueOf(values))
+	if reflectValue.Kind() != reflect.Slice && reflectValue.Kind() != reflect.Array {
+		tx.AddError(fmt.Errorf("unsupported values %T, expected a slice of models", values))
+		return tx
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(values); err != nil {
+		tx.AddError(err)
+		return tx
+	}
+	if len(stmt.Schema.PrimaryFields) == 0 {
+		tx.AddError(fmt.Errorf("%w: no key to match %s by", gorm.ErrPrimaryKeyRequired, stmt.Table))
+		return tx
+	}
+	if reflectValue.Len() == 0 {
+		return tx
+	}
+
+	var fields []*schema.Field
+	if len(columns) == 0 {
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && field.Updatable && !field.PrimaryKey && field.AutoCreateTime == 0 && field.AutoUpdateTime == 0 &&
+				field.FieldType != reflect.TypeOf(gorm.DeletedAt{}) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	for _, column := range columns {
+		field := stmt.Schema.LookUpField(column)
+		if field == nil || field.DBName == "" {
+			tx.AddError(fmt.Errorf("%w: %s", gorm.ErrInvalidField, column))
+			return tx
+		}
+		fields = append(fields, field)
+	}
+
+	// fields tracking the update time get the same value in every row, as
+	// DuckDB fails to bind parameters in the SET of an UPDATE with FROM
+	var touched []*schema.Field
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName != "" && field.AutoUpdateTime > 0 {
+			touched = append(touched, field)
+		}
+	}
+
+	var (
+		table      = clause.Table{Name: bulkUpdateAlias}
+		updates    = make(map[string]interface{}, len(fields)+len(touched))
+		conditions = make([]clause.Expression, len(stmt.Schema.PrimaryFields))
+		bulk       = clause.Values{Values: make([][]interface{}, reflectValue.Len())}
+		keyed      = append(append([]*schema.Field{}, stmt.Schema.PrimaryFields...), fields...)
+		bound      = append(append([]*schema.Field{}, keyed...), touched...)
+		times      = make([]interface{}, len(touched))
+	)
+	for i, field := range touched {
+		times[i] = autoUpdateTime(tx, field)
+	}
+	for i, field := range stmt.Schema.PrimaryFields {
+		conditions[i] = clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+			Value:  clause.Column{Table: table.Name, Name: field.DBName},
+		}
+	}
+	for i, field := range bound {
+		bulk.Columns = append(bulk.Columns, clause.Column{Name: field.DBName})
+		if i >= len(stmt.Schema.PrimaryFields) {
+			updates[field.DBName] = clause.Column{Table: table.Name, Name: field.DBName}
+		}
+	}
+	for i := range bulk.Values {
+		model := reflect.Indirect(reflectValue.Index(i))
+		row := make([]interface{}, len(bound))
+		for j, field := range keyed {
+			row[j], _ = field.ValueOf(stmt.Context, model)
+		}
+		copy(row[len(keyed):], times)
+		bulk.Values[i] = row
+	}
+	bulk = sensitiveValues(stmt, bindValues(stmt, castStructValues(stmt, bulk)))
+	// DuckDB does not infer the types of parameters in the VALUES of an UPDATE
+	for _, row := range bulk.Values {
+		for j, field := range bound {
+			row[j] = clause.Expr{SQL: "CAST(? AS " + tx.Dialector.DataTypeOf(field) + ")", Vars: []interface{}{row[j]}}
+		}
+	}
+
+	return tx.Model(reflect.New(stmt.Schema.ModelType).Interface()).
+		Clauses(valuesTable{alias: table, values: bulk}).
+		Where(clause.And(conditions...)).
+		Updates(updates)
+}
+
+// autoUpdateTime returns the current time in the form field stores it
+func autoUpdateTime(db *gorm.DB, field *schema.Field) interface{} {
+	now := db.NowFunc()
+	switch field.AutoUpdateTime {
+	case schema.UnixNanosecond:
+		return now.UnixNano()
+	case schema.UnixMillisecond:
+		return now.UnixMilli()
+	case schema.UnixSecond:
+		return now.Unix()
+	}
+	return now
+}
+
+// valuesTable is a FROM clause of a VALUES list named alias
+type valuesTable struct {
+	alias  clause.Table
+	values clause.Values
+}
+
+func (valuesTable) Name() string {
+	return "FROM"
+}
+
+func (t valuesTable) Build(builder clause.Builder) {
+	builder.WriteString("(VALUES ")
+	for i, row := range t.values.Values {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteByte('(')
+		builder.AddVar(builder, row...)
+		builder.WriteByte(')')
+	}
+	builder.WriteString(") AS ")
+	builder.WriteQuoted(t.alias)
+	builder.WriteByte('(')
+	for i, column := range t.values.Columns {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteQuoted(column.Name)
+	}
+	builder.WriteByte(')')
+}
+
+func (t valuesTable) MergeClause(c *clause.Clause) {
+	c.Expression = t
+}
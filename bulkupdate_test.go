@@ -0,0 +1,73 @@
+package duckdb
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type bulkProduct struct {
+	SKU       string `gorm:"primaryKey"`
+	Name      string
+	Stock     int
+	Note      *string
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func TestBulkUpdate(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&bulkProduct{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	note := "n"
+	products := []bulkProduct{
+		{SKU: "a", Name: "A", Stock: 1, Note: &note, UpdatedAt: created},
+		{SKU: "b", Name: "B", Stock: 2, UpdatedAt: created},
+		{SKU: "c", Name: "C", Stock: 3, UpdatedAt: created},
+		{SKU: "d", Name: "D", Stock: 4, UpdatedAt: created},
+	}
+	if err := db.Session(&gorm.Session{SkipHooks: true}).Create(&products).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+	if err := db.Delete(&products[3]).Error; err != nil {
+		t.Fatalf("failed to soft delete record, got error %v", err)
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	updated := []bulkProduct{{SKU: "a", Name: "A2", Stock: 10}, {SKU: "b", Name: "B2", Stock: 20}, {SKU: "d", Name: "D2"}}
+	result := BulkUpdate(db.Session(&gorm.Session{Logger: recorder}), &updated)
+	if result.Error != nil || result.RowsAffected != 2 {
+		t.Fatalf("expected 2 rows updated, got %d, error %v", result.RowsAffected, result.Error)
+	}
+	if len(recorder.sql) != 1 || !strings.Contains(recorder.sql[0], `FROM (VALUES (CAST('a' AS VARCHAR),CAST('A2' AS VARCHAR),CAST(10 AS BIGINT),CAST(NULL AS VARCHAR),`) {
+		t.Errorf("expected a single update joining the values, got %v", recorder.sql)
+	}
+
+	var got []bulkProduct
+	if err := db.Unscoped().Order("sku").Find(&got).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if got[0].Name != "A2" || got[0].Stock != 10 || got[0].Note != nil || got[1].Name != "B2" || got[2].Name != "C" || got[3].Name != "D" {
+		t.Errorf("expected matched rows to be updated, got %+v", got)
+	}
+	if !got[0].UpdatedAt.After(created) || !got[2].UpdatedAt.Equal(created) {
+		t.Errorf("expected the update time of updated rows to be set, got %v and %v", got[0].UpdatedAt, got[2].UpdatedAt)
+	}
+
+	result = BulkUpdate(db.Where("bulk_products.stock > ?", 15), []bulkProduct{{SKU: "a", Stock: 5}, {SKU: "b", Stock: 6}}, "stock")
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Fatalf("expected 1 row updated, got %d, error %v", result.RowsAffected, result.Error)
+	}
+	if err := db.Order("sku").Find(&got).Error; err != nil || got[0].Stock != 10 || got[1].Stock != 6 || got[1].Name != "B2" {
+		t.Errorf("expected selected columns of rows matching the conditions to be updated, got %+v, error %v", got, err)
+	}
+
+	if err := BulkUpdate(db, []bulkProduct{{SKU: "a"}}, "unknown").Error; err == nil {
+		t.Errorf("expected unknown column to fail")
+	}
+}
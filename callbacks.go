@@ -0,0 +1,152 @@
+package duckdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+const statementCancelKey = "duckdb:statement_cancel"
+
+// originalContextKey holds the statement context replaced by the timeout
+type originalContextKey struct{}
+
+// registerCallbacks hooks the dialector around gorm's callbacks. Only the
+// statement timeout and the encryption key apply to row queries, as their rows
+// outlive the callbacks.
+func (dialector Dialector) registerCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("*").Register("duckdb:before", dialector.beforeStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("*").Register("duckdb:after", dialector.afterStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("*").Register("duckdb:before", dialector.beforeStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("*").Register("duckdb:after", dialector.afterStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("*").Register("duckdb:before", dialector.beforeStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("*").Register("duckdb:after", dialector.afterStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("*").Register("duckdb:before", dialector.beforeStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("*").Register("duckdb:after", dialector.afterStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().Before("*").Register("duckdb:before", dialector.beforeStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().After("*").Register("duckdb:after", dialector.afterStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().Before("gorm:raw").Register("duckdb:ddl", dialector.onDDL); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().After("gorm:raw").Register("duckdb:audit_ddl", dialector.auditDDL); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("*").Register("duckdb:before", dialector.beforeRow); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("*").Register("duckdb:after", afterRow); err != nil {
+		return err
+	}
+
+	if err := callbacks.Query().Replace("gorm:query", dialector.query); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("duckdb:text_columns", selectTextColumns); err != nil {
+		return err
+	}
+	if err := callbacks.Create().Replace("gorm:create", dialector.create); err != nil {
+		return err
+	}
+	if err := callbacks.Create().Before("gorm:create").Register("duckdb:uuid_keys", returnUUIDKeys); err != nil {
+		return err
+	}
+
+	if err := callbacks.Create().Before("*").Register("duckdb:tenant", routeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("*").Register("duckdb:tenant", routeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("*").Register("duckdb:tenant", routeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("*").Register("duckdb:tenant", routeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("*").Register("duckdb:tenant", routeTenant); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dialector Dialector) beforeStatement(db *gorm.DB) {
+	dialector.attachEncryptionKey(db)
+	dialector.applyStatementTimeout(db)
+	dialector.prepareQueryStats(db)
+	applyScopedSettings(db)
+	dialector.loadInLists(db)
+}
+
+// beforeRow prepares Row and Rows, which return before their rows are read, so
+// the connection releases the statement timeout once the rows are closed: when
+// sql.Row is scanned, or when sql.Rows is closed. Rows left open keep the timer
+// until the timeout expires.
+func (dialector Dialector) beforeRow(db *gorm.DB) {
+	dialector.attachEncryptionKey(db)
+	dialector.applyStatementTimeout(db)
+	if cancel, ok := db.InstanceGet(statementCancelKey); ok {
+		db.Statement.Context = withRelease(db.Statement.Context, cancel.(context.CancelFunc))
+	}
+}
+
+// afterRow releases the statement timeout of rows that failed to be queried,
+// which no connection closes
+func afterRow(db *gorm.DB) {
+	if db.Error != nil {
+		cancelStatementTimeout(db)
+	}
+}
+
+func (dialector Dialector) afterStatement(db *gorm.DB) {
+	usePinnedConnection(db)
+	dialector.reportQueryStats(db)
+	dropInLists(db)
+	restoreScopedSettings(db)
+	cancelStatementTimeout(db)
+}
+
+// applyStatementTimeout bounds the statement context by Config.StatementTimeout;
+// the driver interrupts the query once it expires
+func (dialector Dialector) applyStatementTimeout(db *gorm.DB) {
+	if dialector.StatementTimeout <= 0 || db.DryRun {
+		return
+	}
+
+	ctx := db.Statement.Context
+	if original, ok := ctx.Value(originalContextKey{}).(context.Context); ok {
+		ctx = original
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, dialector.StatementTimeout)
+	db.Statement.Context = context.WithValue(timeoutCtx, originalContextKey{}, ctx)
+	db.InstanceSet(statementCancelKey, cancel)
+}
+
+func cancelStatementTimeout(db *gorm.DB) {
+	if cancel, ok := db.InstanceGet(statementCancelKey); ok {
+		cancel.(context.CancelFunc)()
+		if original, ok := db.Statement.Context.Value(originalContextKey{}).(context.Context); ok {
+			db.Statement.Context = original
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package duckdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestStatementTimeout(t *testing.T) {
+	db := openTestDB(t, Config{StatementTimeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	var count int64
+	err := db.Raw("SELECT count(*) FROM range(100000000000) a").Find(&count).Error
+	if err == nil {
+		t.Fatalf("expected the long running query to be interrupted")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the query to be interrupted promptly, took %v", elapsed)
+	}
+
+	if err := db.Exec("CREATE TABLE timeout_records (id INTEGER)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	// a reused statement must not keep the expired deadline of its previous run
+	tx := db.Table("timeout_records").Where("id > ?", 0)
+	for i := 0; i < 2; i++ {
+		if err := tx.Count(&count).Error; err != nil {
+			t.Errorf("expected statements within the timeout to succeed, got error %v", err)
+		}
+		time.Sleep(60 * time.Millisecond)
+	}
+}
+
+func TestStatementTimeout_rows(t *testing.T) {
+	db := openTestDB(t, Config{StatementTimeout: time.Hour})
+
+	ctx := &countingContext{Context: context.Background(), done: make(chan struct{})}
+	for _, tx := range []*gorm.DB{db, db.Session(&gorm.Session{PrepareStmt: true})} {
+		var n int
+		if err := tx.WithContext(ctx).Raw("SELECT 1").Row().Scan(&n); err != nil || n != 1 {
+			t.Fatalf("failed to scan row, got %d, error %v", n, err)
+		}
+		rows, err := tx.WithContext(ctx).Raw("SELECT * FROM range(3)").Rows()
+		if err != nil {
+			t.Fatalf("failed to query rows, got error %v", err)
+		}
+		rows.Close()
+		if _, err := tx.WithContext(ctx).Raw("SELECT * FROM no_such_table").Rows(); err == nil {
+			t.Fatalf("expected the query of a missing table to fail")
+		}
+	}
+	if pending := ctx.pending.Load(); pending != 0 {
+		t.Errorf("expected the statement timeouts to be released, got %d pending", pending)
+	}
+}
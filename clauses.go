@@ -0,0 +1,262 @@
+package duckdb
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLockingUnsupported is returned for FOR UPDATE/FOR SHARE clauses with LockingError
+var ErrLockingUnsupported = errors.New("DuckDB does not support row locking clauses")
+
+// LockingMode controls how clause.Locking, which DuckDB rejects, is handled
+type LockingMode int
+
+const (
+	// LockingWarn drops locking clauses and logs a warning
+	LockingWarn LockingMode = iota
+	// LockingIgnore drops locking clauses silently
+	LockingIgnore
+	// LockingError fails statements with locking clauses
+	LockingError
+)
+
+// buildLocking replaces the FOR clause builder. DuckDB transactions use MVCC
+// with optimistic conflict detection, so dropping the clause keeps statements
+// written for other databases working.
+func (dialector Dialector) buildLocking(c clause.Clause, builder clause.Builder) {
+	stmt, ok := builder.(*gorm.Statement)
+	if !ok {
+		return
+	}
+
+	locking, _ := c.Expression.(clause.Locking)
+	switch dialector.Locking {
+	case LockingIgnore:
+	case LockingError:
+		stmt.AddError(ErrLockingUnsupported)
+	default:
+		stmt.DB.Logger.Warn(stmt.Context, "duckdb: dropped unsupported locking clause FOR %s", locking.Strength)
+	}
+}
+
+// NullsOrder places NULLs in an ordering
+type NullsOrder int
+
+const (
+	// NullsDefault keeps the default_null_order setting, NULLS LAST unless
+	// configured otherwise
+	NullsDefault NullsOrder = iota
+	// NullsFirst sorts NULLs before other values
+	NullsFirst
+	// NullsLast sorts NULLs after other values
+	NullsLast
+)
+
+// OrderBy returns an ordering by column with explicit placement of NULLs, e.g.
+// db.Order(duckdb.OrderBy("score", true, duckdb.NullsLast)), for code ported
+// from databases whose default null order differs
+func OrderBy(column string, desc bool, nulls NullsOrder) clause.OrderBy {
+	sql := "?"
+	if desc {
+		sql += " DESC"
+	}
+	switch nulls {
+	case NullsFirst:
+		sql += " NULLS FIRST"
+	case NullsLast:
+		sql += " NULLS LAST"
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: sql, Vars: []interface{}{columnOf(column)}}}
+}
+
+// buildOnConflict writes the ON CONFLICT clause. Without columns, or with a
+// primary key the insert leaves to its default, the conflict target is
+// inferred from a key or unique index whose columns are all inserted, as
+// DuckDB needs a target for DO UPDATE and conditions once a table has several
+// of them. DuckDB doesn't support ON CONSTRAINT, so constraints and indexes of
+// the model named by OnConstraint are replaced by their columns.
+func (dialector Dialector) buildOnConflict(c clause.Clause, builder clause.Builder) {
+	if onConflict, ok := c.Expression.(clause.OnConflict); ok {
+		if stmt, ok := builder.(*gorm.Statement); ok && onConflict.OnConstraint != "" {
+			if columns := constraintColumns(stmt, onConflict.OnConstraint); columns != nil {
+				onConflict.Columns, onConflict.OnConstraint = columns, ""
+			}
+		}
+		// DO NOTHING takes no condition, the conflict target does
+		if onConflict.DoNothing && len(onConflict.Where.Exprs) > 0 {
+			onConflict.TargetWhere.Exprs = append(onConflict.TargetWhere.Exprs[:len(onConflict.TargetWhere.Exprs):len(onConflict.TargetWhere.Exprs)], onConflict.Where.Exprs...)
+			onConflict.Where = clause.Where{}
+		}
+		inferTarget := !onConflict.DoNothing || len(onConflict.TargetWhere.Exprs) > 0
+		if stmt, ok := builder.(*gorm.Statement); ok && inferTarget && onConflict.OnConstraint == "" {
+			if columns := conflictTarget(stmt, onConflict.Columns); columns != nil {
+				onConflict.Columns = columns
+			}
+			onConflict.DoUpdates = touchUpdateTime(stmt, onConflict.DoUpdates)
+		}
+		c.Expression = onConflict
+	}
+	c.Builder = nil
+	c.Build(builder)
+}
+
+// conflictTarget infers the conflict target of an insert, nil to keep current
+func conflictTarget(stmt *gorm.Statement, current []clause.Column) []clause.Column {
+	if stmt.Schema == nil {
+		return nil
+	}
+	values, _ := stmt.Clauses["VALUES"].Expression.(clause.Values)
+	inserted := make(map[string]bool, len(values.Columns))
+	for _, column := range values.Columns {
+		inserted[column.Name] = true
+	}
+	target := func(names []string) []clause.Column {
+		columns := make([]clause.Column, len(names))
+		for i, name := range names {
+			if !inserted[name] {
+				return nil
+			}
+			columns[i] = clause.Column{Name: name}
+		}
+		return columns
+	}
+
+	primaryKeys := make([]string, len(stmt.Schema.PrimaryFieldDBNames))
+	copy(primaryKeys, stmt.Schema.PrimaryFieldDBNames)
+	if len(current) > 0 {
+		names := make([]string, len(current))
+		for i, column := range current {
+			names[i] = column.Name
+		}
+		// only a primary key filled in by gorm is replaced
+		if !slices.Equal(names, primaryKeys) || target(names) != nil {
+			return nil
+		}
+	}
+
+	candidates := [][]string{primaryKeys}
+	indexes := stmt.Schema.ParseIndexes()
+	names := make([]string, 0, len(indexes))
+	for name, index := range indexes {
+		if strings.EqualFold(index.Class, "UNIQUE") && index.Where == "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fields := indexes[name].Fields
+		columns := make([]string, len(fields))
+		for i, field := range fields {
+			columns[i] = field.DBName
+		}
+		candidates = append(candidates, columns)
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.Unique {
+			candidates = append(candidates, []string{field.DBName})
+		}
+	}
+
+	for _, candidate := range candidates {
+		if len(candidate) > 0 {
+			if columns := target(candidate); columns != nil {
+				return columns
+			}
+		}
+	}
+	return nil
+}
+
+// constraintColumns returns the columns of the unique index or constraint of the
+// model named name, nil if the model has none of the name
+func constraintColumns(stmt *gorm.Statement, name string) []clause.Column {
+	if stmt.Schema == nil {
+		return nil
+	}
+	if index, ok := stmt.Schema.ParseIndexes()[name]; ok && strings.EqualFold(index.Class, "UNIQUE") && index.Where == "" {
+		columns := make([]clause.Column, len(index.Fields))
+		for i, field := range index.Fields {
+			columns[i] = clause.Column{Name: field.DBName}
+		}
+		return columns
+	}
+	if constraint, ok := stmt.Schema.ParseUniqueConstraints()[name]; ok {
+		return []clause.Column{{Name: constraint.Field.DBName}}
+	}
+	return nil
+}
+
+// touchUpdateTime adds the update time fields of the model to the assignments of
+// DO UPDATE unless assigned already, so that upserts touch them like updates
+func touchUpdateTime(stmt *gorm.Statement, set clause.Set) clause.Set {
+	if stmt.Schema == nil || len(set) == 0 {
+		return set
+	}
+	values, _ := stmt.Clauses["VALUES"].Expression.(clause.Values)
+	for _, field := range stmt.Schema.Fields {
+		if field.AutoUpdateTime == 0 || field.DBName == "" {
+			continue
+		}
+		assigned := slices.ContainsFunc(set, func(assignment clause.Assignment) bool { return assignment.Column.Name == field.DBName })
+		inserted := slices.ContainsFunc(values.Columns, func(column clause.Column) bool { return column.Name == field.DBName })
+		if !assigned && inserted {
+			set = append(set[:len(set):len(set)], clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: Excluded(field.DBName)})
+		}
+	}
+	return set
+}
+
+// Excluded refers to the value a conflicting insert proposed for column, for
+// the assignments and conditions of ON CONFLICT DO UPDATE
+func Excluded(column string) clause.Expr {
+	return clause.Expr{SQL: "?", Vars: []interface{}{clause.Column{Table: "excluded", Name: column}}}
+}
+
+// AssignmentColumnsIfNotNull is like clause.AssignmentColumns but keeps the
+// existing value of a column when the insert proposes NULL, for partial upserts:
+//
+//	db.Clauses(clause.OnConflict{DoUpdates: duckdb.AssignmentColumnsIfNotNull([]string{"name", "email"})}).Create(&users)
+func AssignmentColumnsIfNotNull(columns []string) clause.Set {
+	set := make(clause.Set, len(columns))
+	for i, column := range columns {
+		set[i] = clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value: clause.Expr{SQL: "COALESCE(?, ?)", Vars: []interface{}{
+				clause.Column{Table: "excluded", Name: column}, clause.Column{Table: clause.CurrentTable, Name: column},
+			}},
+		}
+	}
+	return set
+}
+
+// buildValues writes the VALUES clause, with DEFAULT for the UUID primary keys
+// DuckDB generates and values of STRUCT columns cast from JSON. gorm writes
+// DEFAULT VALUES for rows without assignable columns, which inserts a single
+// row, so batches name a column with a database default and insert DEFAULT for
+// each row instead. Schemas without such a column are left to gorm.
+func (dialector Dialector) buildValues(c clause.Clause, builder clause.Builder) {
+	if values, ok := c.Expression.(clause.Values); ok && len(values.Columns) > 0 {
+		if stmt, ok := builder.(*gorm.Statement); ok {
+			c.Expression = sensitiveValues(stmt, bindValues(stmt, castStructValues(stmt, defaultUUIDKeys(stmt, values))))
+		}
+	} else if ok && len(values.Values) > 1 {
+		if stmt, ok := builder.(*gorm.Statement); ok && stmt.Schema != nil && len(stmt.Schema.FieldsWithDefaultDBValue) > 0 {
+			builder.WriteByte('(')
+			builder.WriteQuoted(stmt.Schema.FieldsWithDefaultDBValue[0].DBName)
+			builder.WriteString(") VALUES ")
+			for i := range values.Values {
+				if i > 0 {
+					builder.WriteByte(',')
+				}
+				builder.WriteString("(DEFAULT)")
+			}
+			return
+		}
+	}
+	c.Builder = nil
+	c.Build(builder)
+}
@@ -0,0 +1,310 @@
+package duckdb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+type lockingRecord struct {
+	ID int
+}
+
+func TestDialector_buildLocking(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     LockingMode
+		wantWarn bool
+		wantErr  error
+	}{
+		{name: "it should drop locking clauses with a warning", mode: LockingWarn, wantWarn: true},
+		{name: "it should drop locking clauses silently", mode: LockingIgnore},
+		{name: "it should reject locking clauses", mode: LockingError, wantErr: ErrLockingUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingLogger{Interface: logger.Discard}
+			db := openTestDB(t, Config{Locking: tt.mode}).Session(&gorm.Session{Logger: recorder})
+			if err := db.Exec("CREATE TABLE locking_records (id INTEGER)").Error; err != nil {
+				t.Fatalf("failed to create table, got error %v", err)
+			}
+
+			var records []lockingRecord
+			err := db.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Find(&records).Error
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if gotWarn := strings.Contains(recorder.warnings(), "FOR UPDATE"); gotWarn != tt.wantWarn {
+				t.Errorf("expected warning %v, got %q", tt.wantWarn, recorder.warnings())
+			}
+		})
+	}
+}
+
+type nullsRecord struct {
+	ID    int
+	Score *int
+}
+
+func TestOrderBy(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE TABLE nulls_records AS SELECT * FROM (VALUES (1, 10), (2, NULL), (3, 5)) t(id, score)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		order clause.OrderBy
+		want  []int
+	}{
+		{name: "it should sort NULLs first", order: OrderBy("score", false, NullsFirst), want: []int{2, 3, 1}},
+		{name: "it should sort NULLs last", order: OrderBy("nulls_records.score", true, NullsLast), want: []int{1, 3, 2}},
+		{name: "it should keep the default null order", order: OrderBy("score", true, NullsDefault), want: []int{1, 3, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []int
+			if err := db.Model(&nullsRecord{}).Order(tt.order).Pluck("id", &ids).Error; err != nil {
+				t.Fatalf("failed to query, got error %v", err)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
+
+type upsertProduct struct {
+	Number int    `gorm:"primaryKey;default:nextval('upsert_product_ids')"`
+	SKU    string `gorm:"uniqueIndex"`
+	Name   string
+	Stock  int
+}
+
+type upsertStock struct {
+	Region string `gorm:"primaryKey"`
+	Code   string `gorm:"primaryKey"`
+	Stock  int
+}
+
+func TestDialector_buildOnConflict(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE SEQUENCE upsert_product_ids").Error; err != nil {
+		t.Fatalf("failed to create sequence, got error %v", err)
+	}
+	if err := db.AutoMigrate(&upsertProduct{}, &upsertStock{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		conflict clause.OnConflict
+		want     string
+	}{
+		{
+			name:     "it should infer a unique index when the primary key is not inserted",
+			value:    &upsertProduct{SKU: "a-1", Name: "apple", Stock: 3},
+			conflict: clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"stock"})},
+			want:     `ON CONFLICT ("sku") DO UPDATE SET "stock"="excluded"."stock"`,
+		},
+		{
+			name:     "it should replace the primary key gorm infers for UpdateAll",
+			value:    &upsertProduct{SKU: "a-1", Name: "apple", Stock: 3},
+			conflict: clause.OnConflict{UpdateAll: true},
+			want:     `ON CONFLICT ("sku") DO UPDATE SET`,
+		},
+		{
+			name:     "it should infer the primary key",
+			value:    &upsertStock{Region: "eu", Code: "a-1", Stock: 3},
+			conflict: clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"stock"})},
+			want:     `ON CONFLICT ("region","code") DO UPDATE SET "stock"="excluded"."stock"`,
+		},
+		{
+			name:     "it should keep explicit columns",
+			value:    &upsertProduct{Number: 1, SKU: "a-1"},
+			conflict: clause.OnConflict{Columns: []clause.Column{{Name: "number"}}, DoUpdates: clause.AssignmentColumns([]string{"sku"})},
+			want:     `ON CONFLICT ("number") DO UPDATE SET "sku"="excluded"."sku"`,
+		},
+		{
+			name:     "it should replace a unique index named by OnConstraint by its columns",
+			value:    &upsertProduct{Number: 1, SKU: "a-1"},
+			conflict: clause.OnConflict{OnConstraint: "idx_upsert_products_sku", DoUpdates: clause.AssignmentColumns([]string{"stock"})},
+			want:     `ON CONFLICT ("sku") DO UPDATE SET "stock"="excluded"."stock"`,
+		},
+		{
+			name:     "it should keep unknown constraints",
+			value:    &upsertProduct{Number: 1, SKU: "a-1"},
+			conflict: clause.OnConflict{OnConstraint: "unknown", DoNothing: true},
+			want:     `ON CONFLICT ON CONSTRAINT unknown DO NOTHING`,
+		},
+		{
+			name:     "it should keep DO NOTHING without a target",
+			value:    &upsertStock{Region: "eu", Code: "a-1"},
+			conflict: clause.OnConflict{DoNothing: true},
+			want:     `ON CONFLICT DO NOTHING`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Clauses(tt.conflict).Create(tt.value)
+			})
+			if !strings.Contains(sql, tt.want) {
+				t.Errorf("expected %s in %s", tt.want, sql)
+			}
+		})
+	}
+
+	for _, stock := range []int{3, 5} {
+		product := upsertProduct{SKU: "a-1", Name: "apple", Stock: stock}
+		if err := db.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"stock"})}).Create(&product).Error; err != nil {
+			t.Fatalf("failed to upsert, got error %v", err)
+		}
+	}
+	byIndex := clause.OnConflict{OnConstraint: "idx_upsert_products_sku", DoUpdates: clause.AssignmentColumns([]string{"name"})}
+	if err := db.Clauses(byIndex).Create(&upsertProduct{SKU: "a-1", Name: "apricot"}).Error; err != nil {
+		t.Fatalf("failed to upsert on constraint, got error %v", err)
+	}
+	var products []upsertProduct
+	if err := db.Find(&products).Error; err != nil || len(products) != 1 || products[0].Stock != 5 || products[0].Name != "apricot" {
+		t.Errorf("expected the product to be updated, got %+v, error %v", products, err)
+	}
+}
+
+type upsertContact struct {
+	Email     string `gorm:"primaryKey"`
+	Name      *string
+	Phone     *string
+	UpdatedAt time.Time
+}
+
+func TestAssignmentColumnsIfNotNull(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&upsertContact{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	name, phone, newPhone := "Alice", "555-0100", "555-0199"
+	created := time.Now().Add(-time.Hour)
+	if err := db.Create(&upsertContact{Email: "a@example.com", Name: &name, Phone: &phone, UpdatedAt: created}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	upsert := db.Clauses(clause.OnConflict{DoUpdates: AssignmentColumnsIfNotNull([]string{"name", "phone"})})
+	if err := upsert.Create(&upsertContact{Email: "a@example.com", Phone: &newPhone}).Error; err != nil {
+		t.Fatalf("failed to upsert, got error %v", err)
+	}
+	var contact upsertContact
+	if err := db.First(&contact, "email = ?", "a@example.com").Error; err != nil {
+		t.Fatalf("failed to query, got error %v", err)
+	}
+	if contact.Name == nil || *contact.Name != name || contact.Phone == nil || *contact.Phone != newPhone {
+		t.Errorf("expected the name to be kept and the phone to be updated, got %+v", contact)
+	}
+	if !contact.UpdatedAt.After(created) {
+		t.Errorf("expected the update time to be touched, got %v", contact.UpdatedAt)
+	}
+
+	shout := clause.OnConflict{DoUpdates: clause.Assignments(map[string]interface{}{"name": gorm.Expr("upper(?)", Excluded("name"))})}
+	if err := db.Clauses(shout).Create(&upsertContact{Email: "a@example.com", Name: &name}).Error; err != nil {
+		t.Fatalf("failed to upsert, got error %v", err)
+	}
+	if err := db.First(&contact, "email = ?", "a@example.com").Error; err != nil || contact.Name == nil || *contact.Name != "ALICE" {
+		t.Errorf("expected the name to be updated from excluded, got %+v, error %v", contact, err)
+	}
+}
+
+type upsertAccount struct {
+	Code    string `gorm:"primaryKey"`
+	Balance int
+	Active  bool
+}
+
+func TestDialector_buildOnConflict_where(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&upsertAccount{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Create(&[]upsertAccount{{"a", 1, true}, {"b", 2, false}}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	activeOnly := clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"balance"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "active"}, Value: true}}},
+	}
+	dryRun := db.Session(&gorm.Session{DryRun: true})
+	sql := dryRun.Clauses(activeOnly).Create(&upsertAccount{Code: "a"}).Statement.SQL.String()
+	if want := `ON CONFLICT ("code") DO UPDATE SET "balance"="excluded"."balance" WHERE "upsert_accounts"."active" = ?`; !strings.Contains(sql, want) {
+		t.Errorf("expected %s in %s", want, sql)
+	}
+	if err := db.Clauses(activeOnly).Create(&[]upsertAccount{{"a", 10, true}, {"b", 20, true}}).Error; err != nil {
+		t.Fatalf("failed to upsert, got error %v", err)
+	}
+
+	doNothing := clause.OnConflict{
+		DoNothing: true,
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "active"}}},
+	}
+	sql = dryRun.Clauses(doNothing).Create(&upsertAccount{Code: "a"}).Statement.SQL.String()
+	if want := `ON CONFLICT ("code")  WHERE active DO NOTHING`; !strings.Contains(sql, want) {
+		t.Errorf("expected %s in %s", want, sql)
+	}
+	if err := db.Clauses(doNothing).Create(&upsertAccount{Code: "a", Balance: 100}).Error; err != nil {
+		t.Fatalf("failed to insert, got error %v", err)
+	}
+
+	var balances []int
+	if err := db.Model(&upsertAccount{}).Order("code").Pluck("balance", &balances).Error; err != nil || len(balances) != 2 || balances[0] != 10 || balances[1] != 2 {
+		t.Errorf("expected only active accounts to be updated, got %v, error %v", balances, err)
+	}
+}
+
+type defaultsRecord struct {
+	ID     uint
+	Status string `gorm:"default:upper('new')"`
+}
+
+func TestDialector_buildValues(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&defaultsRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	record := defaultsRecord{}
+	if err := db.Create(&record).Error; err != nil || record.ID != 1 || record.Status != "NEW" {
+		t.Errorf("expected a row of defaults, got %+v, error %v", record, err)
+	}
+	records := []defaultsRecord{{}, {}, {}}
+	if err := db.Create(&records).Error; err != nil || records[0].ID != 2 || records[2].ID != 4 || records[2].Status != "NEW" {
+		t.Errorf("expected a row of defaults per record, got %+v, error %v", records, err)
+	}
+
+	var count int64
+	if err := db.Model(&defaultsRecord{}).Count(&count).Error; err != nil || count != 4 {
+		t.Errorf("expected 4 rows, got %d, error %v", count, err)
+	}
+}
+
+type plainRecord struct {
+	Name string
+}
+
+func TestDialector_buildValues_withoutDefaults(t *testing.T) {
+	db := openTestDB(t, Config{})
+	stmt := &gorm.Statement{DB: db, Clauses: map[string]clause.Clause{}}
+	if err := stmt.Parse(&plainRecord{}); err != nil {
+		t.Fatalf("failed to parse, got error %v", err)
+	}
+	stmt.AddClause(clause.Values{Values: [][]interface{}{{}, {}}})
+	stmt.Build("VALUES")
+	if sql := stmt.SQL.String(); sql != "DEFAULT VALUES" {
+		t.Errorf("expected gorm's DEFAULT VALUES without a column with a default, got %q", sql)
+	}
+}
@@ -0,0 +1,150 @@
+// Command gorm-duckdb runs schema operations against DuckDB databases, e.g. from
+// CI or operations scripts:
+//
+//	gorm-duckdb export-schema -db app.duckdb > schema.sql
+//	gorm-duckdb diff -db app.duckdb -schema schema.sql
+//	gorm-duckdb plan -db app.duckdb -schema schema.sql
+//	gorm-duckdb migrate -db app.duckdb -schema schema.sql
+//
+// The desired schema is a file of the statements creating it, as written by
+// export-schema, e.g. from a database migrated by the application. diff lists
+// the differences and exits with status 1 when there are any, plan prints the
+// statements migrate would run, and migrate runs them in a transaction.
+// Objects missing from the schema file are only dropped with -drop.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gorm.io/driver/duckdb"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const usage = `usage: gorm-duckdb <command> [flags]
+
+commands:
+  export-schema  print the statements creating the schema of -db
+  diff           list the differences between -db and -schema
+  plan           print the statements migrating -db to -schema
+  migrate        migrate -db to -schema
+
+flags:
+`
+
+// errDifferent is returned by diff when the schemas differ
+var errDifferent = errors.New("schemas differ")
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if errors.Is(err, errDifferent) {
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, "gorm-duckdb:", err)
+		os.Exit(2)
+	}
+}
+
+// run runs the command of args, writing its output to stdout
+func run(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("gorm-duckdb", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	dsn := flags.String("db", "", "DSN of the database, e.g. app.duckdb?threads=4")
+	schemaFile := flags.String("schema", "", "file of the statements creating the desired schema")
+	drop := flags.Bool("drop", false, "drop the tables, columns, views, indexes and sequences missing from -schema")
+	verbose := flags.Bool("v", false, "log the statements run")
+
+	if len(args) == 0 {
+		flags.Usage()
+		return errors.New("no command")
+	}
+	command := args[0]
+	switch command {
+	case "export-schema", "diff", "plan", "migrate":
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *dsn == "" {
+		return errors.New("-db is required")
+	}
+	if *schemaFile == "" && command != "export-schema" {
+		return errors.New("-schema is required")
+	}
+
+	config := &gorm.Config{Logger: logger.Discard}
+	if *verbose {
+		config.Logger = logger.New(logWriter{stderr}, logger.Config{LogLevel: logger.Info})
+	}
+	db, err := gorm.Open(duckdb.Open(*dsn), config)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	if command == "export-schema" {
+		return exportSchema(db, stdout)
+	}
+
+	desired, err := gorm.Open(duckdb.Open(""), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := desired.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	if err := loadSchema(desired, *schemaFile); err != nil {
+		return fmt.Errorf("failed to load %s: %w", *schemaFile, err)
+	}
+	changes, err := diffSchemas(db, desired)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "diff":
+		for _, c := range changes {
+			fmt.Fprintln(stdout, c)
+		}
+		if len(changes) > 0 {
+			return errDifferent
+		}
+	case "plan":
+		for _, statement := range plan(changes, *drop) {
+			fmt.Fprintln(stdout, statement+";")
+		}
+	case "migrate":
+		return db.Transaction(func(tx *gorm.DB) error {
+			for _, statement := range plan(changes, *drop) {
+				if err := tx.Exec(statement).Error; err != nil {
+					return fmt.Errorf("%s: %w", statement, err)
+				}
+				fmt.Fprintln(stdout, statement+";")
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+// logWriter is a logger.Writer printing to an io.Writer
+type logWriter struct {
+	io.Writer
+}
+
+func (l logWriter) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(l.Writer, format+"\n", args...)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.duckdb")
+	source := filepath.Join(dir, "source.duckdb")
+	schemaFile := filepath.Join(dir, "schema.sql")
+
+	var stdout, stderr bytes.Buffer
+	exec := func(args ...string) error {
+		stdout.Reset()
+		stderr.Reset()
+		return run(args, &stdout, &stderr)
+	}
+
+	// the desired schema, exported from a database migrated by the application
+	if err := os.WriteFile(schemaFile, []byte(`
+CREATE SEQUENCE seq_orders_id;
+CREATE TABLE orders (id BIGINT DEFAULT nextval('seq_orders_id') PRIMARY KEY, total DOUBLE NOT NULL, note VARCHAR);
+CREATE INDEX idx_orders_total ON orders (total);
+CREATE TABLE customers (name VARCHAR PRIMARY KEY);
+`), 0o644); err != nil {
+		t.Fatalf("failed to write schema, got error %v", err)
+	}
+	if err := exec("migrate", "-db", source, "-schema", schemaFile); err != nil {
+		t.Fatalf("failed to migrate source, got error %v, %s", err, stderr.String())
+	}
+	if err := exec("export-schema", "-db", source); err != nil {
+		t.Fatalf("failed to export schema, got error %v", err)
+	}
+	exported := stdout.String()
+	if !strings.Contains(exported, "CREATE SEQUENCE seq_orders_id") || !strings.Contains(exported, "CREATE INDEX idx_orders_total") {
+		t.Errorf("expected the sequence and index to be exported, got %s", exported)
+	}
+	if err := os.WriteFile(schemaFile, []byte(exported), 0o644); err != nil {
+		t.Fatalf("failed to write schema, got error %v", err)
+	}
+
+	if err := exec("migrate", "-db", target, "-schema", schemaFile); err != nil {
+		t.Fatalf("failed to migrate target, got error %v", err)
+	}
+	if err := exec("migrate", "-db", target, "-schema", filepath.Join(dir, "missing.sql")); err == nil {
+		t.Errorf("expected a missing schema file to fail")
+	}
+	// an outdated target
+	if err := os.WriteFile(filepath.Join(dir, "outdated.sql"), []byte(`
+CREATE TABLE orders (id BIGINT PRIMARY KEY, total INTEGER, legacy VARCHAR);
+CREATE VIEW big_orders AS SELECT * FROM orders WHERE total > 100;
+`), 0o644); err != nil {
+		t.Fatalf("failed to write schema, got error %v", err)
+	}
+	outdated := filepath.Join(dir, "outdated.duckdb")
+	if err := exec("migrate", "-db", outdated, "-schema", filepath.Join(dir, "outdated.sql")); err != nil {
+		t.Fatalf("failed to migrate outdated, got error %v", err)
+	}
+
+	if err := exec("diff", "-db", outdated, "-schema", schemaFile); !errors.Is(err, errDifferent) {
+		t.Fatalf("expected differences, got error %v", err)
+	}
+	diff := stdout.String()
+	for _, line := range []string{
+		"+ sequence seq_orders_id",
+		"~ column orders.total: INTEGER -> DOUBLE",
+		"~ column orders.total: NULL -> NOT NULL",
+		"+ column orders.note: VARCHAR",
+		"- column orders.legacy",
+		"+ table customers",
+		"- view big_orders",
+		"+ index idx_orders_total",
+	} {
+		if !strings.Contains(diff, line+"\n") {
+			t.Errorf("expected diff to contain %q, got\n%s", line, diff)
+		}
+	}
+
+	if err := exec("plan", "-db", outdated, "-schema", schemaFile); err != nil {
+		t.Fatalf("failed to plan, got error %v", err)
+	}
+	if planned := stdout.String(); !strings.Contains(planned, `ALTER TABLE "orders" ALTER COLUMN "total" TYPE DOUBLE;`) || strings.Contains(planned, "DROP") {
+		t.Errorf("expected the plan to alter without dropping, got\n%s", planned)
+	}
+	if err := exec("plan", "-db", outdated, "-schema", schemaFile, "-drop"); err != nil || !strings.Contains(stdout.String(), `DROP VIEW "big_orders";`) {
+		t.Errorf("expected the plan to drop with -drop, got %s, error %v", stdout.String(), err)
+	}
+
+	if err := exec("migrate", "-db", outdated, "-schema", schemaFile, "-drop"); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := exec("diff", "-db", outdated, "-schema", schemaFile); err != nil || stdout.Len() != 0 {
+		t.Errorf("expected no differences after migrating, got %s, error %v", stdout.String(), err)
+	}
+
+	if err := exec("unknown", "-db", target); err == nil || !strings.Contains(stderr.String(), "usage:") {
+		t.Errorf("expected an unknown command to print the usage, got error %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// change is a difference between the schema of a database and the desired one
+type change struct {
+	// op is + for objects to create, - for objects to drop and ~ for columns
+	// to alter
+	op     byte
+	kind   string
+	name   string
+	detail string
+	// sql is the statement applying the change, empty when it cannot be applied
+	sql string
+}
+
+func (c change) String() string {
+	s := string(c.op) + " " + c.kind + " " + c.name
+	if c.detail != "" {
+		s += ": " + c.detail
+	}
+	return s
+}
+
+// object is a sequence, table, view or index and the statement creating it
+type object struct {
+	Name string
+	SQL  string
+}
+
+// objectQueries list the objects of the current schema by kind, in the order
+// they are created in
+var objectQueries = []struct {
+	kind  string
+	query string
+}{
+	{"sequence", "SELECT sequence_name AS name, sql FROM duckdb_sequences() WHERE database_name = current_database() AND schema_name = current_schema() AND NOT temporary ORDER BY sequence_oid"},
+	{"table", "SELECT table_name AS name, sql FROM duckdb_tables() WHERE database_name = current_database() AND schema_name = current_schema() AND NOT temporary ORDER BY table_oid"},
+	{"view", "SELECT view_name AS name, sql FROM duckdb_views() WHERE database_name = current_database() AND schema_name = current_schema() AND NOT internal AND NOT temporary ORDER BY view_oid"},
+	{"index", "SELECT index_name AS name, sql FROM duckdb_indexes() WHERE database_name = current_database() AND schema_name = current_schema() ORDER BY index_oid"},
+}
+
+// objects returns the objects of kind in the current schema
+func objects(db *gorm.DB, kind string) (objects []object, err error) {
+	for _, q := range objectQueries {
+		if q.kind == kind {
+			err = db.Raw(q.query).Scan(&objects).Error
+		}
+	}
+	return objects, err
+}
+
+// exportSchema writes the statements creating the objects of the current
+// schema of db to w
+func exportSchema(db *gorm.DB, w io.Writer) error {
+	for _, q := range objectQueries {
+		list, err := objects(db, q.kind)
+		if err != nil {
+			return err
+		}
+		for _, o := range list {
+			if _, err := fmt.Fprintln(w, o.SQL); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadSchema creates the objects of the statements in the file at path in
+// desired, an empty database
+func loadSchema(desired *gorm.DB, path string) error {
+	statements, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return desired.Exec(string(statements)).Error
+}
+
+// diffSchemas returns the changes turning the current schema of db into the
+// one of desired: objects missing from db are created, columns are added and
+// altered, and objects desired lacks are dropped
+func diffSchemas(db, desired *gorm.DB) (changes []change, err error) {
+	var drops []change
+	for _, q := range objectQueries {
+		have, err := objects(db, q.kind)
+		if err != nil {
+			return nil, err
+		}
+		want, err := objects(desired, q.kind)
+		if err != nil {
+			return nil, err
+		}
+		existing := make(map[string]bool, len(have))
+		for _, o := range have {
+			existing[o.Name] = true
+		}
+		for _, o := range want {
+			if !existing[o.Name] {
+				changes = append(changes, change{op: '+', kind: q.kind, name: o.Name, sql: o.SQL})
+			} else if q.kind == "table" {
+				columns, err := diffColumns(db, desired, o.Name)
+				if err != nil {
+					return nil, err
+				}
+				changes = append(changes, columns...)
+			}
+			delete(existing, o.Name)
+		}
+		for _, o := range have {
+			if existing[o.Name] {
+				drops = append(drops, change{op: '-', kind: q.kind, name: o.Name, sql: toSQL(db, "DROP "+strings.ToUpper(q.kind)+" ?", clause.Table{Name: o.Name})})
+			}
+		}
+	}
+	// views and indexes go before their tables, which go before sequences
+	for i := len(drops) - 1; i >= 0; i-- {
+		changes = append(changes, drops[i])
+	}
+	return changes, nil
+}
+
+// diffColumns returns the changes turning the columns of table in db into the
+// ones in desired
+func diffColumns(db, desired *gorm.DB, table string) (changes []change, err error) {
+	have, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return nil, err
+	}
+	want, err := desired.Migrator().ColumnTypes(table)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]gorm.ColumnType, len(have))
+	for _, column := range have {
+		existing[column.Name()] = column
+	}
+
+	var drops []string
+	for _, column := range want {
+		var (
+			name            = table + "." + column.Name()
+			columnType, _   = column.ColumnType()
+			nullable, _     = column.Nullable()
+			value, hasValue = column.DefaultValue()
+			target          = []interface{}{clause.Table{Name: table}, clause.Column{Name: column.Name()}}
+		)
+		current, ok := existing[column.Name()]
+		delete(existing, column.Name())
+		if !ok {
+			sql := "ALTER TABLE ? ADD COLUMN ? " + columnType
+			if hasValue && value != "" {
+				sql += " DEFAULT " + value
+			}
+			changes = append(changes, change{op: '+', kind: "column", name: name, detail: columnType, sql: toSQL(db, sql, target...)})
+			if !nullable {
+				changes = append(changes, change{op: '~', kind: "column", name: name, detail: "NULL -> NOT NULL", sql: toSQL(db, "ALTER TABLE ? ALTER COLUMN ? SET NOT NULL", target...)})
+			}
+			continue
+		}
+		if currentType, _ := current.ColumnType(); !strings.EqualFold(currentType, columnType) {
+			changes = append(changes, change{op: '~', kind: "column", name: name, detail: currentType + " -> " + columnType, sql: toSQL(db, "ALTER TABLE ? ALTER COLUMN ? TYPE "+columnType, target...)})
+		}
+		if currentNullable, _ := current.Nullable(); currentNullable && !nullable {
+			changes = append(changes, change{op: '~', kind: "column", name: name, detail: "NULL -> NOT NULL", sql: toSQL(db, "ALTER TABLE ? ALTER COLUMN ? SET NOT NULL", target...)})
+		} else if !currentNullable && nullable {
+			changes = append(changes, change{op: '~', kind: "column", name: name, detail: "NOT NULL -> NULL", sql: toSQL(db, "ALTER TABLE ? ALTER COLUMN ? DROP NOT NULL", target...)})
+		}
+	}
+	for name := range existing {
+		drops = append(drops, name)
+	}
+	sort.Strings(drops)
+	for _, name := range drops {
+		changes = append(changes, change{op: '-', kind: "column", name: table + "." + name, sql: toSQL(db, "ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: table}, clause.Column{Name: name})})
+	}
+	return changes, nil
+}
+
+// plan returns the statements applying changes, leaving out drops unless drop
+// is set
+func plan(changes []change, drop bool) (statements []string) {
+	for _, c := range changes {
+		if c.sql != "" && (drop || c.op != '-') {
+			statements = append(statements, strings.TrimSuffix(c.sql, ";"))
+		}
+	}
+	return statements
+}
+
+// toSQL returns sql with vars quoted by the dialector of db
+func toSQL(db *gorm.DB, sql string, vars ...interface{}) string {
+	return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Exec(sql, vars...)
+	})
+}
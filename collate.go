@@ -0,0 +1,72 @@
+package duckdb
+
+import (
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// collationOf returns the collation set by the collate tag of field, e.g.
+// `gorm:"collate:NOCASE"` or `gorm:"collate:NOCASE.NOACCENT"`
+func collationOf(field *schema.Field) string {
+	return field.TagSettings["COLLATE"]
+}
+
+// collateClause renders the COLLATE clause of field, empty without collation
+func collateClause(field *schema.Field) string {
+	if collation := collationOf(field); collation != "" {
+		return " COLLATE " + quoteIdentifier(collation)
+	}
+	return ""
+}
+
+// FullDataTypeOf adds the collation of the collate tag to the column definition,
+// and a default generating UUID primary keys
+func (m Migrator) FullDataTypeOf(field *schema.Field) clause.Expr {
+	expr := m.Migrator.FullDataTypeOf(field)
+	if generatedUUIDKey(field.Schema) == field {
+		expr.SQL += " DEFAULT uuid()"
+	}
+	if collate := collateClause(field); collate != "" {
+		dataType := m.DataTypeOf(field)
+		expr.SQL = dataType + collate + strings.TrimPrefix(expr.SQL, dataType)
+	}
+	return expr
+}
+
+// columnCollation returns the collation of column, which DuckDB only keeps in
+// the table's CREATE statement
+func (m Migrator) columnCollation(stmt *gorm.Statement, column string) (collation string, err error) {
+	catalog, currentSchema, table := m.qualifiedTable(stmt, stmt.Table)
+	var createSQL string
+	if err = m.queryRaw(
+		"SELECT sql FROM duckdb_tables() WHERE database_name = ? AND "+
+			identifierMatches("schema_name")+" AND "+identifierMatches("table_name"), catalog, currentSchema, table,
+	).Row().Scan(&createSQL); err != nil {
+		return "", err
+	}
+
+	pattern := regexp.MustCompile(`(?i)[(,]\s*(?:"` + regexp.QuoteMeta(strings.ReplaceAll(column, `"`, `""`)) + `"|` +
+		regexp.QuoteMeta(column) + `)\s+[^,]*?\bCOLLATE\s+("(?:[^"]|"")+"|[A-Za-z0-9_.]+)`)
+	if matches := pattern.FindStringSubmatch(createSQL); matches != nil {
+		collation = matches[1]
+		if strings.HasPrefix(collation, `"`) {
+			collation = strings.ReplaceAll(collation[1:len(collation)-1], `""`, `"`)
+		}
+	}
+	return collation, nil
+}
+
+// sameCollation reports whether the collation of the existing column matches
+// the collate tag of field
+func (m Migrator) sameCollation(value interface{}, field *schema.Field) (same bool, err error) {
+	err = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		collation, err := m.columnCollation(stmt, field.DBName)
+		same = strings.EqualFold(collation, collationOf(field))
+		return err
+	})
+	return
+}
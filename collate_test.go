@@ -0,0 +1,56 @@
+package duckdb
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type collateRecord struct {
+	ID   int
+	Name string `gorm:"collate:NOCASE"`
+	Code string
+}
+
+type collateRecordV2 struct {
+	ID   int
+	Name string `gorm:"collate:NOCASE"`
+	Code string `gorm:"collate:NOCASE.NOACCENT"`
+}
+
+func (collateRecordV2) TableName() string { return "collate_records" }
+
+func TestMigrator_collate(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&collateRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Create(&collateRecord{ID: 1, Name: "Alice", Code: "é"}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var count int64
+	if err := db.Model(&collateRecord{}).Where("name = ?", "ALICE").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected case-insensitive comparison, got %v, error %v", count, err)
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	tx := db.Session(&gorm.Session{Logger: recorder})
+	if err := tx.AutoMigrate(&collateRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected unchanged collations to be left alone, got %v", recorder.sql)
+	}
+
+	if err := tx.AutoMigrate(&collateRecordV2{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Model(&collateRecord{}).Where("code = ?", "E").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected the new collation to be applied, got %v, error %v", count, err)
+	}
+	if err := db.Model(&collateRecord{}).Where("name = ?", "alice").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected the existing collation to be preserved, got %v, error %v", count, err)
+	}
+}
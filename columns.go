@@ -0,0 +1,144 @@
+package duckdb
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ColumnsExpr selects several columns at once with DuckDB's COLUMNS expression,
+// built with Columns, ColumnsMatching or NumericColumns. Applied to an
+// aggregate, it computes the aggregate of each column, named after the column:
+//
+//	var totals struct{ Price, Quantity float64 }
+//	db.Model(&Sale{}).Select("?", duckdb.ColumnsMatching("^(price|quantity)$").Apply("sum")).Scan(&totals)
+//	db.Model(&Sale{}).Select("region, ?", duckdb.Columns().Exclude("id", "region").Apply("max")).Group("region").Find(&rows)
+type ColumnsExpr struct {
+	pattern  string
+	names    []string
+	exclude  []string
+	function string
+	alias    string
+}
+
+// Columns selects the named columns, or all columns without names
+func Columns(names ...string) ColumnsExpr {
+	return ColumnsExpr{names: names}
+}
+
+// ColumnsMatching selects the columns whose names match the regular expression
+// pattern
+func ColumnsMatching(pattern string) ColumnsExpr {
+	return ColumnsExpr{pattern: pattern}
+}
+
+// NumericColumns selects the columns of the integer and float fields of model
+// but its primary keys, e.g. to aggregate all measures of a table. It fails for
+// models without such fields.
+func NumericColumns(db *gorm.DB, model interface{}) (ColumnsExpr, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return ColumnsExpr{}, err
+	}
+	names := []string{}
+	for _, field := range stmt.Schema.Fields {
+		switch field.DataType {
+		case schema.Int, schema.Uint, schema.Float:
+			if field.DBName != "" && !field.PrimaryKey {
+				names = append(names, field.DBName)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return ColumnsExpr{}, fmt.Errorf("no numeric fields to select in %s", stmt.Schema.Name)
+	}
+	return ColumnsExpr{names: names}, nil
+}
+
+// Exclude leaves columns out of the columns selected
+func (c ColumnsExpr) Exclude(columns ...string) ColumnsExpr {
+	c.exclude = append(append([]string{}, c.exclude...), columns...)
+	return c
+}
+
+// Apply applies function, e.g. sum or max, to each column selected
+func (c ColumnsExpr) Apply(function string) ColumnsExpr {
+	c.function = function
+	return c
+}
+
+// As names the columns of the result after alias, in which \0 stands for the
+// name of the column, e.g. max_\0
+func (c ColumnsExpr) As(alias string) ColumnsExpr {
+	c.alias = alias
+	return c
+}
+
+// Build writes the COLUMNS expression, binding the pattern and names as
+// parameters. Names are excluded from the list, and columns matching the pattern
+// by a lambda, as only * has an EXCLUDE clause. Selecting no names fails, as
+// DuckDB has no COLUMNS expression of no columns.
+func (c ColumnsExpr) Build(builder clause.Builder) {
+	names := c.names
+	if names != nil && len(c.exclude) > 0 {
+		names = slices.DeleteFunc(slices.Clone(names), func(name string) bool { return slices.Contains(c.exclude, name) })
+	}
+	if names != nil && len(names) == 0 && c.pattern == "" {
+		builder.AddError(errors.New("no columns left to select in COLUMNS"))
+		return
+	}
+
+	if c.function != "" {
+		builder.WriteString(c.function)
+		builder.WriteByte('(')
+	}
+	builder.WriteString("COLUMNS(")
+	switch {
+	case c.pattern != "" && len(c.exclude) > 0:
+		builder.WriteString("c -> regexp_matches(c, ")
+		builder.AddVar(builder, c.pattern)
+		builder.WriteString(") AND c NOT IN (")
+		for i, column := range c.exclude {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			builder.AddVar(builder, column)
+		}
+		builder.WriteByte(')')
+	case c.pattern != "":
+		builder.AddVar(builder, c.pattern)
+	case names != nil:
+		builder.WriteByte('[')
+		for i, name := range names {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			builder.AddVar(builder, name)
+		}
+		builder.WriteByte(']')
+	default:
+		builder.WriteByte('*')
+		if len(c.exclude) > 0 {
+			builder.WriteString(" EXCLUDE (")
+			for i, column := range c.exclude {
+				if i > 0 {
+					builder.WriteString(", ")
+				}
+				builder.WriteQuoted(column)
+			}
+			builder.WriteByte(')')
+		}
+	}
+	builder.WriteByte(')')
+	if c.function != "" {
+		builder.WriteByte(')')
+	}
+	if c.alias != "" {
+		builder.WriteString(" AS ")
+		builder.WriteQuoted(c.alias)
+	}
+}
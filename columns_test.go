@@ -0,0 +1,92 @@
+package duckdb
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type columnsSale struct {
+	ID       uint `gorm:"primaryKey;autoIncrement:false"`
+	Region   string
+	Price    float64
+	Quantity int
+}
+
+func TestColumnsExpr(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&columnsSale{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	sales := []columnsSale{{ID: 1, Region: "east", Price: 2.5, Quantity: 3}, {ID: 2, Region: "east", Price: 4, Quantity: 5}, {ID: 3, Region: "west", Price: 1, Quantity: 7}}
+	if err := db.Create(&sales).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+
+	var totals struct {
+		Price    float64
+		Quantity int
+	}
+	if err := db.Model(&columnsSale{}).Select("?", ColumnsMatching("^(price|quantity)$").Apply("sum")).Scan(&totals).Error; err != nil || totals.Price != 7.5 || totals.Quantity != 15 {
+		t.Errorf("expected the sums of the matching columns, got %+v, error %v", totals, err)
+	}
+
+	var maxima []struct {
+		Region      string
+		MaxPrice    float64
+		MaxQuantity int
+	}
+	if err := db.Model(&columnsSale{}).Select("region, ?", Columns().Exclude("id", "region").Apply("max").As(`max_\0`)).
+		Group("region").Order("region").Scan(&maxima).Error; err != nil || len(maxima) != 2 || maxima[0].MaxPrice != 4 || maxima[1].MaxQuantity != 7 {
+		t.Errorf("expected the maxima of the other columns by region, got %+v, error %v", maxima, err)
+	}
+
+	numeric, err := NumericColumns(db, &columnsSale{})
+	if err != nil {
+		t.Fatalf("failed to get numeric columns, got error %v", err)
+	}
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&columnsSale{}).Select("?", numeric.Apply("avg")).Scan(&totals)
+	})
+	if !strings.Contains(sql, `SELECT avg(COLUMNS(['price', 'quantity'])) FROM`) {
+		t.Errorf("expected the numeric columns but the key, got %s", sql)
+	}
+	if err := db.Model(&columnsSale{}).Select("?", numeric.Apply("min")).Scan(&totals).Error; err != nil || totals.Price != 1 || totals.Quantity != 3 {
+		t.Errorf("expected the minima of the numeric columns, got %+v, error %v", totals, err)
+	}
+}
+
+type columnsLabel struct {
+	Code string `gorm:"primaryKey"`
+	Name string
+}
+
+func TestColumnsExpr_exclude(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&columnsSale{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Create(&[]columnsSale{{ID: 1, Region: "east", Price: 2.5, Quantity: 3}, {ID: 2, Region: "west", Price: 4, Quantity: 5}}).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+
+	numeric, err := NumericColumns(db, &columnsSale{})
+	if err != nil {
+		t.Fatalf("failed to get numeric columns, got error %v", err)
+	}
+	for _, columns := range []ColumnsExpr{numeric.Exclude("price"), ColumnsMatching("^(price|quantity)$").Exclude("price")} {
+		var totals []map[string]interface{}
+		if err := db.Model(&columnsSale{}).Select("?", columns.Apply("sum")).Scan(&totals).Error; err != nil || len(totals) != 1 || len(totals[0]) != 1 || totals[0]["quantity"] == nil {
+			t.Errorf("expected the sum of the columns but the excluded ones, got %v, error %v", totals, err)
+		}
+	}
+
+	var totals []map[string]interface{}
+	if err := db.Model(&columnsSale{}).Select("?", Columns("price").Exclude("price").Apply("sum")).Scan(&totals).Error; err == nil {
+		t.Errorf("expected selecting no columns to fail")
+	}
+	if _, err := NumericColumns(db, &columnsLabel{}); err == nil {
+		t.Errorf("expected a model without numeric fields to fail")
+	}
+}
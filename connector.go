@@ -0,0 +1,388 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+var errReadOnlyTxIsolation = errors.New("read-only transactions only support the default isolation level")
+
+// connector wraps the DuckDB connector so that connections can support features
+// the underlying driver lacks, such as read-only transactions
+type connector struct {
+	driver.Connector
+	// use is the target of the USE statement run on every connection, see Use
+	use atomic.Pointer[string]
+	// readers is the pool of read connections, closed with the database
+	readers *sql.DB
+	// session are the statements run on every connection opened, such as the
+	// SET statements of settings DuckDB keeps per connection
+	session []string
+	// init is called with every connection opened, see Config.ConnInit
+	init func(ctx context.Context, conn driver.Conn) error
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.session) > 0 {
+		execer, ok := dc.(driver.ExecerContext)
+		if !ok {
+			dc.Close()
+			return nil, errors.New("the DuckDB driver cannot run statements on new connections")
+		}
+		for _, statement := range c.session {
+			if _, err := execer.ExecContext(ctx, statement, nil); err != nil {
+				dc.Close()
+				return nil, err
+			}
+		}
+	}
+	if c.init != nil {
+		if err := c.init(ctx, dc); err != nil {
+			dc.Close()
+			return nil, err
+		}
+	}
+	return &conn{Conn: dc, connector: c}, nil
+}
+
+// borrowedConnector hides the Close method of a connector from the pools opened
+// on it, which would close it with them
+type borrowedConnector struct {
+	driver.Connector
+}
+
+// Connector returns the connector of the pool of db, which opens connections to
+// the same database initialized like those of the pool, e.g. to open another
+// pool with sql.OpenDB. Closing such pools leaves the database open. The
+// connections wrap those of the driver, see DriverConn. Databases opened with
+// Config.Conn have no connector.
+func Connector(db *gorm.DB) (driver.Connector, error) {
+	if pool, ok := db.ConnPool.(*connPool); ok && pool.connector != nil {
+		return borrowedConnector{pool.connector}, nil
+	}
+	return nil, errors.New("the database has no connector of the dialector")
+}
+
+// DriverConn returns the connection of the driver wrapped by a connection of
+// the dialector, as passed to the function of sql.Conn.Raw, e.g. to use it with
+// go-duckdb's Arrow interface, and other connections as they are
+func DriverConn(c interface{}) interface{} {
+	if c, ok := c.(*conn); ok {
+		return c.Conn
+	}
+	return c
+}
+
+func (c *connector) Close() error {
+	if c.readers != nil {
+		c.readers.Close()
+	}
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+var sharedConnectors = struct {
+	sync.Mutex
+	connectors map[string]*sharedConnector
+}{connectors: map[string]*sharedConnector{}}
+
+// sharedConnector is an in-memory database shared by every dialector opened with
+// the same name. The database is closed when the last pool using it is closed.
+type sharedConnector struct {
+	driver.Connector
+	name string
+	refs int
+}
+
+// openSharedConnector returns the connector of the named in-memory database,
+// calling open to create it when no pool uses it yet
+func openSharedConnector(name string, open func() (driver.Connector, error)) (*sharedConnector, error) {
+	sharedConnectors.Lock()
+	defer sharedConnectors.Unlock()
+
+	c, ok := sharedConnectors.connectors[name]
+	if !ok {
+		base, err := open()
+		if err != nil {
+			return nil, err
+		}
+		c = &sharedConnector{Connector: base, name: name}
+		sharedConnectors.connectors[name] = c
+	}
+	c.refs++
+	return c, nil
+}
+
+func (c *sharedConnector) Close() error {
+	sharedConnectors.Lock()
+	defer sharedConnectors.Unlock()
+
+	if c.refs--; c.refs > 0 {
+		return nil
+	}
+	delete(sharedConnectors.connectors, c.name)
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+type conn struct {
+	driver.Conn
+	connector  *connector
+	used       string
+	tx         bool
+	readOnlyTx bool
+	// readOnly runs queries outside of transactions in read-only ones, as the
+	// connections of the readers do
+	readOnly bool
+}
+
+// applyUse runs the USE statement of the connector when it changed since the
+// connection last ran it, outside of transactions so that they keep their target
+func (c *conn) applyUse(ctx context.Context) error {
+	if c.connector == nil || c.tx || c.readOnlyTx {
+		return nil
+	}
+	target := c.connector.use.Load()
+	if target == nil || *target == c.used {
+		return nil
+	}
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil
+	}
+	if _, err := execer.ExecContext(ctx, "USE "+*target, nil); err != nil {
+		return err
+	}
+	c.used = *target
+	return nil
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if value, ok := nv.Value.(sensitiveValue); ok {
+		nv.Value = value.value
+	}
+	// database/sql rejects unsigned values beyond the range of int64, which the
+	// driver binds as UBIGINT
+	if value := reflect.ValueOf(nv.Value); value.CanUint() && value.Uint() > math.MaxInt64 {
+		nv.Value = value.Uint()
+		return nil
+	}
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.applyUse(ctx); err != nil {
+		return nil, err
+	}
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.applyUse(ctx); err != nil {
+		return nil, err
+	}
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	// the read-only transaction of read connections and the statement context
+	// of transactions end when the rows are closed
+	var end func() error
+	if c.readOnly && !c.tx && !c.readOnlyTx {
+		tx, err := c.BeginTx(ctx, driver.TxOptions{ReadOnly: true})
+		if err != nil {
+			return nil, err
+		}
+		end = tx.Rollback
+	}
+	end = releasing(ctx, end)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		if end != nil {
+			end()
+		}
+		return nil, err
+	}
+	return convertRows(rows, end), nil
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := c.applyUse(ctx); err != nil {
+		return nil, err
+	}
+	var (
+		s   driver.Stmt
+		err error
+	)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = preparer.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s}, nil
+}
+
+// stmt converts the rows of prepared queries as conn.QueryContext does, e.g.
+// with gorm's PrepareStmt
+type stmt struct {
+	driver.Stmt
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+	end := releasing(ctx, nil)
+	if err != nil {
+		if end != nil {
+			end()
+		}
+		return nil, err
+	}
+	return convertRows(rows, end), nil
+}
+
+// releasing returns end preceded by the release ctx holds for the query, see
+// releaseKey
+func releasing(ctx context.Context, end func() error) func() error {
+	release, ok := ctx.Value(releaseKey{}).(func())
+	if !ok {
+		return end
+	}
+	return func() error {
+		release()
+		if end != nil {
+			return end()
+		}
+		return nil
+	}
+}
+
+// namedValues returns the values of args, which must not be named, for
+// statements that only take positional values
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("the DuckDB driver does not support named parameters in this statement")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// BeginTx starts a read-only transaction with BEGIN TRANSACTION READ ONLY when
+// requested, as the DuckDB driver rejects sql.TxOptions.ReadOnly
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if !opts.ReadOnly {
+		if err := c.applyUse(ctx); err != nil {
+			return nil, err
+		}
+		var (
+			tx  driver.Tx
+			err error
+		)
+		if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+			tx, err = beginner.BeginTx(ctx, opts)
+		} else {
+			tx, err = c.Conn.Begin()
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.tx = true
+		return &connTx{Tx: tx, conn: c}, nil
+	}
+
+	if sql.IsolationLevel(opts.Isolation) != sql.LevelDefault {
+		return nil, errReadOnlyTxIsolation
+	}
+	if _, err := c.ExecContext(ctx, "BEGIN TRANSACTION READ ONLY", nil); err != nil {
+		return nil, err
+	}
+	c.readOnlyTx = true
+	return &readOnlyTx{conn: c}, nil
+}
+
+// connTx marks its connection as in a transaction until it ends
+type connTx struct {
+	driver.Tx
+	conn *conn
+}
+
+func (tx *connTx) Commit() error {
+	defer func() { tx.conn.tx = false }()
+	return tx.Tx.Commit()
+}
+
+func (tx *connTx) Rollback() error {
+	defer func() { tx.conn.tx = false }()
+	return tx.Tx.Rollback()
+}
+
+type readOnlyTx struct {
+	conn *conn
+}
+
+func (tx *readOnlyTx) Commit() error {
+	return tx.end("COMMIT")
+}
+
+func (tx *readOnlyTx) Rollback() error {
+	return tx.end("ROLLBACK")
+}
+
+func (tx *readOnlyTx) end(query string) error {
+	if !tx.conn.readOnlyTx {
+		return sql.ErrTxDone
+	}
+	tx.conn.readOnlyTx = false
+	_, err := tx.conn.ExecContext(context.Background(), query, nil)
+	return err
+}
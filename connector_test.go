@@ -0,0 +1,119 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSharedMemory(t *testing.T) {
+	fixtures := openTestDB(t, Config{SharedMemory: "shared_memory_test"})
+	if err := fixtures.Exec("CREATE TABLE shared_records AS SELECT 42 AS id").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	db := openTestDB(t, Config{DSN: ":memory:shared_memory_test"})
+	var id int
+	if err := db.Raw("SELECT id FROM shared_records").Scan(&id).Error; err != nil || id != 42 {
+		t.Errorf("expected to read the shared table, got %v, error %v", id, err)
+	}
+
+	other := openTestDB(t, Config{SharedMemory: "other_memory_test"})
+	if err := other.Exec("SELECT id FROM shared_records").Error; err == nil {
+		t.Errorf("expected differently named databases not to be shared")
+	}
+
+	if sqlDB, err := fixtures.DB(); err == nil {
+		sqlDB.Close()
+	}
+	if err := db.Raw("SELECT id FROM shared_records").Scan(&id).Error; err != nil {
+		t.Errorf("expected the database to stay open while in use, got error %v", err)
+	}
+}
+
+func TestConnector_session(t *testing.T) {
+	base, err := Dialector{Config: &Config{}}.openConnector("")
+	if err != nil {
+		t.Fatalf("failed to open connector, got error %v", err)
+	}
+	c := &connector{Connector: base, session: []string{"CREATE SCHEMA IF NOT EXISTS session_test", "SET search_path = 'session_test'"}}
+	sqlDB := sql.OpenDB(c)
+	defer sqlDB.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			t.Fatalf("failed to connect, got error %v", err)
+		}
+		defer conn.Close()
+		var searchPath string
+		if err := conn.QueryRowContext(ctx, "SELECT current_setting('search_path')").Scan(&searchPath); err != nil || searchPath != "session_test" {
+			t.Errorf("expected the session statements to run on connection %d, got %q, error %v", i, searchPath, err)
+		}
+	}
+}
+
+func TestConnector(t *testing.T) {
+	var inits atomic.Int64
+	db := openTestDB(t, Config{
+		BootQueries: []string{"SET search_path = 'main'"},
+		ConnInit: func(ctx context.Context, dc driver.Conn) error {
+			if _, ok := dc.(*conn); ok {
+				return errors.New("expected the connection of the driver")
+			}
+			inits.Add(1)
+			_, err := dc.(driver.ExecerContext).ExecContext(ctx, "SET errors_as_json = true", nil)
+			return err
+		},
+	})
+	if err := db.Exec("CREATE TABLE connector_records AS SELECT 42 AS id").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	c, err := Connector(db)
+	if err != nil {
+		t.Fatalf("failed to get connector, got error %v", err)
+	}
+	other := sql.OpenDB(c)
+	var id int64
+	var errorsAsJSON string
+	if err := other.QueryRow("SELECT id, current_setting('errors_as_json') FROM connector_records").Scan(&id, &errorsAsJSON); err != nil ||
+		id != 42 || errorsAsJSON != "true" {
+		t.Errorf("expected an initialized connection to the same database, got %d and %q, error %v", id, errorsAsJSON, err)
+	}
+	if err := other.Close(); err != nil {
+		t.Fatalf("failed to close pool, got error %v", err)
+	}
+	if err := db.Exec("SELECT 1").Error; err != nil {
+		t.Errorf("expected the database to stay open, got error %v", err)
+	}
+	if inits.Load() < 2 {
+		t.Errorf("expected ConnInit to be called for every connection, got %d calls", inits.Load())
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get pool, got error %v", err)
+	}
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("failed to connect, got error %v", err)
+	}
+	defer conn.Close()
+	if err := conn.Raw(func(dc interface{}) error {
+		if _, ok := DriverConn(dc).(driver.Conn); !ok || DriverConn(dc) == dc {
+			t.Errorf("expected DriverConn to unwrap %T", dc)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to get the driver connection, got error %v", err)
+	}
+
+	if _, err := Connector(openTestDB(t, Config{Conn: sqlDB})); err == nil {
+		t.Errorf("expected databases opened with Config.Conn to have no connector")
+	}
+}
@@ -0,0 +1,137 @@
+package duckdb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maxDefaultsBatch bounds the number of default values evaluated at once for
+// columns left out of CopyFrom
+const maxDefaultsBatch = 2048
+
+// CopyFrom streams rows into table through the appender, in the manner of pgx's
+// CopyFrom, and returns the number of rows copied. rows is called until it
+// returns io.EOF and each row holds the values of columns, or of all columns of
+// the table in order when columns is empty. Values are converted as by
+// AppendModels, and columns left out get their default or NULL.
+func CopyFrom(db *gorm.DB, table string, columns []string, rows func() ([]any, error)) (int64, error) {
+	tableColumns, err := loadAppenderColumns(db, table)
+	if err != nil {
+		return 0, err
+	}
+
+	positions := make([]int, len(tableColumns))
+	for i := range positions {
+		positions[i] = -1
+	}
+	if len(columns) == 0 {
+		for i := range positions {
+			positions[i] = i
+		}
+		columns = make([]string, len(tableColumns))
+		for i, column := range tableColumns {
+			columns[i] = column.Name
+		}
+	}
+	for i, name := range columns {
+		found := false
+		for j, column := range tableColumns {
+			if strings.EqualFold(column.Name, name) {
+				positions[j], found = i, true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("column %q does not exist in table %q", name, table)
+		}
+	}
+
+	defaults := make([]*defaultSource, len(tableColumns))
+	for i, column := range tableColumns {
+		if positions[i] < 0 && column.Default.Valid {
+			defaults[i] = &defaultSource{db: db, expr: column.Default.String}
+		}
+	}
+
+	appender, err := NewAppender(db, table)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		row   = make([]driver.Value, len(tableColumns))
+		count int64
+	)
+	for {
+		values, err := rows()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			appender.Close()
+			return count, fmt.Errorf("row %d: %w", count, err)
+		}
+		if len(values) != len(columns) {
+			appender.Close()
+			return count, fmt.Errorf("row %d: expected %d values, got %d", count, len(columns), len(values))
+		}
+
+		for i, column := range tableColumns {
+			switch {
+			case positions[i] >= 0:
+				row[i], err = appenderValue(column.DataType, column, values[positions[i]])
+			case defaults[i] != nil:
+				row[i], err = defaults[i].next()
+			default:
+				row[i] = nil
+			}
+			if err != nil {
+				appender.Close()
+				return count, fmt.Errorf("row %d, column %q: %w", count, column.Name, err)
+			}
+		}
+		if err := appender.AppendRow(row...); err != nil {
+			appender.Close()
+			return count, fmt.Errorf("row %d: %w", count, err)
+		}
+		count++
+	}
+	return count, appender.Close()
+}
+
+// defaultSource evaluates a column default in batches, so that sequences and
+// functions such as now() or uuid() yield a value per row. Batches double in
+// size, so fewer sequence values than rows copied are left unused.
+type defaultSource struct {
+	db     *gorm.DB
+	expr   string
+	batch  int
+	values []interface{}
+}
+
+func (s *defaultSource) next() (driver.Value, error) {
+	if len(s.values) == 0 {
+		s.batch = min(max(s.batch*2, 1), maxDefaultsBatch)
+		rows, err := s.db.Session(&gorm.Session{NewDB: true}).Raw(fmt.Sprintf("SELECT %s FROM range(%d)", s.expr, s.batch)).Rows()
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var value interface{}
+			if err := rows.Scan(&value); err != nil {
+				return nil, err
+			}
+			s.values = append(s.values, value)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	value := s.values[0]
+	s.values = s.values[1:]
+	return value, nil
+}
@@ -0,0 +1,64 @@
+package duckdb
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCopyFrom(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec(`CREATE SEQUENCE copy_records_seq;
+		CREATE TABLE copy_records (id INTEGER DEFAULT nextval('copy_records_seq'), name VARCHAR, amount DECIMAL(8,2), note VARCHAR)`).Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	n := 0
+	count, err := CopyFrom(db, "copy_records", []string{"amount", "name"}, func() ([]any, error) {
+		if n == 5000 {
+			return nil, io.EOF
+		}
+		n++
+		return []any{float64(n) / 4, "r"}, nil
+	})
+	if err != nil || count != 5000 {
+		t.Fatalf("failed to copy rows, got %v rows, error %v", count, err)
+	}
+
+	var summary string
+	if err := db.Raw("SELECT concat_ws('|', count(DISTINCT id), min(id), max(id), sum(amount), count(note)) FROM copy_records").Find(&summary).Error; err != nil {
+		t.Fatalf("failed to read rows, got error %v", err)
+	}
+	if summary != "5000|1|5000|3125625.00|0" {
+		t.Errorf("expected sequence defaults and converted values, got %q", summary)
+	}
+
+	t.Run("all columns", func(t *testing.T) {
+		rows := [][]any{{int64(-1), "x", "1.5", nil}}
+		count, err := CopyFrom(db, "copy_records", nil, func() ([]any, error) {
+			if len(rows) == 0 {
+				return nil, io.EOF
+			}
+			row := rows[0]
+			rows = rows[1:]
+			return row, nil
+		})
+		if err != nil || count != 1 {
+			t.Errorf("failed to copy rows, got %v rows, error %v", count, err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		errSource := errors.New("source failed")
+		if _, err := CopyFrom(db, "copy_records", []string{"name"}, func() ([]any, error) { return nil, errSource }); !errors.Is(err, errSource) {
+			t.Errorf("expected the source error, got %v", err)
+		}
+		if _, err := CopyFrom(db, "copy_records", []string{"missing"}, nil); err == nil || !strings.Contains(err.Error(), `"missing"`) {
+			t.Errorf("expected an unknown column error, got %v", err)
+		}
+		if _, err := CopyFrom(db, "copy_records", []string{"amount"}, func() ([]any, error) { return []any{"abc"}, nil }); err == nil || !strings.Contains(err.Error(), `row 0, column "amount"`) {
+			t.Errorf("expected an error naming the row and column, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// ErrNoDriver is returned when the package is built without the bundled DuckDB
+// bindings (without cgo or with the duckdb_nodriver build tag) and neither
+// Config.Driver nor Config.DriverName is set
+var ErrNoDriver = errors.New("no DuckDB driver available, set Config.Driver or Config.DriverName")
+
+// openConnector opens dsn with the configured driver, falling back to the
+// bundled go-duckdb bindings
+func (dialector Dialector) openConnector(dsn string) (driver.Connector, error) {
+	if dialector.Driver != nil {
+		return dialector.Driver.OpenConnector(dsn)
+	}
+
+	if dialector.DriverName != "" {
+		db, err := sql.Open(dialector.DriverName, dsn)
+		if err != nil {
+			return nil, err
+		}
+		drv := db.Driver()
+		if err := db.Close(); err != nil {
+			return nil, err
+		}
+		if driverContext, ok := drv.(driver.DriverContext); ok {
+			return driverContext.OpenConnector(dsn)
+		}
+		return dsnConnector{dsn: dsn, driver: drv}, nil
+	}
+
+	if defaultDriver == nil {
+		return nil, ErrNoDriver
+	}
+	return defaultDriver.OpenConnector(dsn)
+}
+
+// dsnConnector adapts drivers that do not implement driver.DriverContext
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
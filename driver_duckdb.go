@@ -0,0 +1,23 @@
+//go:build cgo && !duckdb_nodriver
+
+package duckdb
+
+import (
+	"database/sql/driver"
+	"time"
+
+	goduckdb "github.com/marcboeker/go-duckdb"
+)
+
+var defaultDriver driver.DriverContext = goduckdb.Driver{}
+
+// intervalDuration returns an INTERVAL value read from the database as a
+// time.Duration, counting days as 24 hours and months as 30 days
+func intervalDuration(value driver.Value) driver.Value {
+	interval, ok := value.(goduckdb.Interval)
+	if !ok {
+		return value
+	}
+	days := int64(interval.Months)*30 + int64(interval.Days)
+	return time.Duration(days)*24*time.Hour + time.Duration(interval.Micros)*time.Microsecond
+}
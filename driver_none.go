@@ -0,0 +1,13 @@
+//go:build !cgo || duckdb_nodriver
+
+package duckdb
+
+import "database/sql/driver"
+
+var defaultDriver driver.DriverContext
+
+// intervalDuration returns value as it is, as there is no driver returning
+// intervals
+func intervalDuration(value driver.Value) driver.Value {
+	return value
+}
@@ -0,0 +1,41 @@
+package duckdb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var errStubDriver = errors.New("stub driver")
+
+type stubDriver struct {
+	dsn string
+}
+
+func (d *stubDriver) Open(string) (driver.Conn, error) {
+	return nil, errStubDriver
+}
+
+func (d *stubDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	d.dsn = dsn
+	return nil, errStubDriver
+}
+
+func TestDialector_openConnector(t *testing.T) {
+	stub := &stubDriver{}
+	_, err := gorm.Open(New(Config{DSN: "app.duckdb?threads=2", Driver: stub}), &gorm.Config{Logger: logger.Discard})
+	if !errors.Is(err, errStubDriver) {
+		t.Errorf("expected the configured driver to be used, got error %v", err)
+	}
+	if stub.dsn != "app.duckdb?threads=2" {
+		t.Errorf("expected the DSN to be passed to the driver, got %q", stub.dsn)
+	}
+
+	db := openTestDB(t, Config{DriverName: "duckdb"})
+	if err := db.Exec("SELECT 1").Error; err != nil {
+		t.Errorf("expected the registered driver to be used, got error %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package duckdb
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidDSN is returned when the DSN cannot be parsed or contains an invalid option
+var ErrInvalidDSN = errors.New("invalid DSN")
+
+// DSN is a parsed DuckDB data source name: a database path followed by optional
+// `?key=value` configuration options.
+type DSN struct {
+	Path    string
+	Options url.Values
+}
+
+// AccessMode is the mode a database file is opened in, see Config.AccessMode
+type AccessMode string
+
+const (
+	// AccessModeAutomatic opens the database read-write, or read-only when the
+	// file is opened read-only by another process
+	AccessModeAutomatic AccessMode = "automatic"
+	// AccessModeReadOnly opens the database read-only, so that statements
+	// changing it fail and other processes may open it read-only too
+	AccessModeReadOnly AccessMode = "read_only"
+	// AccessModeReadWrite opens the database read-write
+	AccessModeReadWrite AccessMode = "read_write"
+)
+
+var sizePattern = regexp.MustCompile(`(?i)^\s*\d+(\.\d+)?\s*(b|bytes|kb|kib|mb|mib|gb|gib|tb|tib|k|m|g|t)?\s*$`)
+
+func validateBool(v string) error {
+	if _, err := strconv.ParseBool(v); err != nil {
+		return fmt.Errorf("expected a boolean, got %q", v)
+	}
+	return nil
+}
+
+func validateUint(v string) error {
+	if n, err := strconv.ParseUint(v, 10, 64); err != nil || n == 0 {
+		return fmt.Errorf("expected a positive integer, got %q", v)
+	}
+	return nil
+}
+
+func validateSize(v string) error {
+	if !sizePattern.MatchString(v) {
+		return fmt.Errorf("expected a size such as 512MB or 2GB, got %q", v)
+	}
+	return nil
+}
+
+func validateString(v string) error {
+	return nil
+}
+
+func validateOneOf(values ...string) func(string) error {
+	return func(v string) error {
+		for _, value := range values {
+			if strings.EqualFold(v, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s, got %q", strings.Join(values, ", "), v)
+	}
+}
+
+// dsnOptions lists the DuckDB configuration options checked by ParseDSN. Options
+// missing here are passed through to the driver unless they look like a typo of
+// a known one.
+var dsnOptions = map[string]func(string) error{
+	"access_mode":                  validateOneOf("automatic", "read_only", "read_write"),
+	"allow_unsigned_extensions":    validateBool,
+	"autoinstall_known_extensions": validateBool,
+	"autoload_known_extensions":    validateBool,
+	"checkpoint_threshold":         validateSize,
+	"custom_user_agent":            validateString,
+	"default_null_order":           validateOneOf("nulls_first", "nulls_last", "nulls_first_on_asc_last_on_desc", "nulls_last_on_asc_first_on_desc"),
+	"default_order":                validateOneOf("asc", "ascending", "desc", "descending"),
+	"enable_external_access":       validateBool,
+	"enable_object_cache":          validateBool,
+	"extension_directory":          validateString,
+	"external_threads":             validateUint,
+	"lock_configuration":           validateBool,
+	"max_memory":                   validateSize,
+	"max_temp_directory_size":      validateSize,
+	"memory_limit":                 validateSize,
+	"motherduck_token":             validateString,
+	"preserve_insertion_order":     validateBool,
+	"temp_directory":               validateString,
+	"threads":                      validateUint,
+	"timezone":                     validateString,
+	"wal_autocheckpoint":           validateSize,
+	"worker_threads":               validateUint,
+}
+
+// localOptions are the DuckDB settings kept per connection, which DuckDB
+// rejects as options of the database. The dialector sets them on every
+// connection instead of passing them to the driver, see DSN.TakeLocalOptions.
+var localOptions = map[string]bool{
+	"calendar":                               true,
+	"custom_profiling_settings":              true,
+	"enable_http_logging":                    true,
+	"enable_profiling":                       true,
+	"enable_progress_bar":                    true,
+	"enable_progress_bar_print":              true,
+	"errors_as_json":                         true,
+	"explain_output":                         true,
+	"file_search_path":                       true,
+	"home_directory":                         true,
+	"http_logging_output":                    true,
+	"ieee_floating_point_ops":                true,
+	"integer_division":                       true,
+	"log_query_path":                         true,
+	"max_expression_depth":                   true,
+	"merge_join_threshold":                   true,
+	"nested_loop_join_threshold":             true,
+	"order_by_non_integer_literal":           true,
+	"ordered_aggregate_threshold":            true,
+	"partitioned_write_flush_threshold":      true,
+	"partitioned_write_max_open_files":       true,
+	"perfect_ht_threshold":                   true,
+	"pivot_filter_threshold":                 true,
+	"pivot_limit":                            true,
+	"prefer_range_joins":                     true,
+	"preserve_identifier_case":               true,
+	"profile_output":                         true,
+	"profiling_mode":                         true,
+	"profiling_output":                       true,
+	"progress_bar_time":                      true,
+	"scalar_subquery_error_on_multiple_rows": true,
+	"schema":                                 true,
+	"search_path":                            true,
+	"streaming_buffer_size":                  true,
+	"timezone":                               true,
+}
+
+// deprecatedOptions maps option aliases kept for compatibility to their
+// current names
+var deprecatedOptions = map[string]string{
+	"max_memory":         "memory_limit",
+	"worker_threads":     "threads",
+	"wal_autocheckpoint": "checkpoint_threshold",
+}
+
+// deprecationWarning describes the use of a deprecated option alias, if name is one
+func deprecationWarning(name string) string {
+	if replacement, ok := deprecatedOptions[strings.ToLower(name)]; ok {
+		return fmt.Sprintf("option %q is deprecated, use %q instead", name, replacement)
+	}
+	return ""
+}
+
+// ParseDSN splits dsn into its path and options and validates the options known
+// to DuckDB, so that misspelled or malformed settings are reported when the
+// dialector is initialized instead of on the first query.
+func ParseDSN(dsn string) (*DSN, error) {
+	path, rawQuery, _ := strings.Cut(dsn, "?")
+	options, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDSN, err)
+	}
+
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		validate, ok := dsnOptions[strings.ToLower(key)]
+		if !ok {
+			if suggestion := suggestDSNOption(key); suggestion != "" {
+				return nil, fmt.Errorf("%w: unknown option %q, did you mean %q?", ErrInvalidDSN, key, suggestion)
+			}
+			continue
+		}
+		if len(options[key]) > 1 {
+			return nil, fmt.Errorf("%w: option %q is given more than once", ErrInvalidDSN, key)
+		}
+		if err := validate(options.Get(key)); err != nil {
+			return nil, fmt.Errorf("%w: option %q: %v", ErrInvalidDSN, key, err)
+		}
+	}
+
+	return &DSN{Path: path, Options: options}, nil
+}
+
+// String formats the DSN back into the form accepted by the driver
+func (d *DSN) String() string {
+	if len(d.Options) == 0 {
+		return d.Path
+	}
+	return d.Path + "?" + d.Options.Encode()
+}
+
+// TakeLocalOptions removes the options DuckDB keeps per connection, such as
+// search_path or errors_as_json, from d and returns them, to be set on every
+// connection rather than passed to the driver, by their lowercase name
+func (d *DSN) TakeLocalOptions() map[string]string {
+	options := map[string]string{}
+	for name := range d.Options {
+		if localOptions[strings.ToLower(name)] {
+			options[strings.ToLower(name)] = d.Options.Get(name)
+			d.Options.Del(name)
+		}
+	}
+	return options
+}
+
+// Warnings describes options that DuckDB accepts but that are likely unintended:
+// deprecated aliases and options passed to the driver unchecked. These are
+// warnings about the DSN only: the driver exposes no warnings or notices of
+// DuckDB itself, so the messages of statements are not logged.
+func (d *DSN) Warnings() (warnings []string) {
+	names := make([]string, 0, len(d.Options))
+	for name := range d.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if warning := deprecationWarning(name); warning != "" {
+			warnings = append(warnings, warning)
+		} else if _, ok := dsnOptions[strings.ToLower(name)]; !ok && !localOptions[strings.ToLower(name)] {
+			warnings = append(warnings, fmt.Sprintf("option %q is not known to the dialector and is passed to DuckDB unchecked", name))
+		}
+	}
+	return
+}
+
+// suggestDSNOption returns the known option closest to key when key is likely a
+// typo of it, or an empty string otherwise.
+func suggestDSNOption(key string) (suggestion string) {
+	key = strings.ToLower(key)
+	best := -1
+	for name := range dsnOptions {
+		distance := levenshtein(key, name)
+		if distance > 2 || distance > len(name)/4 {
+			continue
+		}
+		if best < 0 || distance < best || (distance == best && name < suggestion) {
+			best, suggestion = distance, name
+		}
+	}
+	return suggestion
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,129 @@
+package duckdb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		dsn      string
+		wantPath string
+		wantErr  string
+	}{
+		{
+			name:     "it should parse an empty DSN",
+			dsn:      "",
+			wantPath: "",
+		},
+		{
+			name:     "it should parse a path without options",
+			dsn:      "data/app.duckdb",
+			wantPath: "data/app.duckdb",
+		},
+		{
+			name:     "it should accept known options",
+			dsn:      "app.duckdb?access_mode=READ_ONLY&threads=4&memory_limit=2GB",
+			wantPath: "app.duckdb",
+		},
+		{
+			name:     "it should pass through unknown options",
+			dsn:      ":memory:?s3_region=us-east-1",
+			wantPath: ":memory:",
+		},
+		{
+			name:    "it should suggest the closest option for typos",
+			dsn:     "app.duckdb?acces_mode=read_only",
+			wantErr: `unknown option "acces_mode", did you mean "access_mode"?`,
+		},
+		{
+			name:    "it should reject invalid enum values",
+			dsn:     "app.duckdb?access_mode=readonly",
+			wantErr: `option "access_mode": expected one of automatic, read_only, read_write, got "readonly"`,
+		},
+		{
+			name:    "it should reject invalid thread counts",
+			dsn:     "app.duckdb?threads=0",
+			wantErr: `option "threads": expected a positive integer, got "0"`,
+		},
+		{
+			name:    "it should reject invalid sizes",
+			dsn:     "app.duckdb?memory_limit=lots",
+			wantErr: `option "memory_limit": expected a size such as 512MB or 2GB, got "lots"`,
+		},
+		{
+			name:    "it should reject invalid booleans",
+			dsn:     "app.duckdb?enable_external_access=nope",
+			wantErr: `option "enable_external_access": expected a boolean, got "nope"`,
+		},
+		{
+			name:    "it should reject repeated options",
+			dsn:     "app.duckdb?threads=1&threads=2",
+			wantErr: `option "threads" is given more than once`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDSN(tt.dsn)
+			if tt.wantErr != "" {
+				if err == nil || !errors.Is(err, ErrInvalidDSN) || !strings.HasSuffix(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseDSN() expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDSN() unexpected error %v", err)
+			}
+			if got.Path != tt.wantPath {
+				t.Errorf("ParseDSN() path = %q, want %q", got.Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestDSN_TakeLocalOptions(t *testing.T) {
+	dsn, err := ParseDSN("app.duckdb?threads=4&Search_Path=analytics&errors_as_json=true")
+	if err != nil {
+		t.Fatalf("ParseDSN() unexpected error %v", err)
+	}
+	if warnings := dsn.Warnings(); len(warnings) != 0 {
+		t.Errorf("expected no warnings for local options, got %v", warnings)
+	}
+	local := dsn.TakeLocalOptions()
+	if len(local) != 2 || local["search_path"] != "analytics" || local["errors_as_json"] != "true" {
+		t.Errorf("expected the local options, got %v", local)
+	}
+	if got := dsn.String(); got != "app.duckdb?threads=4" {
+		t.Errorf("expected the other options to be kept, got %q", got)
+	}
+}
+
+func TestDialector_Initialize_dsnOptions(t *testing.T) {
+	db := openTestDB(t, Config{DSN: ":memory:?threads=2&memory_limit=1GB&errors_as_json=true&integer_division=true"})
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get pool, got error %v", err)
+	}
+	// every connection of the pool gets the local options
+	for i := 0; i < 2; i++ {
+		conn, err := sqlDB.Conn(context.Background())
+		if err != nil {
+			t.Fatalf("failed to open connection, got error %v", err)
+		}
+		defer conn.Close()
+
+		var threads, quotient int64
+		var memoryLimit, errorsAsJSON string
+		if err := conn.QueryRowContext(context.Background(), "SELECT current_setting('threads'), current_setting('memory_limit'), current_setting('errors_as_json'), 7 / 2").
+			Scan(&threads, &memoryLimit, &errorsAsJSON, &quotient); err != nil {
+			t.Fatalf("failed to read settings, got error %v", err)
+		}
+		if threads != 2 || memoryLimit != "953.6 MiB" || errorsAsJSON != "true" || quotient != 3 {
+			t.Errorf("expected the DSN options to apply, got threads %d, memory_limit %q, errors_as_json %q, 7 / 2 = %d",
+				threads, memoryLimit, errorsAsJSON, quotient)
+		}
+	}
+}
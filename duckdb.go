@@ -1,14 +1,22 @@
 package duckdb
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	_ "github.com/marcboeker/go-duckdb" // DuckDB ドライバーを登録
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
-	"gorm.io/gorm/logger"
 	"gorm.io/gorm/migrator"
 	"gorm.io/gorm/schema"
 )
@@ -18,9 +26,139 @@ type Dialector struct {
 }
 
 type Config struct {
+	// Driver opens the database instead of the bundled go-duckdb bindings
+	Driver driver.DriverContext
+	// DriverName selects a registered database/sql driver instead of the
+	// bundled go-duckdb bindings
 	DriverName string
 	DSN        string
 	Conn       gorm.ConnPool
+	// Settings are applied with SET after connecting, for options that cannot be
+	// given in the DSN such as settings of autoloaded extensions (e.g. s3_region)
+	Settings map[string]string
+	// Version is the linked DuckDB version, e.g. v1.1.3. It is detected at
+	// Initialize when empty and used to gate version-dependent SQL.
+	Version string
+	// DisableExternalAccess sets enable_external_access=false, preventing queries
+	// from reading or writing files, attaching databases and installing extensions
+	DisableExternalAccess bool
+	// AllowUnsignedExtensions permits loading extensions without a valid
+	// signature. DuckDB only takes it when the database starts, so it does not
+	// apply to Config.Conn, whose connector must set it instead.
+	AllowUnsignedExtensions bool
+	// AccessMode opens the database file in the mode, replacing the access_mode
+	// of the DSN, e.g. AccessModeReadOnly for services that must never change a
+	// shared database. It does not apply to Config.Conn.
+	AccessMode AccessMode
+	// Extensions are installed unless they are, e.g. bundled ones such as json
+	// and parquet, and loaded at Initialize before any connection of the pool
+	// is opened, e.g. httpfs or spatial. DuckDB loads extensions into the
+	// database, so they serve every connection of the pool.
+	Extensions []string
+	// LockConfiguration sets lock_configuration=true once all other settings are
+	// applied, so that queries cannot change the configuration afterwards
+	LockConfiguration bool
+	// CreateDirs creates missing parent directories of a file database at Initialize
+	CreateDirs bool
+	// DirMode is the permission of directories created by CreateDirs, 0755 by default
+	DirMode os.FileMode
+	// SharedMemory names an in-memory database shared by all dialectors opened
+	// with the same name in this process, like a DSN of `:memory:name`. The
+	// options of the first one opened apply.
+	SharedMemory string
+	// StatementTimeout interrupts statements running longer than it, which for
+	// Row and Rows lasts until their rows are closed; zero disables the timeout
+	StatementTimeout time.Duration
+	// Locking controls how FOR UPDATE/FOR SHARE clauses are handled, they are
+	// dropped with a warning by default
+	Locking LockingMode
+	// MemoryLimit bounds the memory DuckDB uses, e.g. 4GB, beyond which large
+	// operations spill to TempDirectory
+	MemoryLimit string
+	// Threads is the number of threads DuckDB runs queries on, the number of
+	// cores by default
+	Threads int
+	// TempDirectory is where large sorts, joins and aggregations spill to disk
+	TempDirectory string
+	// MaxTempDirectorySize limits the disk space used for spilling, e.g. 20GB
+	MaxTempDirectorySize string
+	// DisableInsertionOrder sets preserve_insertion_order=false for the whole
+	// database, see WithoutInsertionOrder to disable it for single loads only
+	DisableInsertionOrder bool
+	// AppenderFlushRows and AppenderFlushInterval are the default AppenderOptions
+	AppenderFlushRows     int
+	AppenderFlushInterval time.Duration
+	// MigrationHooks are called by the Migrator around schema changes
+	MigrationHooks MigrationHooks
+	// AuditDDL records every statement executed by the Migrator, with its start
+	// time and duration, into the schema_migrations_log table
+	AuditDDL bool
+	// QueryStats is called with the statistics of each create, query, update,
+	// delete and exec; statements run with Row or Rows are not reported
+	QueryStats func(ctx context.Context, stats QueryStats)
+	// ProfileQueries enables the DuckDB profiler for the statements reported to
+	// QueryStats, adding rows scanned and peak memory at the cost of pinning a
+	// connection and writing a profile per statement
+	ProfileQueries bool
+	// EncryptionKey is the AES key of fields with the encrypted serializer, see
+	// EncryptedSerializer; EncryptionKeyFunc can fetch it instead, e.g. from a KMS
+	EncryptionKey     []byte
+	EncryptionKeyFunc func(ctx context.Context) ([]byte, error)
+	// InListThreshold is the number of values above which IN conditions are
+	// loaded into a temporary table and matched by a sub-query, e.g. for lookups
+	// of millions of keys; zero inlines all values
+	InListThreshold int
+	// IdentifierMaxLength is the length above which the names NamingStrategy
+	// generates for indexes and constraints are truncated with a hash suffix, 64
+	// by default
+	IdentifierMaxLength int
+	// TypeMapper returns the column type of a field, e.g. to store all times as
+	// TIMESTAMPTZ, and reports false for the built-in type. Fields with a type
+	// tag are passed too, with the tag as their DataType.
+	TypeMapper func(field *schema.Field) (string, bool)
+	// ClauseBuilders are registered on the database at Initialize after the
+	// dialector's own, which they replace for the same clause, so that packages
+	// can render clauses for DuckDB without patching the dialector
+	ClauseBuilders map[string]clause.ClauseBuilder
+	// RedactParameters replaces all bind values in the SQL passed to the logger,
+	// MigrationHooks.OnDDL and the DDL audit with [REDACTED], keeping the
+	// statement text. Fields tagged sensitive are redacted regardless.
+	RedactParameters bool
+	// TypeAliases are other names of database types, by the lowercase name
+	// DuckDB reports, e.g. {"bigint": {"int8"}}, so that AutoMigrate takes
+	// columns of a type tag spelled differently as unchanged instead of altering
+	// them on every run. They replace the built-in aliases of the same type.
+	TypeAliases map[string][]string
+	// NumberedPlaceholders writes parameters as $1, $2, ... instead of ?, so
+	// that raw SQL can refer to a parameter more than once. Plugins rewriting
+	// statements that expect ? placeholders do not support them.
+	NumberedPlaceholders bool
+	// ReadConns is the size of a pool of connections to the same database that
+	// SELECT statements and read-only transactions are sent to, while other
+	// statements run one at a time on a single read-write connection. This
+	// keeps reads concurrent in mixed workloads without writes contending.
+	ReadConns int
+	// UseAppender creates slices of records, e.g. with CreateInBatches, through
+	// the appender instead of INSERT statements, see WithAppender
+	UseAppender bool
+	// PreferTimestampTZ stores time fields in TIMESTAMPTZ columns instead of
+	// TIMESTAMP, keeping the instant of times in any zone; fields tagged
+	// type:timestamptz get such a column regardless
+	PreferTimestampTZ bool
+	// TimeZone is the zone TIMESTAMPTZ values are displayed and truncated in,
+	// e.g. Asia/Tokyo, set on every connection as DuckDB keeps it per
+	// connection. It requires the icu extension.
+	TimeZone string
+	// BootQueries run on every connection opened, after the settings, e.g.
+	// PRAGMA and SET statements of settings kept per connection. Statements
+	// changing the database, such as ATTACH or CREATE SECRET, must tolerate
+	// running again, e.g. with IF NOT EXISTS or OR REPLACE.
+	BootQueries []string
+	// ConnInit is called with every connection opened, after the boot queries,
+	// for initialization that takes more than statements. It gets the
+	// connection of the driver, e.g. a *duckdb.Conn of go-duckdb. It does not
+	// apply to Config.Conn.
+	ConnInit func(ctx context.Context, conn driver.Conn) error
 }
 
 func Open(dsn string) gorm.Dialector {
@@ -31,37 +169,342 @@ func New(config Config) gorm.Dialector {
 	return &Dialector{Config: &config}
 }
 
+// Close checkpoints the database and closes its connection pool, so that the
+// next start does not have to replay the write-ahead log. Pending operations
+// finish before the checkpoint runs.
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	// stop keeping idle connections and queue new work behind the checkpoint
+	sqlDB.SetMaxIdleConns(0)
+	sqlDB.SetMaxOpenConns(1)
+
+	_, err = sqlDB.Exec("CHECKPOINT")
+	return errors.Join(err, sqlDB.Close())
+}
+
+// dialectorOf returns the DuckDB dialector of db
+func dialectorOf(db *gorm.DB) (Dialector, bool) {
+	switch dialector := db.Dialector.(type) {
+	case *Dialector:
+		return *dialector, true
+	case Dialector:
+		return dialector, true
+	}
+	return Dialector{}, false
+}
+
 func (dialector Dialector) Name() string {
 	return "duckdb"
 }
 
+// callbacksConfig are the clauses of gorm's statements
+var callbacksConfig = &callbacks.Config{
+	CreateClauses: []string{"INSERT", "VALUES", "ON CONFLICT", "RETURNING"},
+	UpdateClauses: []string{"UPDATE", "SET", "FROM", "WHERE", "RETURNING"},
+	DeleteClauses: []string{"DELETE", "FROM", "WHERE", "RETURNING"},
+}
+
+// createCallback is gorm's create, which Dialector.create falls back to
+var createCallback = callbacks.Create(callbacksConfig)
+
 func (dialector Dialector) Initialize(db *gorm.DB) (err error) {
-	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{
-		CreateClauses: []string{"INSERT", "VALUES"},
-		UpdateClauses: []string{"UPDATE", "SET", "WHERE"},
-		DeleteClauses: []string{"DELETE", "FROM", "WHERE"},
-	})
+	callbacks.RegisterDefaultCallbacks(db, callbacksConfig)
+	if err = dialector.registerCallbacks(db); err != nil {
+		return err
+	}
+	db.ClauseBuilders["FOR"] = dialector.buildLocking
+	db.ClauseBuilders["ON CONFLICT"] = dialector.buildOnConflict
+	db.ClauseBuilders["VALUES"] = dialector.buildValues
+	db.ClauseBuilders["SET"] = dialector.buildSet
+	for name, builder := range dialector.ClauseBuilders {
+		db.ClauseBuilders[name] = builder
+	}
+
+	for name := range dialector.Settings {
+		if warning := deprecationWarning(name); warning != "" {
+			db.Logger.Warn(context.Background(), "duckdb: %s", warning)
+		}
+	}
 
 	if dialector.Conn != nil {
 		db.ConnPool = dialector.Conn
 	} else {
-		db.ConnPool, err = sql.Open("duckdb", dialector.Config.DSN)
+		var dsn *DSN
+		if dsn, err = ParseDSN(dialector.Config.DSN); err != nil {
+			return err
+		}
+		// DSN option warnings, logged once when the database is opened
+		for _, warning := range dsn.Warnings() {
+			db.Logger.Warn(context.Background(), "duckdb: %s", warning)
+		}
+
+		if dialector.CreateDirs && isFilePath(dsn.Path) {
+			mode := dialector.DirMode
+			if mode == 0 {
+				mode = 0o755
+			}
+			if err = os.MkdirAll(filepath.Dir(dsn.Path), mode); err != nil {
+				return fmt.Errorf("failed to create database directory: %w", err)
+			}
+		}
+		// allow_unsigned_extensions can only be set at startup
+		if dialector.AllowUnsignedExtensions {
+			dsn.Options.Set("allow_unsigned_extensions", "true")
+		}
+		// as can the access mode
+		if dialector.AccessMode != "" {
+			if err = dsnOptions["access_mode"](string(dialector.AccessMode)); err != nil {
+				return fmt.Errorf("invalid access mode: %w", err)
+			}
+			for key := range dsn.Options {
+				if strings.EqualFold(key, "access_mode") {
+					dsn.Options.Del(key)
+				}
+			}
+			dsn.Options.Set("access_mode", string(dialector.AccessMode))
+		}
+		session := dsn.TakeLocalOptions()
+		for name, value := range dialector.sessionSettings() {
+			session[name] = value
+		}
+		sharedMemory := dialector.SharedMemory
+		if name := strings.TrimPrefix(dsn.Path, ":memory:"); name != dsn.Path && name != "" {
+			sharedMemory = name
+		} else if dsn.Path == ":memory:" {
+			// go-duckdb cannot parse options after :memory:, the database of
+			// the empty path too
+			dsn.Path = ""
+		}
+
+		var base driver.Connector
+		if sharedMemory != "" {
+			dsn.Path = ""
+			base, err = openSharedConnector(sharedMemory, func() (driver.Connector, error) {
+				return dialector.openConnector(dsn.String())
+			})
+		} else {
+			base, err = dialector.openConnector(dsn.String())
+		}
 		if err != nil {
 			return err
 		}
+		// extensions are loaded before the pool opens connections, so that the
+		// boot queries can use them
+		if len(dialector.Extensions) > 0 {
+			if err = dialector.bootstrap(base); err != nil {
+				return err
+			}
+		}
+		c := &connector{Connector: base}
+		if c.session, err = settingStatements(session); err != nil {
+			return err
+		}
+		c.session = append(c.session, dialector.BootQueries...)
+		c.init = dialector.ConnInit
+		pool := &connPool{DB: sql.OpenDB(c), connector: c}
+		if dialector.ReadConns > 0 {
+			pool.openReaders(dialector.ReadConns)
+		}
+		db.ConnPool = pool
+	}
+
+	if dialector.Version == "" {
+		if dialector.Version, err = detectVersion(db.ConnPool); err != nil {
+			return err
+		}
+	}
+
+	if err = applySettings(db.ConnPool, dialector.settings()); err != nil {
+		return err
+	}
+	// connections of pools given by Config.Conn are not opened by the dialector
+	if dialector.Conn != nil {
+		if err = loadExtensions(db.ConnPool, dialector.Extensions); err != nil {
+			return err
+		}
+		if err = applySettings(db.ConnPool, dialector.sessionSettings()); err != nil {
+			return err
+		}
+		for _, query := range dialector.BootQueries {
+			if _, err = db.ConnPool.ExecContext(context.Background(), query); err != nil {
+				return err
+			}
+		}
+	}
+
+	return dialector.applySecuritySettings(db.ConnPool)
+}
+
+// bootstrap applies the settings and loads the extensions on a connection of
+// base, which does not run the statements of new connections
+func (dialector Dialector) bootstrap(base driver.Connector) error {
+	db := sql.OpenDB(borrowedConnector{base})
+	defer db.Close()
+	if err := applySettings(db, dialector.settings()); err != nil {
+		return err
+	}
+	return loadExtensions(db, dialector.Extensions)
+}
+
+// loadExtensions installs the extensions that are not installed yet and loads
+// those that are not loaded
+func loadExtensions(conn gorm.ConnPool, extensions []string) error {
+	for _, name := range extensions {
+		var installed, loaded bool
+		err := conn.QueryRowContext(context.Background(),
+			"SELECT installed, loaded FROM duckdb_extensions() WHERE extension_name = ?", name,
+		).Scan(&installed, &loaded)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to look up extension %s: %w", name, err)
+		}
+		if !installed {
+			if _, err := conn.ExecContext(context.Background(), "INSTALL "+quoteString(name)); err != nil {
+				return fmt.Errorf("failed to install extension %s: %w", name, err)
+			}
+		}
+		if !loaded {
+			if _, err := conn.ExecContext(context.Background(), "LOAD "+quoteString(name)); err != nil {
+				return fmt.Errorf("failed to load extension %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// settings returns Config.Settings merged with the settings of dedicated Config
+// fields. DuckDB applies them to the whole database, so they are set once rather
+// than on every connection.
+func (dialector Dialector) settings() map[string]string {
+	settings := make(map[string]string, len(dialector.Settings)+2)
+	for name, value := range dialector.Settings {
+		settings[name] = value
+	}
+	if dialector.MemoryLimit != "" {
+		settings["memory_limit"] = dialector.MemoryLimit
+	}
+	if dialector.Threads > 0 {
+		settings["threads"] = strconv.Itoa(dialector.Threads)
+	}
+	if dialector.TempDirectory != "" {
+		settings["temp_directory"] = dialector.TempDirectory
+	}
+	if dialector.MaxTempDirectorySize != "" {
+		settings["max_temp_directory_size"] = dialector.MaxTempDirectorySize
+	}
+	if dialector.DisableInsertionOrder {
+		settings["preserve_insertion_order"] = "false"
+	}
+	return settings
+}
+
+// sessionSettings returns the settings of Config fields that DuckDB keeps per
+// connection, applied to every connection opened
+func (dialector Dialector) sessionSettings() map[string]string {
+	settings := map[string]string{}
+	if dialector.TimeZone != "" {
+		settings["timezone"] = dialector.TimeZone
+	}
+	return settings
+}
+
+// applySecuritySettings must run last, as the settings it applies prevent
+// changing others or loading extensions
+func (dialector Dialector) applySecuritySettings(conn gorm.ConnPool) error {
+	if dialector.DisableExternalAccess {
+		if err := applySettings(conn, map[string]string{"enable_external_access": "false"}); err != nil {
+			return err
+		}
 	}
+	if dialector.LockConfiguration {
+		if err := applySettings(conn, map[string]string{"lock_configuration": "true"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isFilePath reports whether path refers to a local database file rather than
+// an in-memory database or a remote one such as md: or s3://
+func isFilePath(path string) bool {
+	switch {
+	case path == "", strings.HasPrefix(path, ":memory:"):
+		return false
+	case filepath.IsAbs(path):
+		return true
+	}
+	return !strings.Contains(path, ":")
+}
 
+var settingNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func applySettings(conn gorm.ConnPool, settings map[string]string) error {
+	names, err := settingNames(settings)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := conn.ExecContext(context.Background(), setStatement(name, settings[name])); err != nil {
+			return fmt.Errorf("failed to set %s: %w", name, err)
+		}
+	}
 	return nil
 }
 
+// settingStatements returns the SET statements applying settings, ordered by
+// name
+func settingStatements(settings map[string]string) ([]string, error) {
+	names, err := settingNames(settings)
+	statements := make([]string, len(names))
+	for i, name := range names {
+		statements[i] = setStatement(name, settings[name])
+	}
+	return statements, err
+}
+
+// settingNames returns the names of settings in order, checking that they are
+// valid names
+func settingNames(settings map[string]string) ([]string, error) {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		if !settingNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid setting name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func setStatement(name, value string) string {
+	return "SET " + name + " = " + quoteString(value)
+}
+
+// quoteString renders s as a SQL string literal
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Apply sets NamingStrategy as the naming strategy of config unless it has one,
+// bounding names to Config.IdentifierMaxLength
 func (dialector Dialector) Apply(config *gorm.Config) error {
 	if config.NamingStrategy == nil {
-		config.NamingStrategy = schema.NamingStrategy{}
+		strategy := NamingStrategy{}
+		if dialector.Config != nil {
+			strategy.IdentifierMaxLength = dialector.IdentifierMaxLength
+		}
+		config.NamingStrategy = strategy
 	}
 	return nil
 }
 
 func (dialector Dialector) Migrator(db *gorm.DB) gorm.Migrator {
+	if db.Statement.Context.Value(migratorContextKey{}) == nil {
+		db = db.WithContext(context.WithValue(db.Statement.Context, migratorContextKey{}, true))
+	}
 	return Migrator{migrator.Migrator{Config: migrator.Config{
 		DB:                          db,
 		Dialector:                   dialector,
@@ -73,33 +516,114 @@ func (dialector Dialector) DefaultValueOf(field *schema.Field) clause.Expression
 	return clause.Expr{SQL: "DEFAULT"}
 }
 
+// numberedPlaceholder matches the numbered parameters written by BindVarTo
+// with Config.NumberedPlaceholders
+var numberedPlaceholder = regexp.MustCompile(`\$(\d+)`)
+
+// BindVarTo writes the parameter of v, ? or its number with
+// Config.NumberedPlaceholders
 func (dialector Dialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	if dialector.numberedPlaceholders() {
+		writer.WriteByte('$')
+		writer.WriteString(strconv.Itoa(len(stmt.Vars)))
+		return
+	}
 	writer.WriteByte('?')
 }
 
-func (dialector Dialector) QuoteTo(writer clause.Writer, str string) {
-	writer.WriteByte('"')
-	writer.WriteString(str)
-	writer.WriteByte('"')
+func (dialector Dialector) numberedPlaceholders() bool {
+	return dialector.Config != nil && dialector.NumberedPlaceholders
 }
 
-var numericPlaceholder = regexp.MustCompile(`\?`)
-
-func (dialector Dialector) Explain(sql string, vars ...interface{}) string {
-	return logger.ExplainSQL(sql, numericPlaceholder, `'`, vars...)
+// QuoteTo quotes each part of the dotted name str, e.g. a table qualified by
+// its schema or attached database, escaping the quotes within. Parts that are
+// quoted already, such as main."Order.Items", are kept whole.
+func (dialector Dialector) QuoteTo(writer clause.Writer, str string) {
+	for i, part := range splitIdentifier(str) {
+		if i > 0 {
+			writer.WriteByte('.')
+		}
+		writer.WriteString(quoteIdentifier(part))
+	}
 }
 
 func (dialector Dialector) DataTypeOf(field *schema.Field) string {
+	if dialector.Config != nil && dialector.TypeMapper != nil {
+		if dataType, ok := dialector.TypeMapper(field); ok {
+			return dataType
+		}
+	}
+
+	if isEmbeddedStruct(field) || isList(field) || isMap(field) ||
+		isArray(field) && field.DataType == schema.String {
+		if dataType, err := structColumnType(field.IndirectFieldType); err == nil {
+			return dataType
+		}
+	}
+	if field.DataType == schema.String && isBinarySerialized(field) {
+		return "BLOB"
+	}
+	if isJSONField(field) {
+		return "JSON"
+	}
+	if isUUIDField(field) {
+		return "UUID"
+	}
+	if isHugeInt(field) {
+		return "HUGEINT"
+	}
+	if isIntervalField(field) {
+		return "INTERVAL"
+	}
+	if isDateField(field) {
+		return "DATE"
+	}
+	if isTimeOfDayField(field) {
+		return "TIME"
+	}
+	if isBitField(field) {
+		return "BIT"
+	}
+
 	switch field.DataType {
 	case schema.Bool:
 		return "BOOLEAN"
-	case schema.Int, schema.Uint:
-		return "INTEGER"
+	case schema.Int:
+		// the type of the size of the field, so that the full range of Go's
+		// integer types fits
+		switch {
+		case field.Size == 0:
+		case field.Size <= 8:
+			return "TINYINT"
+		case field.Size <= 16:
+			return "SMALLINT"
+		case field.Size <= 32:
+			return "INTEGER"
+		}
+		return "BIGINT"
+	case schema.Uint:
+		// likewise the unsigned type of the size of the field
+		switch {
+		case field.Size == 0:
+		case field.Size <= 8:
+			return "UTINYINT"
+		case field.Size <= 16:
+			return "USMALLINT"
+		case field.Size <= 32:
+			return "UINTEGER"
+		}
+		return "UBIGINT"
 	case schema.Float:
+		if field.Precision > 0 {
+			return fmt.Sprintf("DECIMAL(%d,%d)", field.Precision, field.Scale)
+		}
 		return "DOUBLE"
 	case schema.String:
 		return "VARCHAR"
 	case schema.Time:
+		if dialector.Config != nil && dialector.PreferTimestampTZ {
+			return "TIMESTAMPTZ"
+		}
 		return "TIMESTAMP"
 	case schema.Bytes:
 		return "BLOB"
@@ -107,12 +631,23 @@ func (dialector Dialector) DataTypeOf(field *schema.Field) string {
 	return string(field.DataType)
 }
 
+// SavePoint starts a nested transaction of gorm's Transaction. DuckDB has no
+// savepoints, so the statements of nested transactions run in the enclosing
+// transaction, and only the name is checked.
 func (dialector Dialector) SavePoint(tx *gorm.DB, name string) error {
-	tx.Exec("SAVEPOINT " + name)
-	return nil
+	return checkSavePointName(name)
 }
 
+// RollbackTo rolls back a failed nested transaction. As DuckDB can't roll back
+// part of a transaction, the enclosing transaction is aborted instead: its
+// commit rolls it back, and both fail with ErrNestedRollback.
 func (dialector Dialector) RollbackTo(tx *gorm.DB, name string) error {
-	tx.Exec("ROLLBACK TO SAVEPOINT " + name)
-	return nil
+	if err := checkSavePointName(name); err != nil {
+		return err
+	}
+	err := fmt.Errorf("failed to roll back to savepoint %s: %w", name, ErrNestedRollback)
+	if pool, ok := tx.Statement.ConnPool.(*interruptibleTx); ok {
+		pool.abort(err)
+	}
+	return err
 }
@@ -0,0 +1,549 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func openTestDB(t *testing.T, config Config) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(New(config), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open database, got error %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestDialector_Initialize_security(t *testing.T) {
+	db := openTestDB(t, Config{
+		Settings:              map[string]string{"threads": "2"},
+		DisableExternalAccess: true,
+		LockConfiguration:     true,
+	})
+
+	var threads int
+	if err := db.Raw("SELECT current_setting('threads')").Scan(&threads).Error; err != nil || threads != 2 {
+		t.Errorf("expected settings to be applied before locking, got threads = %v, error %v", threads, err)
+	}
+	if err := db.Exec("SELECT * FROM read_csv('/etc/hosts')").Error; err == nil {
+		t.Errorf("expected reading files to fail with external access disabled")
+	}
+	if err := db.Exec("SET threads = 1").Error; err == nil {
+		t.Errorf("expected SET to fail with a locked configuration")
+	}
+}
+
+func TestClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "close.duckdb")
+	db, err := gorm.Open(Open(path), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open database, got error %v", err)
+	}
+	if err := db.Exec("CREATE TABLE close_test AS SELECT range AS id FROM range(1000)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	if err := Close(db); err != nil {
+		t.Fatalf("failed to close database, got error %v", err)
+	}
+	if info, err := os.Stat(path + ".wal"); err == nil && info.Size() > 0 {
+		t.Errorf("expected the WAL to be checkpointed, got %v bytes", info.Size())
+	}
+	if err := db.Exec("SELECT 1").Error; err == nil {
+		t.Errorf("expected queries to fail after Close")
+	}
+}
+
+func TestDialector_Initialize_createDirs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data", "analytics")
+	openTestDB(t, Config{DSN: filepath.Join(dir, "app.duckdb"), CreateDirs: true, DirMode: 0o700})
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("expected the directory to be created, got error %v", err)
+	}
+	if info.Mode().Perm() != 0o700 {
+		t.Errorf("expected directory mode 0700, got %v", info.Mode().Perm())
+	}
+}
+
+func TestDialector_Initialize_allowUnsignedExtensionsConn(t *testing.T) {
+	sqlDB, err := openTestDB(t, Config{}).DB()
+	if err != nil {
+		t.Fatalf("failed to get pool, got error %v", err)
+	}
+	// the running database cannot take the setting, which is left to its connector
+	db := openTestDB(t, Config{Conn: sqlDB, AllowUnsignedExtensions: true})
+	var allowed bool
+	if err := db.Raw("SELECT current_setting('allow_unsigned_extensions')").Scan(&allowed).Error; err != nil || allowed {
+		t.Errorf("expected the setting to be left alone, got %v, error %v", allowed, err)
+	}
+}
+
+func TestDialector_Initialize_accessMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.duckdb")
+	db := openTestDB(t, Config{DSN: path})
+	if err := db.Exec("CREATE TABLE shared_records AS SELECT range AS id FROM range(10)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	if err := Close(db); err != nil {
+		t.Fatalf("failed to close database, got error %v", err)
+	}
+
+	db = openTestDB(t, Config{DSN: path + "?access_mode=read_write", AccessMode: AccessModeReadOnly})
+	var count int64
+	if err := db.Raw("SELECT count(*) FROM shared_records").Scan(&count).Error; err != nil || count != 10 {
+		t.Errorf("expected 10 records, got %d, error %v", count, err)
+	}
+	if err := db.Exec("DELETE FROM shared_records").Error; err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("expected writes to fail in read-only mode, got error %v", err)
+	}
+
+	if _, err := gorm.Open(New(Config{DSN: path, AccessMode: "readonly"}), &gorm.Config{Logger: logger.Discard}); err == nil {
+		t.Errorf("expected an invalid access mode to fail")
+	}
+}
+
+func Test_isFilePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "", want: false},
+		{path: ":memory:", want: false},
+		{path: ":memory:shared", want: false},
+		{path: "md:analytics", want: false},
+		{path: "s3://bucket/app.duckdb", want: false},
+		{path: "data/app.duckdb", want: true},
+		{path: "/var/lib/app.duckdb", want: true},
+	}
+	for _, tt := range tests {
+		if got := isFilePath(tt.path); got != tt.want {
+			t.Errorf("isFilePath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// recordingLogger keeps the messages logged at Warn level
+type recordingLogger struct {
+	logger.Interface
+	mu    sync.Mutex
+	warns []string
+}
+
+func (l *recordingLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, fmt.Sprintf(msg, args...))
+}
+
+func (l *recordingLogger) warnings() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.warns, "\n")
+}
+
+func TestDialector_Initialize_warnings(t *testing.T) {
+	recorder := &recordingLogger{Interface: logger.Discard}
+	db, err := gorm.Open(New(Config{
+		DSN:      "?max_memory=1GB&checkpoint_threshold=16MB&custom_setting_x=1",
+		Settings: map[string]string{"worker_threads": "2"},
+	}), &gorm.Config{Logger: recorder})
+	if err == nil {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	}
+
+	warnings := recorder.warnings()
+	for _, want := range []string{
+		`option "max_memory" is deprecated, use "memory_limit" instead`,
+		`option "worker_threads" is deprecated, use "threads" instead`,
+		`option "custom_setting_x" is not known to the dialector`,
+	} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("expected warning %q, got %q", want, warnings)
+		}
+	}
+	if strings.Contains(warnings, "checkpoint_threshold") {
+		t.Errorf("expected no warning for known options, got %q", warnings)
+	}
+}
+
+func TestDialector_Initialize_tempDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "spill")
+	db := openTestDB(t, Config{TempDirectory: dir, MaxTempDirectorySize: "1GB"})
+
+	var tempDirectory, maxSize string
+	if err := db.Raw("SELECT current_setting('temp_directory'), current_setting('max_temp_directory_size')").Row().Scan(&tempDirectory, &maxSize); err != nil {
+		t.Fatalf("failed to read settings, got error %v", err)
+	}
+	if tempDirectory != dir {
+		t.Errorf("expected temp_directory %q, got %q", dir, tempDirectory)
+	}
+	if maxSize != "953.6 MiB" {
+		t.Errorf("expected max_temp_directory_size to be set, got %q", maxSize)
+	}
+}
+
+func TestDialector_Initialize_resources(t *testing.T) {
+	db := openTestDB(t, Config{
+		Settings:    map[string]string{"threads": "1"},
+		MemoryLimit: "512MB",
+		Threads:     3,
+	})
+
+	var memoryLimit string
+	var threads int64
+	if err := db.Raw("SELECT current_setting('memory_limit'), current_setting('threads')").Row().Scan(&memoryLimit, &threads); err != nil {
+		t.Fatalf("failed to read settings, got error %v", err)
+	}
+	if memoryLimit != "488.2 MiB" {
+		t.Errorf("expected memory_limit to be set, got %q", memoryLimit)
+	}
+	if threads != 3 {
+		t.Errorf("expected Threads to replace the threads setting, got %d", threads)
+	}
+}
+
+func TestDialector_Initialize_extensions(t *testing.T) {
+	db := openTestDB(t, Config{Extensions: []string{"json", "parquet"}, DisableExternalAccess: true})
+	for _, name := range []string{"json", "parquet"} {
+		if loaded, err := ExtensionLoaded(db, name); err != nil || !loaded {
+			t.Errorf("expected extension %s to be loaded, got %v, error %v", name, loaded, err)
+		}
+	}
+
+	_, err := gorm.Open(New(Config{Extensions: []string{"no_such_extension"}}), &gorm.Config{Logger: logger.Discard})
+	if err == nil || !strings.Contains(err.Error(), "extension no_such_extension") {
+		t.Errorf("expected an unknown extension to fail, got error %v", err)
+	}
+}
+
+func TestDialector_Initialize_bootQueries(t *testing.T) {
+	db := openTestDB(t, Config{
+		Extensions:  []string{"json"},
+		BootQueries: []string{"CREATE SCHEMA IF NOT EXISTS boot", "SET search_path = 'boot'", "CREATE OR REPLACE MACRO boot.twice(x) AS x * 2"},
+	})
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get pool, got error %v", err)
+	}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			t.Fatalf("failed to connect, got error %v", err)
+		}
+		defer conn.Close()
+		var searchPath string
+		var twice int64
+		if err := conn.QueryRowContext(ctx, "SELECT current_setting('search_path'), twice(21)").Scan(&searchPath, &twice); err != nil ||
+			searchPath != "boot" || twice != 42 {
+			t.Errorf("expected the boot queries to run on connection %d, got %q and %d, error %v", i, searchPath, twice, err)
+		}
+	}
+}
+
+type numberedEvent struct {
+	ID   uint
+	Kind string
+	Seen int
+}
+
+func TestDialector_QuoteTo(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "users", want: `"users"`},
+		{name: "main.users", want: `"main"."users"`},
+		{name: "analytics.main.users", want: `"analytics"."main"."users"`},
+		{name: `weird"name`, want: `"weird""name"`},
+		{name: `main."Order.Items"`, want: `"main"."Order.Items"`},
+		{name: `"say ""hi"""`, want: `"say ""hi"""`},
+	}
+	for _, tt := range tests {
+		var builder strings.Builder
+		New(Config{}).QuoteTo(&builder, tt.name)
+		if got := builder.String(); got != tt.want {
+			t.Errorf("QuoteTo(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	db := openTestDB(t, Config{})
+	if err := db.Exec(`CREATE TABLE "quoted""items" (id INTEGER)`).Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	if err := db.Table(`main.quoted"items`).Create(map[string]interface{}{"id": 1}).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	var count int64
+	if err := db.Table(`main.quoted"items`).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected 1 record, got %d, error %v", count, err)
+	}
+}
+
+func TestDialector_NumberedPlaceholders(t *testing.T) {
+	db := openTestDB(t, Config{NumberedPlaceholders: true})
+	if err := db.AutoMigrate(&numberedEvent{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	stmt := db.Session(&gorm.Session{DryRun: true}).Where("kind = ? AND seen > ?", "click", 1).Find(&[]numberedEvent{}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "kind = $1 AND seen > $2") {
+		t.Errorf("expected numbered parameters, got %s", sql)
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	tx := db.Session(&gorm.Session{Logger: recorder})
+	events := []numberedEvent{{Kind: "click", Seen: 2}, {Kind: "view", Seen: 1}}
+	if err := tx.Create(&events).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+	var found []numberedEvent
+	if err := tx.Where("kind = ? AND seen > ?", "click", 1).Find(&found).Error; err != nil || len(found) != 1 {
+		t.Errorf("expected 1 record, got %v, error %v", found, err)
+	}
+	if !recorder.contains("kind = 'click' AND seen > 1") {
+		t.Errorf("expected the logged SQL to interpolate the parameters, got %v", recorder.sql)
+	}
+
+	var count int64
+	if err := tx.Raw("SELECT count(*) FROM numbered_events WHERE kind = $1 OR kind = $1 || $2", "view", "s").Scan(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected a parameter used twice to match 1 record, got %d, error %v", count, err)
+	}
+}
+
+// shardingPlugin switches the connection of statements to a pool rewriting
+// their SQL, like the gorm.io/sharding plugin does
+type shardingPlugin struct {
+	pool *shardingConnPool
+}
+
+type shardingConnPool struct {
+	gorm.ConnPool
+	mu         sync.Mutex
+	statements []string
+}
+
+func (p *shardingConnPool) rewrite(query string, args []interface{}) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if strings.Count(query, "?") != len(args) {
+		p.statements = append(p.statements, "unexpected placeholders: "+query)
+	}
+	p.statements = append(p.statements, query)
+	return strings.ReplaceAll(query, `"sharded_events"`, `"sharded_events_1"`)
+}
+
+func (p *shardingConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.ConnPool.ExecContext(ctx, p.rewrite(query, args), args...)
+}
+
+func (p *shardingConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.ConnPool.QueryContext(ctx, p.rewrite(query, args), args...)
+}
+
+func (p *shardingConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.ConnPool.QueryRowContext(ctx, p.rewrite(query, args), args...)
+}
+
+func (*shardingPlugin) Name() string {
+	return "sharding"
+}
+
+func (s *shardingPlugin) Initialize(db *gorm.DB) error {
+	s.pool = &shardingConnPool{ConnPool: db.ConnPool}
+	switchConn := func(db *gorm.DB) {
+		db.Statement.ConnPool = s.pool
+	}
+	callbacks := db.Callback()
+	callbacks.Create().Before("*").Register("gorm:sharding", switchConn)
+	callbacks.Query().Before("*").Register("gorm:sharding", switchConn)
+	callbacks.Update().Before("*").Register("gorm:sharding", switchConn)
+	callbacks.Delete().Before("*").Register("gorm:sharding", switchConn)
+	callbacks.Row().Before("*").Register("gorm:sharding", switchConn)
+	return callbacks.Raw().Before("*").Register("gorm:sharding", switchConn)
+}
+
+type shardedEvent struct {
+	ID   uint
+	Kind string
+}
+
+func TestDialector_sharding(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Table("sharded_events_1").AutoMigrate(&shardedEvent{}); err != nil {
+		t.Fatalf("failed to create shard, got error %v", err)
+	}
+	sharding := &shardingPlugin{}
+	if err := db.Use(sharding); err != nil {
+		t.Fatalf("failed to use plugin, got error %v", err)
+	}
+
+	events := []shardedEvent{{Kind: "click"}, {Kind: "view"}, {Kind: "click"}}
+	if err := db.Create(&events).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	if events[0].ID != 1 || events[2].ID != 3 {
+		t.Errorf("expected generated keys to be returned, got %+v", events)
+	}
+	if err := db.Model(&shardedEvent{}).Where("kind = ?", "view").Update("kind", "open").Error; err != nil {
+		t.Errorf("failed to update, got error %v", err)
+	}
+	if err := db.Delete(&events[0]).Error; err != nil {
+		t.Errorf("failed to delete, got error %v", err)
+	}
+
+	var found []shardedEvent
+	if err := db.Where("kind IN ?", []string{"click", "open"}).Order("id").Find(&found).Error; err != nil ||
+		len(found) != 2 || found[0].Kind != "open" || found[1].ID != 3 {
+		t.Errorf("expected the remaining events, got %+v, error %v", found, err)
+	}
+
+	for _, statement := range sharding.pool.statements {
+		if strings.HasPrefix(statement, "unexpected") {
+			t.Errorf("%s", statement)
+		}
+	}
+	if len(sharding.pool.statements) == 0 || !strings.HasSuffix(sharding.pool.statements[0], `RETURNING "id"`) {
+		t.Errorf("expected inserts to return the keys, got %q", sharding.pool.statements)
+	}
+}
+
+type typeMappedRecord struct {
+	Name      string
+	Code      string `gorm:"type:VARCHAR(8)"`
+	Count     int
+	CreatedAt time.Time
+}
+
+func TestDialector_TypeMapper(t *testing.T) {
+	var tagged []string
+	db := openTestDB(t, Config{TypeMapper: func(field *schema.Field) (string, bool) {
+		switch field.DataType {
+		case schema.Time:
+			return "TIMESTAMPTZ", true
+		case schema.String:
+			return "VARCHAR(191)", true
+		case "VARCHAR(8)":
+			tagged = append(tagged, field.DBName)
+		}
+		return "", false
+	}})
+	if err := db.AutoMigrate(&typeMappedRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	var columns []struct {
+		ColumnName string
+		DataType   string
+	}
+	if err := db.Raw("SELECT column_name, data_type FROM duckdb_columns() WHERE table_name = 'type_mapped_records' ORDER BY column_index").Scan(&columns).Error; err != nil {
+		t.Fatalf("failed to load columns, got error %v", err)
+	}
+	want := map[string]string{"name": "VARCHAR", "code": "VARCHAR", "count": "BIGINT", "created_at": "TIMESTAMP WITH TIME ZONE"}
+	for _, column := range columns {
+		if want[column.ColumnName] != column.DataType {
+			t.Errorf("expected %s to be %s, got %s", column.ColumnName, want[column.ColumnName], column.DataType)
+		}
+	}
+	if len(tagged) == 0 || tagged[0] != "code" {
+		t.Errorf("expected fields with a type tag to be passed to TypeMapper, got %v", tagged)
+	}
+}
+
+type timestampTZRecord struct {
+	ID        uint
+	StartsAt  time.Time
+	Local     time.Time `gorm:"type:timestamp"`
+	CreatedAt time.Time
+}
+
+type timestampTZTagged struct {
+	ID       uint
+	StartsAt time.Time `gorm:"type:timestamptz"`
+}
+
+func TestDialector_PreferTimestampTZ(t *testing.T) {
+	db := openTestDB(t, Config{PreferTimestampTZ: true})
+	if err := db.AutoMigrate(&timestampTZRecord{}, &timestampTZTagged{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var types []string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name IN ('timestamp_tz_records', 'timestamp_tz_taggeds') AND column_name <> 'id' ORDER BY table_name, column_index").Scan(&types).Error; err != nil {
+		t.Fatalf("failed to load columns, got error %v", err)
+	}
+	want := []string{"TIMESTAMP WITH TIME ZONE", "TIMESTAMP", "TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITH TIME ZONE"}
+	if fmt.Sprint(types) != fmt.Sprint(want) {
+		t.Errorf("expected columns %v, got %v", want, types)
+	}
+
+	startsAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+	if err := db.Create(&timestampTZRecord{StartsAt: startsAt}).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	var record timestampTZRecord
+	if err := db.First(&record).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if !record.StartsAt.Equal(startsAt) {
+		t.Errorf("expected the instant %v to be kept, got %v", startsAt, record.StartsAt)
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&timestampTZRecord{}, &timestampTZTagged{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected TIMESTAMPTZ columns to be left as they are, got %v", recorder.sql)
+	}
+}
+
+func TestDialector_ClauseBuilders(t *testing.T) {
+	db := openTestDB(t, Config{ClauseBuilders: map[string]clause.ClauseBuilder{
+		"LIMIT": func(c clause.Clause, builder clause.Builder) {
+			if limit, ok := c.Expression.(clause.Limit); ok && limit.Limit != nil {
+				builder.WriteString("USING SAMPLE ")
+				builder.AddVar(builder, *limit.Limit)
+				builder.WriteString(" ROWS")
+				return
+			}
+			c.Builder = nil
+			c.Build(builder)
+		},
+		"ON CONFLICT": func(c clause.Clause, builder clause.Builder) {
+			builder.WriteString("ON CONFLICT DO NOTHING")
+		},
+	}})
+
+	stmt := db.Session(&gorm.Session{DryRun: true}).Table("events").Limit(10).Find(&[]map[string]interface{}{}).Statement
+	if sql := stmt.SQL.String(); sql != `SELECT * FROM "events" USING SAMPLE ? ROWS` {
+		t.Errorf("expected the registered LIMIT builder, got %v", sql)
+	}
+	stmt = db.Session(&gorm.Session{DryRun: true}).Clauses(clause.OnConflict{UpdateAll: true}).Create(&shardedEvent{Kind: "click"}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "ON CONFLICT DO NOTHING") {
+		t.Errorf("expected the registered builder to replace the dialector's, got %v", sql)
+	}
+	stmt = db.Session(&gorm.Session{DryRun: true}).Table("events").Find(&[]map[string]interface{}{}).Statement
+	if sql := stmt.SQL.String(); sql != `SELECT * FROM "events"` {
+		t.Errorf("expected other clauses to be unchanged, got %v", sql)
+	}
+}
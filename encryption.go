@@ -0,0 +1,169 @@
+package duckdb
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// EncryptedSerializerName is the serializer encrypting fields with the key of
+// Config.EncryptionKey or Config.EncryptionKeyFunc:
+//
+//	type User struct {
+//		ID    uint
+//		Email string `gorm:"serializer:encrypted"`
+//	}
+const EncryptedSerializerName = "encrypted"
+
+func init() {
+	schema.RegisterSerializer(EncryptedSerializerName, EncryptedSerializer{})
+}
+
+// encryptionKeyContextKey holds the key function of the dialector in statement
+// contexts
+type encryptionKeyContextKey struct{}
+
+// EncryptedSerializer encrypts fields with AES-GCM into BLOB columns and
+// decrypts them on scan. Strings and byte slices are encrypted as they are,
+// other values as JSON. The nonce is stored before the ciphertext.
+//
+// The registered encrypted serializer takes its key from the dialector; one
+// with its own key can be registered under another name:
+//
+//	schema.RegisterSerializer("pii", duckdb.EncryptedSerializer{Key: key})
+type EncryptedSerializer struct {
+	// Key is an AES-128, AES-192 or AES-256 key of 16, 24 or 32 bytes
+	Key []byte
+	// KeyFunc returns the key, e.g. from a KMS, and is used instead of Key
+	KeyFunc func(ctx context.Context) ([]byte, error)
+}
+
+func (s EncryptedSerializer) aead(ctx context.Context) (cipher.AEAD, error) {
+	var (
+		key []byte
+		err error
+	)
+	switch {
+	case s.KeyFunc != nil:
+		key, err = s.KeyFunc(ctx)
+	case s.Key != nil:
+		key = s.Key
+	default:
+		keyFunc, ok := ctx.Value(encryptionKeyContextKey{}).(func(context.Context) ([]byte, error))
+		if !ok {
+			return nil, errors.New("duckdb: no encryption key, set Config.EncryptionKey or Config.EncryptionKeyFunc")
+		}
+		key, err = keyFunc(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Scan decrypts dbValue into the field
+func (s EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) (err error) {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		var data []byte
+		switch v := dbValue.(type) {
+		case []byte:
+			data = v
+		case string:
+			data = []byte(v)
+		default:
+			return fmt.Errorf("failed to decrypt value: %#v", dbValue)
+		}
+
+		aead, err := s.aead(ctx)
+		if err != nil {
+			return err
+		}
+		if len(data) < aead.NonceSize() {
+			return errors.New("failed to decrypt value: too short")
+		}
+		plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(field.DBName))
+		if err != nil {
+			return fmt.Errorf("failed to decrypt value: %w", err)
+		}
+
+		switch target := fieldValue.Elem(); {
+		case target.Kind() == reflect.String:
+			target.SetString(string(plaintext))
+		case target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Uint8:
+			target.SetBytes(plaintext)
+		default:
+			if err := json.Unmarshal(plaintext, fieldValue.Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+// Value encrypts the field, leaving nil pointers NULL
+func (s EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(fieldValue)
+	if !rv.IsValid() || rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+
+	var plaintext []byte
+	switch {
+	case rv.Kind() == reflect.String:
+		plaintext = []byte(rv.String())
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		plaintext = rv.Bytes()
+	default:
+		data, err := json.Marshal(fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		plaintext = data
+	}
+
+	aead, err := s.aead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	// the column name is authenticated, so values cannot be swapped between columns
+	return aead.Seal(nonce, nonce, plaintext, []byte(field.DBName)), nil
+}
+
+// isEncrypted reports whether field uses an EncryptedSerializer
+func isEncrypted(field *schema.Field) bool {
+	_, ok := field.Serializer.(EncryptedSerializer)
+	return ok
+}
+
+// attachEncryptionKey makes the key of the dialector available to the encrypted
+// serializer through the statement context
+func (dialector Dialector) attachEncryptionKey(db *gorm.DB) {
+	keyFunc := dialector.EncryptionKeyFunc
+	if keyFunc == nil && dialector.EncryptionKey != nil {
+		key := dialector.EncryptionKey
+		keyFunc = func(context.Context) ([]byte, error) { return key, nil }
+	}
+	if keyFunc != nil {
+		db.Statement.Context = context.WithValue(db.Statement.Context, encryptionKeyContextKey{}, keyFunc)
+	}
+}
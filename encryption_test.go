@@ -0,0 +1,78 @@
+package duckdb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+type encryptedProfile struct {
+	Phone string `json:"phone"`
+}
+
+type encryptedRecord struct {
+	ID      int               `gorm:"primaryKey;autoIncrement:false"`
+	Email   string            `gorm:"serializer:encrypted"`
+	Profile *encryptedProfile `gorm:"serializer:encrypted"`
+	Token   []byte            `gorm:"serializer:encrypted_test"`
+}
+
+func TestEncryptedSerializer(t *testing.T) {
+	schema.RegisterSerializer("encrypted_test", EncryptedSerializer{Key: bytes.Repeat([]byte{7}, 16)})
+
+	key := bytes.Repeat([]byte{1}, 32)
+	db := openTestDB(t, Config{EncryptionKeyFunc: func(ctx context.Context) ([]byte, error) { return key, nil }})
+	if err := db.AutoMigrate(&encryptedRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	columnTypes, _ := db.Migrator().ColumnTypes(&encryptedRecord{})
+	for _, columnType := range columnTypes {
+		if columnType.Name() != "id" && columnType.DatabaseTypeName() != "BLOB" {
+			t.Errorf("expected column %s to be a BLOB, got %s", columnType.Name(), columnType.DatabaseTypeName())
+		}
+	}
+
+	records := []encryptedRecord{
+		{ID: 1, Email: "alice@example.com", Profile: &encryptedProfile{Phone: "555-0100"}, Token: []byte("secret")},
+		{ID: 2, Email: "bob@example.com"},
+	}
+	if err := db.Create(&records).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var stored []byte
+	if err := db.Raw("SELECT email FROM encrypted_records WHERE id = 1").Row().Scan(&stored); err != nil || bytes.Contains(stored, []byte("alice")) {
+		t.Errorf("expected the email to be stored encrypted, got %q, error %v", stored, err)
+	}
+
+	var found []encryptedRecord
+	if err := db.Order("id").Find(&found).Error; err != nil || len(found) != 2 {
+		t.Fatalf("failed to query, got %+v, error %v", found, err)
+	}
+	if found[0].Email != "alice@example.com" || found[0].Profile == nil || found[0].Profile.Phone != "555-0100" || string(found[0].Token) != "secret" {
+		t.Errorf("expected decrypted values, got %+v", found[0])
+	}
+	if found[1].Email != "bob@example.com" || found[1].Profile != nil {
+		t.Errorf("expected decrypted values with NULL for nil pointers, got %+v", found[1])
+	}
+
+	var scanned encryptedRecord
+	if err := db.Raw("SELECT * FROM encrypted_records WHERE id = ?", 2).Scan(&scanned).Error; err != nil || scanned.Email != "bob@example.com" {
+		t.Errorf("expected raw scans to decrypt, got %+v, error %v", scanned, err)
+	}
+
+	key = bytes.Repeat([]byte{2}, 32)
+	if err := db.First(&encryptedRecord{}, 1).Error; err == nil {
+		t.Errorf("expected decrypting with another key to fail")
+	}
+
+	other := openTestDB(t, Config{})
+	if err := other.AutoMigrate(&encryptedRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := other.Create(&encryptedRecord{ID: 1, Email: "x"}).Error; err == nil {
+		t.Errorf("expected an error without a key, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package duckdb
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var enumTypePattern = regexp.MustCompile(`(?is)^\s*ENUM\s*\((.*)\)\s*$`)
+
+// EnumValues returns the members of an ENUM column reported by ColumnTypes, whose
+// DatabaseTypeName is the name of the user-defined type, or ENUM for inline
+// types
+func EnumValues(columnType gorm.ColumnType) ([]string, bool) {
+	typ, ok := columnType.ColumnType()
+	if !ok {
+		return nil, false
+	}
+	return parseEnumType(typ)
+}
+
+// parseEnumType parses the members of a type such as ENUM('a', 'b')
+func parseEnumType(typ string) (values []string, ok bool) {
+	matches := enumTypePattern.FindStringSubmatch(typ)
+	if matches == nil {
+		return nil, false
+	}
+
+	list := strings.TrimSpace(matches[1])
+	for list != "" {
+		if list[0] != '\'' {
+			return nil, false
+		}
+		var value strings.Builder
+		i := 1
+		for ; i < len(list); i++ {
+			if list[i] == '\'' {
+				if i+1 < len(list) && list[i+1] == '\'' {
+					i++
+				} else {
+					break
+				}
+			}
+			value.WriteByte(list[i])
+		}
+		if i >= len(list) {
+			return nil, false
+		}
+		values = append(values, value.String())
+
+		list = strings.TrimSpace(list[i+1:])
+		if list != "" {
+			if list[0] != ',' {
+				return nil, false
+			}
+			list = strings.TrimSpace(list[1:])
+		}
+	}
+	return values, true
+}
+
+// enumTypes returns the members of the user-defined ENUM types by type name
+func (m Migrator) enumTypes() (map[string][]string, error) {
+	return m.enumTypesIn(clause.Expr{SQL: "CURRENT_DATABASE()"}, clause.Expr{SQL: "CURRENT_SCHEMA()"})
+}
+
+// enumTypesIn returns the members of the user-defined ENUM types of a schema
+// by type name
+func (m Migrator) enumTypesIn(catalog, currentSchema interface{}) (map[string][]string, error) {
+	rows, err := m.queryRaw(
+		"SELECT type_name, labels FROM duckdb_types() WHERE logical_type = 'ENUM' AND labels IS NOT NULL AND database_name = ? AND "+
+			identifierMatches("schema_name")+" ORDER BY type_name",
+		catalog, currentSchema,
+	).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := map[string][]string{}
+	for rows.Next() {
+		var (
+			name   string
+			labels interface{}
+		)
+		if err := rows.Scan(&name, &labels); err != nil {
+			return nil, err
+		}
+		types[name] = stringList(labels)
+	}
+	return types, rows.Err()
+}
+
+// enumTypeName returns the name of the user-defined ENUM type with values as
+// members, or ENUM when there is none
+func enumTypeName(types map[string][]string, values []string) string {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if slices.Equal(types[name], values) {
+			return name
+		}
+	}
+	return "ENUM"
+}
+
+// sameEnumType reports whether field and the existing ENUM column have the same
+// members, and whether both are ENUM types at all. DuckDB has no ALTER TYPE ...
+// ADD VALUE, so a column whose members differ is converted to the new type.
+func (m Migrator) sameEnumType(field *schema.Field, columnType gorm.ColumnType) (same bool, isEnum bool) {
+	current, ok := EnumValues(columnType)
+	if !ok {
+		return false, false
+	}
+
+	target := m.DataTypeOf(field)
+	if values, ok := parseEnumType(target); ok {
+		return slices.Equal(current, values), true
+	}
+	types, err := m.enumTypes()
+	if err != nil {
+		return false, false
+	}
+	for name, values := range types {
+		if strings.EqualFold(name, target) {
+			return slices.Equal(current, values), true
+		}
+	}
+	return false, false
+}
+
+// Fields whose type tag lists the members of an ENUM inline, e.g.
+// `gorm:"type:enum('new','done')"`, get a type of their own: the Migrator
+// creates <table>_<column>_enum before the column and gives the column that
+// type. DuckDB has no ALTER TYPE ... ADD VALUE and keeps tables depending on
+// the types their columns no longer have, so when the members change, the
+// column is converted to a new type numbered after the last one, e.g.
+// <table>_<column>_enum_2. The types are dropped with their tables.
+
+// enumTypeVersions returns the numbers of the ENUM types created for field among
+// types by type name, 1 for the first one, and the name of the first one
+func (m Migrator) enumTypeVersions(stmt *gorm.Statement, field *schema.Field, types map[string][]string) (string, map[string]int) {
+	_, _, table := m.qualifiedTable(stmt, stmt.Table)
+	base := table.(string) + "_" + field.DBName + "_enum"
+	pattern := regexp.MustCompile(`(?i)^` + regexp.QuoteMeta(base) + `(?:_(\d+))?$`)
+	versions := map[string]int{}
+	for name := range types {
+		if matches := pattern.FindStringSubmatch(name); matches != nil {
+			versions[name] = 1
+			if matches[1] != "" {
+				versions[name], _ = strconv.Atoi(matches[1])
+			}
+		}
+	}
+	return base, versions
+}
+
+// enumTypeOf returns the quoted name of the ENUM type of field with the members
+// of its type tag, creating it when there is none yet, or false for fields
+// whose type tag lists no members
+func (m Migrator) enumTypeOf(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field) (string, bool, error) {
+	dataType := m.DataTypeOf(field)
+	values, ok := parseEnumType(dataType)
+	if !ok {
+		return "", false, nil
+	}
+	catalog, currentSchema, _ := m.qualifiedTable(stmt, stmt.Table)
+	types, err := m.enumTypesIn(catalog, currentSchema)
+	if err != nil {
+		return "", true, err
+	}
+
+	base, versions := m.enumTypeVersions(stmt, field, types)
+	last := 0
+	for name, version := range versions {
+		if slices.Equal(types[name], values) {
+			return m.schemaObjectName(stmt, name), true, nil
+		}
+		last = max(last, version)
+	}
+	name := base
+	if last > 0 {
+		name += "_" + strconv.Itoa(last+1)
+	}
+	name = m.schemaObjectName(stmt, name)
+	return name, true, tx.Exec("CREATE TYPE " + name + " AS " + dataType).Error
+}
+
+// fullDataTypeOf returns the full data type of field like FullDataTypeOf, with
+// the ENUM type of field in place of the members of its type tag, and the
+// expression of generated fields in place of their constraints
+func (m Migrator) fullDataTypeOf(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field) (clause.Expr, error) {
+	if generated, ok := generatedExpression(field); ok {
+		return clause.Expr{SQL: m.DataTypeOf(field) + " GENERATED ALWAYS AS (" + generated + ")"}, nil
+	}
+	expr := m.DB.Migrator().FullDataTypeOf(field)
+	name, ok, err := m.enumTypeOf(tx, stmt, field)
+	if ok && err == nil {
+		expr.SQL = name + strings.TrimPrefix(expr.SQL, m.DataTypeOf(field))
+	}
+	return expr, err
+}
+
+// tableEnumTypes returns the quoted names of the ENUM types created for the
+// fields of the table of stmt
+func (m Migrator) tableEnumTypes(stmt *gorm.Statement) ([]string, error) {
+	if stmt.Schema == nil {
+		return nil, nil
+	}
+	catalog, currentSchema, _ := m.qualifiedTable(stmt, stmt.Table)
+	types, err := m.enumTypesIn(catalog, currentSchema)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, field := range stmt.Schema.Fields {
+		if _, ok := parseEnumType(m.DataTypeOf(field)); !ok || field.DBName == "" {
+			continue
+		}
+		_, versions := m.enumTypeVersions(stmt, field, types)
+		for name := range versions {
+			names = append(names, m.schemaObjectName(stmt, name))
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
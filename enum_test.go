@@ -0,0 +1,179 @@
+package duckdb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Test_parseEnumType(t *testing.T) {
+	tests := []struct {
+		name   string
+		typ    string
+		want   []string
+		wantOk bool
+	}{
+		{name: "it should parse members", typ: "ENUM('sad', 'ok')", want: []string{"sad", "ok"}, wantOk: true},
+		{name: "it should unescape quotes", typ: "enum('it''s','a,b')", want: []string{"it's", "a,b"}, wantOk: true},
+		{name: "it should reject other types", typ: "VARCHAR", wantOk: false},
+		{name: "it should reject malformed members", typ: "ENUM('a' 'b')", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseEnumType(tt.typ)
+			if ok != tt.wantOk || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseEnumType(%q) = %v, %v, want %v, %v", tt.typ, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+// sqlRecorder keeps the SQL traced by gorm
+type sqlRecorder struct {
+	logger.Interface
+	mu  sync.Mutex
+	sql []string
+}
+
+func (l *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sql = append(l.sql, sql)
+}
+
+func (l *sqlRecorder) contains(substr string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, sql := range l.sql {
+		if strings.Contains(sql, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+type enumRecord struct {
+	ID     int
+	Mood   string `gorm:"type:mood"`
+	Status string `gorm:"type:ENUM('new','done')"`
+}
+
+type enumRecordV2 struct {
+	ID     int
+	Mood   string `gorm:"type:mood"`
+	Status string `gorm:"type:ENUM('new','done','archived')"`
+}
+
+func (enumRecordV2) TableName() string { return "enum_records" }
+
+func TestMigrator_enumColumns(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE TYPE mood AS ENUM ('sad', 'ok')").Error; err != nil {
+		t.Fatalf("failed to create type, got error %v", err)
+	}
+	if err := db.AutoMigrate(&enumRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	columnTypes, err := db.Migrator().ColumnTypes(&enumRecord{})
+	if err != nil {
+		t.Fatalf("failed to get column types, got error %v", err)
+	}
+	for _, columnType := range columnTypes {
+		values, _ := EnumValues(columnType)
+		switch columnType.Name() {
+		case "mood":
+			if columnType.DatabaseTypeName() != "mood" || !reflect.DeepEqual(values, []string{"sad", "ok"}) {
+				t.Errorf("expected the mood type and its members, got %v %v", columnType.DatabaseTypeName(), values)
+			}
+		case "status":
+			if columnType.DatabaseTypeName() != "enum_records_status_enum" || !reflect.DeepEqual(values, []string{"new", "done"}) {
+				t.Errorf("expected the ENUM type of the field and its members, got %v %v", columnType.DatabaseTypeName(), values)
+			}
+		}
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	tx := db.Session(&gorm.Session{Logger: recorder})
+	if err := tx.AutoMigrate(&enumRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected unchanged ENUM columns to be left alone, got %v", recorder.sql)
+	}
+
+	if err := db.Exec("INSERT INTO enum_records VALUES (1, 'ok', 'done')").Error; err != nil {
+		t.Fatalf("failed to insert, got error %v", err)
+	}
+	if err := tx.AutoMigrate(&enumRecordV2{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Exec("INSERT INTO enum_records VALUES (2, 'sad', 'archived')").Error; err != nil {
+		t.Errorf("expected the new member to be accepted, got error %v", err)
+	}
+	var typeName string
+	db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'enum_records' AND column_name = 'status'").Scan(&typeName)
+	if typeName != "ENUM('new', 'done', 'archived')" {
+		t.Errorf("expected the column to have the new members, got %v", typeName)
+	}
+	if !recorder.contains(`CREATE TYPE "enum_records_status_enum_2" AS ENUM('new','done','archived')`) {
+		t.Errorf("expected a new ENUM type for the new members, got %v", recorder.sql)
+	}
+
+	if err := db.Migrator().DropTable(&enumRecordV2{}); err != nil {
+		t.Fatalf("failed to drop table, got error %v", err)
+	}
+	var types []string
+	db.Raw("SELECT type_name FROM duckdb_types() WHERE logical_type = 'ENUM' AND NOT internal ORDER BY type_name").Scan(&types)
+	if !reflect.DeepEqual(types, []string{"mood"}) {
+		t.Errorf("expected the ENUM types of the table to be dropped, got %v", types)
+	}
+}
+
+type enumTicket struct {
+	ID int
+}
+
+type enumTicketV2 struct {
+	ID       int
+	Priority string `gorm:"type:enum('low','high');default:'low'"`
+}
+
+func (enumTicketV2) TableName() string { return "enum_tickets" }
+
+func TestMigrator_addEnumColumn(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&enumTicket{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Create(&enumTicket{ID: 1}).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	if err := db.AutoMigrate(&enumTicketV2{}); err != nil {
+		t.Fatalf("failed to add column, got error %v", err)
+	}
+
+	var ticket enumTicketV2
+	if err := db.First(&ticket, 1).Error; err != nil || ticket.Priority != "low" {
+		t.Errorf("expected the default member, got %q, error %v", ticket.Priority, err)
+	}
+	if err := db.Create(&enumTicketV2{ID: 2, Priority: "urgent"}).Error; err == nil {
+		t.Errorf("expected values that are no member to be rejected")
+	}
+	columnTypes, err := db.Migrator().ColumnTypes(&enumTicketV2{})
+	if err != nil {
+		t.Fatalf("failed to get column types, got error %v", err)
+	}
+	for _, columnType := range columnTypes {
+		if columnType.Name() == "priority" && columnType.DatabaseTypeName() != "enum_tickets_priority_enum" {
+			t.Errorf("expected the ENUM type of the field, got %v", columnType.DatabaseTypeName())
+		}
+	}
+}
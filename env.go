@@ -0,0 +1,57 @@
+package duckdb
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// OpenFromEnv returns a dialector configured from the environment:
+//
+//	DUCKDB_PATH          database path or DSN, in-memory when empty
+//	DUCKDB_MEMORY_LIMIT  memory_limit option, e.g. 4GB
+//	DUCKDB_THREADS       threads option
+//	DUCKDB_S3_*          httpfs S3 settings, e.g. DUCKDB_S3_REGION sets s3_region
+//	MOTHERDUCK_TOKEN     motherduck_token option for `md:` paths
+func OpenFromEnv() gorm.Dialector {
+	config := configFromEnv(os.Environ())
+	return New(config)
+}
+
+func configFromEnv(environ []string) Config {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	path, rawQuery, _ := strings.Cut(env["DUCKDB_PATH"], "?")
+	options, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// leave the DSN untouched so that Initialize reports the parse error
+		return Config{DSN: env["DUCKDB_PATH"]}
+	}
+	if v := env["DUCKDB_MEMORY_LIMIT"]; v != "" {
+		options.Set("memory_limit", v)
+	}
+	if v := env["DUCKDB_THREADS"]; v != "" {
+		options.Set("threads", v)
+	}
+	if v := env["MOTHERDUCK_TOKEN"]; v != "" && strings.HasPrefix(path, "md:") {
+		options.Set("motherduck_token", v)
+	}
+
+	config := Config{DSN: (&DSN{Path: path, Options: options}).String()}
+	for k, v := range env {
+		if strings.HasPrefix(k, "DUCKDB_S3_") && v != "" {
+			if config.Settings == nil {
+				config.Settings = map[string]string{}
+			}
+			config.Settings[strings.ToLower(strings.TrimPrefix(k, "DUCKDB_"))] = v
+		}
+	}
+	return config
+}
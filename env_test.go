@@ -0,0 +1,50 @@
+package duckdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_configFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		environ []string
+		want    Config
+	}{
+		{
+			name:    "it should default to an in-memory database",
+			environ: nil,
+			want:    Config{DSN: ""},
+		},
+		{
+			name:    "it should merge memory limit and threads into the DSN",
+			environ: []string{"DUCKDB_PATH=data/app.duckdb?access_mode=read_only", "DUCKDB_MEMORY_LIMIT=2GB", "DUCKDB_THREADS=4"},
+			want:    Config{DSN: "data/app.duckdb?access_mode=read_only&memory_limit=2GB&threads=4"},
+		},
+		{
+			name:    "it should collect S3 settings",
+			environ: []string{"DUCKDB_PATH=app.duckdb", "DUCKDB_S3_REGION=us-east-1", "DUCKDB_S3_ACCESS_KEY_ID=key"},
+			want: Config{DSN: "app.duckdb", Settings: map[string]string{
+				"s3_region":        "us-east-1",
+				"s3_access_key_id": "key",
+			}},
+		},
+		{
+			name:    "it should pass the MotherDuck token for md: paths",
+			environ: []string{"DUCKDB_PATH=md:analytics", "MOTHERDUCK_TOKEN=secret"},
+			want:    Config{DSN: "md:analytics?motherduck_token=secret"},
+		},
+		{
+			name:    "it should ignore the MotherDuck token for local paths",
+			environ: []string{"DUCKDB_PATH=app.duckdb", "MOTHERDUCK_TOKEN=secret"},
+			want:    Config{DSN: "app.duckdb"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := configFromEnv(tt.environ); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("configFromEnv() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
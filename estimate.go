@@ -0,0 +1,84 @@
+package duckdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// explainNode is a node of the JSON output of EXPLAIN
+type explainNode struct {
+	Name      string         `json:"name"`
+	Children  []explainNode  `json:"children"`
+	ExtraInfo map[string]any `json:"extra_info"`
+}
+
+// EstimateRows returns the number of rows DuckDB's optimizer expects query, a
+// SQL string or a query built on a session, to return, without running it:
+//
+//	rows, err := duckdb.EstimateRows(db, db.Model(&Event{}).Where("kind = ?", "click"))
+//	if err == nil && rows > 1_000_000 {
+//		// stream with FindInBatches instead of loading all rows
+//	}
+//
+// The estimate comes from the planner's statistics and may be far off for
+// filters it cannot see through, e.g. on expressions or joins. The limits of
+// SQL strings are only applied for ordered queries.
+func EstimateRows(db *gorm.DB, query interface{}, values ...interface{}) (int64, error) {
+	var (
+		sql   string
+		limit int64 = -1
+	)
+	switch query := query.(type) {
+	case string:
+		sql = query
+	case *gorm.DB:
+		stmt := query.Session(&gorm.Session{DryRun: true}).Find(&[]map[string]interface{}{}).Statement
+		if stmt.Error != nil {
+			return 0, stmt.Error
+		}
+		sql, values = stmt.SQL.String(), stmt.Vars
+		if c, ok := stmt.Clauses["LIMIT"]; ok {
+			if l, ok := c.Expression.(clause.Limit); ok && l.Limit != nil {
+				limit = int64(*l.Limit)
+			}
+		}
+	default:
+		return 0, fmt.Errorf("unsupported query %T, expected a SQL string or *gorm.DB", query)
+	}
+
+	var plan struct {
+		Key   string `gorm:"column:explain_key"`
+		Value string `gorm:"column:explain_value"`
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Raw("EXPLAIN (FORMAT JSON) "+sql, values...).Scan(&plan).Error; err != nil {
+		return 0, err
+	}
+	var nodes []explainNode
+	if err := json.Unmarshal([]byte(plan.Value), &nodes); err != nil {
+		return 0, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	// operators like limits carry no estimate, so it is read from the first
+	// node below them, capped by the limits found on the way
+	for len(nodes) > 0 {
+		if top, ok := nodes[0].ExtraInfo["Top"].(string); ok {
+			if n, err := strconv.ParseInt(top, 10, 64); err == nil && (limit < 0 || n < limit) {
+				limit = n
+			}
+		}
+		if cardinality, ok := nodes[0].ExtraInfo["Estimated Cardinality"].(string); ok {
+			rows, err := strconv.ParseInt(cardinality, 10, 64)
+			if limit >= 0 && rows > limit {
+				rows = limit
+			}
+			return rows, err
+		}
+		nodes = nodes[0].Children
+	}
+	return 0, errors.New("plan has no estimated cardinality")
+}
@@ -0,0 +1,43 @@
+package duckdb
+
+import "testing"
+
+type estimateEvent struct {
+	ID   uint
+	Kind string
+}
+
+func TestEstimateRows(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&estimateEvent{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Exec("INSERT INTO estimate_events SELECT range, CASE WHEN range % 10 = 0 THEN 'click' ELSE 'view' END FROM range(1, 10001)").Error; err != nil {
+		t.Fatalf("failed to insert rows, got error %v", err)
+	}
+
+	rows, err := EstimateRows(db, "SELECT * FROM estimate_events")
+	if err != nil || rows != 10000 {
+		t.Errorf("expected 10000 estimated rows, got %d, error %v", rows, err)
+	}
+	rows, err = EstimateRows(db, "SELECT * FROM estimate_events WHERE id <= ?", 100)
+	if err != nil || rows <= 0 || rows >= 10000 {
+		t.Errorf("expected filtered estimate, got %d, error %v", rows, err)
+	}
+	rows, err = EstimateRows(db, db.Model(&estimateEvent{}).Where("kind = ?", "click").Limit(5))
+	if err != nil || rows <= 0 || rows > 5 {
+		t.Errorf("expected limited estimate, got %d, error %v", rows, err)
+	}
+
+	rows, err = EstimateRows(db, "SELECT * FROM estimate_events ORDER BY id LIMIT 3")
+	if err != nil || rows != 3 {
+		t.Errorf("expected ordered limit estimate, got %d, error %v", rows, err)
+	}
+
+	if _, err := EstimateRows(db, "SELECT * FROM missing_table"); err == nil {
+		t.Errorf("expected error for missing table")
+	}
+	if _, err := EstimateRows(db, 42); err == nil {
+		t.Errorf("expected error for unsupported query")
+	}
+}
@@ -0,0 +1,59 @@
+package duckdb
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// ExportFormat is a file format written by Export
+type ExportFormat string
+
+const (
+	ExportCSV     ExportFormat = "csv"
+	ExportJSON    ExportFormat = "json"
+	ExportParquet ExportFormat = "parquet"
+)
+
+// Export writes the rows of the query built on db to w in format and returns
+// the number of bytes written, e.g. for a download handler:
+//
+//	duckdb.Export(db.Model(&Order{}).Where("year = ?", 2024), w, duckdb.ExportParquet)
+//
+// DuckDB writes the result to a temporary file in Config.TempDirectory, or the
+// system default, which is then streamed to w, so the rows are never held in
+// memory.
+func Export(db *gorm.DB, w io.Writer, format ExportFormat) (int64, error) {
+	switch format {
+	case ExportCSV, ExportJSON, ExportParquet:
+	default:
+		return 0, errors.New("unsupported export format " + string(format))
+	}
+
+	stmt := db.Session(&gorm.Session{DryRun: true}).Find(&[]map[string]interface{}{}).Statement
+	if stmt.Error != nil {
+		return 0, stmt.Error
+	}
+
+	f, err := createTemp(db, "*."+string(format))
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+
+	sql := "COPY (" + stmt.SQL.String() + ") TO " + quoteString(f.Name()) + " (FORMAT " + string(format) + ")"
+	if err := db.Session(&gorm.Session{NewDB: true}).Exec(sql, stmt.Vars...).Error; err != nil {
+		return 0, err
+	}
+
+	if f, err = os.Open(f.Name()); err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return io.Copy(w, f)
+}
@@ -0,0 +1,42 @@
+package duckdb
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE TABLE export_records AS SELECT range AS id, 'n' || range AS name FROM range(5)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	tests := []struct {
+		format ExportFormat
+		want   string
+	}{
+		{format: ExportCSV, want: "id,name\n3,n3\n4,n4\n"},
+		{format: ExportJSON, want: "{\"id\":3,\"name\":\"n3\"}\n{\"id\":4,\"name\":\"n4\"}\n"},
+		{format: ExportParquet, want: "PAR1"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := Export(db.Table("export_records").Where("id > ?", 2).Order("id"), &buf, tt.format)
+			if err != nil || n != int64(buf.Len()) {
+				t.Fatalf("failed to export, got %v bytes, error %v", n, err)
+			}
+			if tt.format == ExportParquet {
+				if !bytes.HasPrefix(buf.Bytes(), []byte(tt.want)) {
+					t.Errorf("expected a parquet file, got %q", buf.Bytes()[:4])
+				}
+			} else if buf.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, buf.String())
+			}
+		})
+	}
+
+	if _, err := Export(db.Table("export_records"), &bytes.Buffer{}, "xlsx"); err == nil {
+		t.Errorf("expected an error for an unsupported format")
+	}
+}
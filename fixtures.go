@@ -0,0 +1,138 @@
+package duckdb
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// fixtureFormats are the extensions of fixture files and the functions reading
+// them, in the order they are looked up
+var fixtureFormats = []struct {
+	extension string
+	reader    string
+}{
+	{".csv", "read_csv(?, header = true)"},
+	{".csv.gz", "read_csv(?, header = true)"},
+	{".parquet", "read_parquet(?)"},
+}
+
+// LoadFixtures empties the tables of models and loads the rows of the files
+// named after them in dir, e.g. users.csv or orders.parquet, to set up
+// integration tests or seed a database:
+//
+//	if err := duckdb.LoadFixtures(db, "testdata/fixtures", &User{}, &Order{}); err != nil {
+//		t.Fatal(err)
+//	}
+//
+// Tables are emptied referencing ones first and loaded referenced ones first,
+// so that foreign keys hold. Columns are matched by name, so files may leave
+// out columns with defaults, and the sequences generating keys are moved past
+// the keys loaded. Tables without a file are left empty. As DuckDB rejects
+// deleting referenced rows in the transaction that deleted the rows
+// referencing them, each table is emptied and loaded in its own statement.
+func LoadFixtures(db *gorm.DB, dir string, models ...interface{}) error {
+	tx := db.Session(&gorm.Session{NewDB: true})
+	loaded := make(map[string]bool, len(models))
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		loaded[stmt.Table] = true
+	}
+	// models are only ordered when the models they depend on may be added,
+	// which are left out again
+	if reorderer, ok := tx.Migrator().(interface {
+		ReorderModels(values []interface{}, autoAdd bool) []interface{}
+	}); ok {
+		models = reorderer.ReorderModels(models, true)
+	}
+	var tables []string
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		if loaded[stmt.Table] {
+			tables = append(tables, stmt.Table)
+		}
+	}
+	for i := len(tables) - 1; i >= 0; i-- {
+		if err := tx.Exec("DELETE FROM " + quoteIdentifier(tables[i])).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, table := range tables {
+		path, reader, err := fixtureFile(dir, table)
+		if err != nil {
+			return err
+		} else if path == "" {
+			continue
+		}
+		if err := tx.Exec("INSERT INTO "+quoteIdentifier(table)+" BY NAME SELECT * FROM "+reader, path).Error; err != nil {
+			return err
+		}
+		if err := advanceSequences(tx, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fixtureFile returns the path of the fixture file of table in dir and the
+// function reading it, or an empty path without file
+func fixtureFile(dir, table string) (path, reader string, err error) {
+	for _, format := range fixtureFormats {
+		path = filepath.Join(dir, table+format.extension)
+		if _, err = os.Stat(path); err == nil {
+			return path, format.reader, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", "", err
+		}
+	}
+	return "", "", nil
+}
+
+// advanceSequences moves the sequences that generate the columns of table past
+// the largest value of the columns, so that the next rows created don't reuse
+// the keys loaded
+func advanceSequences(tx *gorm.DB, table string) error {
+	var columns []struct {
+		ColumnName    string
+		ColumnDefault string
+	}
+	if err := tx.Raw(
+		"SELECT column_name, column_default FROM duckdb_columns() WHERE database_name = current_database() AND schema_name = current_schema() AND table_name = ? AND column_default LIKE 'nextval(%'",
+		table,
+	).Scan(&columns).Error; err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		var last sql.NullInt64
+		if err := tx.Raw("SELECT max(" + quoteIdentifier(column.ColumnName) + ") FROM " + quoteIdentifier(table)).Scan(&last).Error; err != nil {
+			return err
+		} else if !last.Valid {
+			continue
+		}
+		// sequences can't be set, so values are drawn until past the last one
+		var next int64
+		if err := tx.Raw("SELECT " + column.ColumnDefault).Scan(&next).Error; err != nil {
+			return err
+		}
+		// the count is inlined, as DuckDB fails to commit a prepared statement
+		// drawing values
+		if next < last.Int64 {
+			if err := tx.Exec("SELECT max(" + column.ColumnDefault + ") FROM range(" + strconv.FormatInt(last.Int64-next, 10) + ")").Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package duckdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fixtureAuthor struct {
+	ID   uint
+	Name string
+}
+
+type fixtureBook struct {
+	Code     string `gorm:"primaryKey"`
+	Title    string
+	AuthorID uint
+	Author   fixtureAuthor
+}
+
+type fixtureReview struct {
+	Code  string `gorm:"primaryKey"`
+	Stars int
+}
+
+func TestLoadFixtures(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&fixtureAuthor{}, &fixtureBook{}, &fixtureReview{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Create(&fixtureBook{Code: "old", Author: fixtureAuthor{Name: "old"}}).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+	if err := db.Create(&fixtureReview{Code: "old"}).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fixture_authors.csv"), []byte("id,name\n1,Ann\n5,Bob\n"), 0o644); err != nil {
+		t.Fatalf("failed to write fixture, got error %v", err)
+	}
+	if err := db.Exec("COPY (SELECT 'b1' AS code, 'First' AS title, 5 AS author_id) TO " + quoteString(filepath.Join(dir, "fixture_books.parquet")) + " (FORMAT parquet)").Error; err != nil {
+		t.Fatalf("failed to write fixture, got error %v", err)
+	}
+
+	// books are passed before the authors they reference
+	if err := LoadFixtures(db, dir, &fixtureBook{}, &fixtureReview{}, &fixtureAuthor{}); err != nil {
+		t.Fatalf("failed to load fixtures, got error %v", err)
+	}
+
+	var authors []fixtureAuthor
+	if err := db.Order("id").Find(&authors).Error; err != nil || len(authors) != 2 || authors[1].Name != "Bob" {
+		t.Errorf("expected the authors of the fixture, got %+v, error %v", authors, err)
+	}
+	var books []fixtureBook
+	if err := db.Find(&books).Error; err != nil || len(books) != 1 || books[0].Title != "First" || books[0].AuthorID != 5 {
+		t.Errorf("expected the books of the fixture, got %+v, error %v", books, err)
+	}
+	var reviews int64
+	if err := db.Model(&fixtureReview{}).Count(&reviews).Error; err != nil || reviews != 0 {
+		t.Errorf("expected tables without fixture to be emptied, got %d, error %v", reviews, err)
+	}
+
+	author := fixtureAuthor{Name: "Cid"}
+	if err := db.Create(&author).Error; err != nil || author.ID <= 5 {
+		t.Errorf("expected new keys past the fixture, got %d, error %v", author.ID, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "fixture_reviews.csv"), []byte("code,unknown\nr1,1\n"), 0o644); err != nil {
+		t.Fatalf("failed to write fixture, got error %v", err)
+	}
+	if err := LoadFixtures(db, dir, &fixtureReview{}); err == nil {
+		t.Errorf("expected a fixture of unknown columns to fail")
+	}
+}
@@ -0,0 +1,123 @@
+package duckdb
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// foreignKeys returns the foreign key constraints of the table of stmt, as the
+// generic migrator creates them for belongs to relationships and the join
+// tables of many2many relationships, leaving out those DuckDB cannot create
+func (m Migrator) foreignKeys(stmt *gorm.Statement) (constraints []*schema.Constraint) {
+	if stmt.Schema == nil || m.DB.DisableForeignKeyConstraintWhenMigrating || m.DB.IgnoreRelationshipsWhenMigrating {
+		return nil
+	}
+	for _, rel := range stmt.Schema.Relationships.Relations {
+		if rel.Field.IgnoreMigration {
+			continue
+		}
+		if constraint := rel.ParseConstraint(); constraint != nil && constraint.Schema == stmt.Schema && m.supportsForeignKey(stmt, constraint) {
+			constraints = append(constraints, constraint)
+		}
+	}
+	return constraints
+}
+
+// supportsForeignKey reports whether DuckDB can create constraint, which it
+// cannot for referential actions and for references to columns without a
+// primary key or unique constraint, e.g. the foreignKey and references tags of
+// many2many relationships naming other columns
+func (m Migrator) supportsForeignKey(stmt *gorm.Statement, constraint *schema.Constraint) bool {
+	for _, action := range [][2]string{{"ON DELETE", constraint.OnDelete}, {"ON UPDATE", constraint.OnUpdate}} {
+		switch strings.ToUpper(strings.TrimSpace(action[1])) {
+		case "", "RESTRICT", "NO ACTION":
+		default:
+			m.DB.Logger.Warn(stmt.Context, "duckdb: dropped foreign key %s with unsupported %s %s", constraint.Name, action[0], action[1])
+			return false
+		}
+	}
+
+	if !sameFields(constraint.References, constraint.ReferenceSchema.PrimaryFields) &&
+		!(len(constraint.References) == 1 && constraint.References[0].Unique) {
+		m.DB.Logger.Warn(stmt.Context, "duckdb: dropped foreign key %s referencing columns of %s without a primary key or unique constraint",
+			constraint.Name, constraint.ReferenceSchema.Table)
+		return false
+	}
+	return true
+}
+
+// sameFields reports whether fields and other hold the same fields in any order
+func sameFields(fields, other []*schema.Field) bool {
+	if len(fields) != len(other) {
+		return false
+	}
+	for _, field := range fields {
+		found := false
+		for _, o := range other {
+			found = found || o == field
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// hasForeignKey reports whether table has the foreign key constraint, which DuckDB
+// names itself, e.g. m2m_user_languages_m2m_user_id_id_fkey, so it is matched
+// by its columns and the table it references
+func (m Migrator) hasForeignKey(stmt *gorm.Statement, table string, constraint *schema.Constraint) bool {
+	columns := make([]string, len(constraint.ForeignKeys))
+	for i, field := range constraint.ForeignKeys {
+		columns[i] = field.DBName
+	}
+	catalog, currentSchema, curTable := m.qualifiedTable(stmt, table)
+
+	var count int64
+	m.queryRaw(
+		"SELECT count(*) FROM duckdb_constraints() WHERE constraint_type = 'FOREIGN KEY' AND database_name = ? AND "+
+			identifierMatches("schema_name")+" AND "+identifierMatches("table_name")+" AND "+
+			identifierMatches("array_to_string(constraint_column_names, ',')")+" AND "+identifierMatches("referenced_table"),
+		catalog, currentSchema, curTable, strings.Join(columns, ","), constraint.ReferenceSchema.Table,
+	).Scan(&count)
+	return count > 0
+}
+
+// CreateConstraint skips the foreign keys DuckDB cannot create, which
+// CreateTable leaves out of the table
+func (m Migrator) CreateConstraint(value interface{}, name string) error {
+	supported := true
+	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		constraint, _ := m.GuessConstraintInterfaceAndTable(stmt, name)
+		if fk, ok := constraint.(*schema.Constraint); ok {
+			supported = m.supportsForeignKey(stmt, fk)
+		}
+		return nil
+	})
+	if !supported {
+		return nil
+	}
+	return m.Migrator.CreateConstraint(value, name)
+}
+
+// dropJoinTables drops the join tables of the many2many relationships of the
+// model of stmt, which DuckDB does not drop for DROP TABLE ... CASCADE and
+// which reference the model's table. Join tables that also join a table kept,
+// as dropped lists the tables dropped, are left for the caller to drop.
+func (m Migrator) dropJoinTables(tx *gorm.DB, stmt *gorm.Statement, dropped map[string]bool) error {
+	if stmt.Schema == nil {
+		return nil
+	}
+	for _, rel := range stmt.Schema.Relationships.Many2Many {
+		if related := rel.FieldSchema.Table; !dropped[related] && m.HasTable(related) {
+			continue
+		}
+		if err := tx.Exec("DROP TABLE IF EXISTS ?", clause.Table{Name: rel.JoinTable.Table}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package duckdb
+
+import (
+	"sort"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type m2mLanguage struct {
+	Code string `gorm:"primaryKey"`
+	Name string
+}
+
+type m2mUser struct {
+	ID        uint
+	Name      string
+	Languages []m2mLanguage `gorm:"many2many:m2m_user_languages"`
+	Friends   []*m2mUser    `gorm:"many2many:m2m_friendships"`
+}
+
+type m2mTag struct {
+	ID   uint
+	Slug string
+}
+
+type m2mPost struct {
+	ID     uint
+	Handle string
+	Tags   []m2mTag `gorm:"many2many:m2m_post_tags;foreignKey:Handle;joinForeignKey:PostHandle;references:Slug;joinReferences:TagSlug"`
+	Labels []m2mTag `gorm:"many2many:m2m_post_labels;constraint:OnDelete:CASCADE"`
+}
+
+func languageCodes(t *testing.T, db *gorm.DB, user *m2mUser) []string {
+	t.Helper()
+	var languages []m2mLanguage
+	if err := db.Model(user).Association("Languages").Find(&languages); err != nil {
+		t.Fatalf("failed to find languages, got error %v", err)
+	}
+	codes := make([]string, len(languages))
+	for i, language := range languages {
+		codes[i] = language.Code
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func TestMigrator_many2many(t *testing.T) {
+	db := openTestDB(t, Config{})
+	for i := 0; i < 2; i++ {
+		if err := db.AutoMigrate(&m2mUser{}, &m2mLanguage{}); err != nil {
+			t.Fatalf("failed to migrate (%d), got error %v", i, err)
+		}
+	}
+
+	var foreignKeys []string
+	if err := db.Raw("SELECT constraint_text FROM duckdb_constraints() WHERE table_name = 'm2m_user_languages' AND constraint_type IN ('PRIMARY KEY', 'FOREIGN KEY') ORDER BY constraint_index").
+		Scan(&foreignKeys).Error; err != nil {
+		t.Fatalf("failed to query constraints, got error %v", err)
+	}
+	expected := []string{
+		"PRIMARY KEY(m2m_user_id, m2m_language_code)",
+		"FOREIGN KEY (m2m_user_id) REFERENCES m2m_users(id)",
+		"FOREIGN KEY (m2m_language_code) REFERENCES m2m_languages(code)",
+	}
+	if len(foreignKeys) != len(expected) {
+		t.Fatalf("expected constraints %v, got %v", expected, foreignKeys)
+	}
+	for _, constraint := range expected {
+		found := false
+		for _, foreignKey := range foreignKeys {
+			found = found || foreignKey == constraint
+		}
+		if !found {
+			t.Errorf("expected constraint %s, got %v", constraint, foreignKeys)
+		}
+	}
+	if !db.Migrator().HasConstraint(&m2mUser{}, "Languages") || !db.Migrator().HasConstraint(&m2mUser{}, "Friends") {
+		t.Errorf("expected join table foreign keys to be found")
+	}
+
+	user := m2mUser{Name: "alice", Languages: []m2mLanguage{{Code: "en"}, {Code: "de"}}}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create record with associations, got error %v", err)
+	}
+	if codes := languageCodes(t, db, &user); len(codes) != 2 || codes[0] != "de" || codes[1] != "en" {
+		t.Errorf("expected created languages, got %v", codes)
+	}
+
+	if err := db.Model(&user).Association("Languages").Append(&m2mLanguage{Code: "fr"}); err != nil {
+		t.Fatalf("failed to append association, got error %v", err)
+	}
+	if codes := languageCodes(t, db, &user); len(codes) != 3 || codes[2] != "fr" {
+		t.Errorf("expected appended language, got %v", codes)
+	}
+	if err := db.Model(&user).Association("Languages").Replace(&m2mLanguage{Code: "en"}, &m2mLanguage{Code: "ja"}); err != nil {
+		t.Fatalf("failed to replace association, got error %v", err)
+	}
+	if codes := languageCodes(t, db, &user); len(codes) != 2 || codes[0] != "en" || codes[1] != "ja" {
+		t.Errorf("expected replaced languages, got %v", codes)
+	}
+
+	if err := db.Model(&user).Association("Friends").Append(&m2mUser{Name: "bob"}); err != nil {
+		t.Fatalf("failed to append self-referencing association, got error %v", err)
+	}
+	if count := db.Model(&user).Association("Friends").Count(); count != 1 {
+		t.Errorf("expected one friend, got %d", count)
+	}
+
+	if err := db.Exec("INSERT INTO m2m_user_languages VALUES (?, ?)", user.ID, "xx").Error; err == nil {
+		t.Errorf("expected foreign key to reject unknown language")
+	}
+	if err := db.Model(&user).Association("Languages").Clear(); err != nil {
+		t.Fatalf("failed to clear association, got error %v", err)
+	}
+	if err := db.Migrator().DropTable(&m2mUser{}, &m2mLanguage{}); err != nil {
+		t.Fatalf("failed to drop tables, got error %v", err)
+	}
+	if db.Migrator().HasTable("m2m_user_languages") || db.Migrator().HasTable(&m2mUser{}) {
+		t.Errorf("expected tables to be dropped")
+	}
+}
+
+func TestMigrator_DropTable_sharedJoinTable(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&m2mUser{}, &m2mLanguage{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	user := m2mUser{Name: "alice", Languages: []m2mLanguage{{Code: "en"}}}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create record with associations, got error %v", err)
+	}
+
+	// the join table of languages is kept with them, so the users are too
+	if err := db.Migrator().DropTable(&m2mUser{}); err == nil {
+		t.Errorf("expected the users referenced by a kept join table not to be dropped")
+	}
+	if !db.Migrator().HasTable("m2m_user_languages") || !db.Migrator().HasTable(&m2mLanguage{}) {
+		t.Errorf("expected the join table shared with languages to be kept")
+	}
+	var count int64
+	if err := db.Table("m2m_user_languages").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected the rows of the join table to be kept, got %d, error %v", count, err)
+	}
+
+	if err := db.Migrator().DropTable("m2m_user_languages"); err != nil {
+		t.Fatalf("failed to drop the join table, got error %v", err)
+	}
+	if err := db.Migrator().DropTable(&m2mUser{}); err != nil {
+		t.Fatalf("failed to drop users, got error %v", err)
+	}
+	if db.Migrator().HasTable(&m2mUser{}) || db.Migrator().HasTable("m2m_friendships") || !db.Migrator().HasTable(&m2mLanguage{}) {
+		t.Errorf("expected users and their own join tables only to be dropped")
+	}
+}
+
+func TestMigrator_many2manyUnsupportedForeignKeys(t *testing.T) {
+	db := openTestDB(t, Config{})
+	for i := 0; i < 2; i++ {
+		if err := db.AutoMigrate(&m2mPost{}, &m2mTag{}); err != nil {
+			t.Fatalf("failed to migrate (%d), got error %v", i, err)
+		}
+	}
+
+	var foreignKeys []string
+	if err := db.Raw("SELECT constraint_text FROM duckdb_constraints() WHERE table_name IN ('m2m_post_tags', 'm2m_post_labels') AND constraint_type = 'FOREIGN KEY'").
+		Scan(&foreignKeys).Error; err != nil {
+		t.Fatalf("failed to query constraints, got error %v", err)
+	}
+	if len(foreignKeys) != 0 {
+		t.Errorf("expected unsupported foreign keys to be left out, got %v", foreignKeys)
+	}
+
+	post := m2mPost{Handle: "hello", Tags: []m2mTag{{Slug: "go"}}, Labels: []m2mTag{{Slug: "news"}}}
+	if err := db.Create(&post).Error; err != nil {
+		t.Fatalf("failed to create record with associations, got error %v", err)
+	}
+	if err := db.Model(&post).Association("Tags").Append(&m2mTag{Slug: "duckdb"}); err != nil {
+		t.Fatalf("failed to append association, got error %v", err)
+	}
+	var handles []string
+	if err := db.Raw("SELECT post_handle FROM m2m_post_tags ORDER BY tag_slug").Scan(&handles).Error; err != nil || len(handles) != 2 || handles[0] != "hello" {
+		t.Errorf("expected join rows keyed by handle, got %v, error %v", handles, err)
+	}
+}
@@ -0,0 +1,31 @@
+package duckdb
+
+import (
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// Fields with the generated tag get generated columns, computed from the
+// other columns of their row when read rather than stored:
+//
+//	type OrderLine struct {
+//		ID       uint
+//		Price    float64
+//		Quantity int
+//		Total    float64 `gorm:"->;generated:price * quantity"`
+//	}
+//	// CREATE TABLE "order_lines" (..., "total" DOUBLE GENERATED ALWAYS AS (price * quantity), ...)
+//
+// Generated fields must be read-only, with the -> tag, as DuckDB rejects
+// writing generated columns. DuckDB only creates generated columns with their
+// table and cannot change them, so AddColumn fails for generated fields and
+// MigrateColumn leaves their columns as they are.
+
+// generatedExpression returns the expression of the generated tag of field, or
+// false for fields without one
+func generatedExpression(field *schema.Field) (string, bool) {
+	expr, ok := field.TagSettings["GENERATED"]
+	expr = strings.TrimSpace(expr)
+	return expr, ok && expr != ""
+}
@@ -0,0 +1,71 @@
+package duckdb
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type generatedLine struct {
+	ID       uint
+	Price    float64
+	Quantity int
+	Total    float64 `gorm:"->;generated:price * quantity"`
+	Label    string  `gorm:"->;generated:'#' || id::VARCHAR"`
+}
+
+func TestGeneratedColumns(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&generatedLine{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var sql string
+	if err := db.Raw("SELECT sql FROM duckdb_tables() WHERE table_name = 'generated_lines'").Scan(&sql).Error; err != nil {
+		t.Fatalf("failed to query table, got error %v", err)
+	}
+	if !strings.Contains(sql, "total DOUBLE GENERATED ALWAYS AS") {
+		t.Errorf("expected a generated total column, got %s", sql)
+	}
+
+	line := generatedLine{Price: 2.5, Quantity: 4}
+	if err := db.Create(&line).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	var found generatedLine
+	if err := db.First(&found, line.ID).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if found.Total != 10 || found.Label != "#1" {
+		t.Errorf("expected total 10 and label #1, got %+v", found)
+	}
+
+	if err := db.Model(&found).Update("quantity", 2).Error; err != nil {
+		t.Fatalf("failed to update record, got error %v", err)
+	}
+	if err := db.Save(&found).Error; err != nil {
+		t.Fatalf("failed to save record, got error %v", err)
+	}
+	if err := db.First(&found, line.ID).Error; err != nil || found.Total != 5 {
+		t.Errorf("expected total 5, got %+v, error %v", found, err)
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&generatedLine{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected generated columns to be left as they are, got %v", recorder.sql)
+	}
+}
+
+func TestMigrator_addGeneratedColumn(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE TABLE generated_lines (id UBIGINT PRIMARY KEY, price DOUBLE, quantity BIGINT, label VARCHAR)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	if err := db.Migrator().AddColumn(&generatedLine{}, "Total"); err == nil || !strings.Contains(err.Error(), "generated column") {
+		t.Errorf("expected adding a generated column to fail, got error %v", err)
+	}
+}
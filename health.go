@@ -0,0 +1,73 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Health is a snapshot of the state of a database, as returned by HealthCheck
+type Health struct {
+	// Version is the DuckDB library version, e.g. v1.1.3
+	Version string
+	// AccessMode is read_only or read_write
+	AccessMode string
+	// Path is the database file, empty for in-memory databases
+	Path string
+	// DatabaseSize is the size of the database file in bytes
+	DatabaseSize int64
+	// WALSize is the size of the write-ahead log in bytes
+	WALSize int64
+	// Latency is the round-trip time of a trivial query
+	Latency time.Duration
+}
+
+// HealthCheck pings the database and reports its state, e.g. for readiness
+// probes of services embedding DuckDB
+func HealthCheck(db *gorm.DB) (*Health, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if db.Statement != nil && db.Statement.Context != nil {
+		ctx = db.Statement.Context
+	}
+
+	health := &Health{}
+	start := time.Now()
+	if err := sqlDB.QueryRowContext(ctx, "SELECT library_version FROM pragma_version()").Scan(&health.Version); err != nil {
+		return nil, err
+	}
+	health.Latency = time.Since(start)
+
+	var (
+		path                   sql.NullString
+		readOnly               bool
+		blockSize, totalBlocks int64
+	)
+	if err := sqlDB.QueryRowContext(ctx,
+		"SELECT d.path, d.readonly, s.block_size, s.total_blocks FROM duckdb_databases() d "+
+			"JOIN pragma_database_size() s USING (database_name) WHERE d.database_name = current_database()",
+	).Scan(&path, &readOnly, &blockSize, &totalBlocks); err != nil {
+		return nil, err
+	}
+	health.AccessMode, health.Path, health.DatabaseSize = "read_write", path.String, blockSize*totalBlocks
+	if readOnly {
+		health.AccessMode = "read_only"
+	}
+
+	if health.Path != "" {
+		if info, err := os.Stat(health.Path + ".wal"); err == nil {
+			health.WALSize = info.Size()
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return health, nil
+}
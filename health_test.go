@@ -0,0 +1,40 @@
+package duckdb
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHealthCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health.duckdb")
+	db := openTestDB(t, Config{DSN: path})
+	if err := db.Exec("CREATE TABLE health_records AS SELECT range AS id FROM range(100000)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	health, err := HealthCheck(db)
+	if err != nil {
+		t.Fatalf("failed to check health, got error %v", err)
+	}
+	if !strings.HasPrefix(health.Version, "v") || health.AccessMode != "read_write" || health.Path != path || health.Latency <= 0 {
+		t.Errorf("unexpected health %+v", health)
+	}
+	if health.WALSize == 0 {
+		t.Errorf("expected a WAL size before checkpointing, got %+v", health)
+	}
+
+	if err := db.Exec("CHECKPOINT").Error; err != nil {
+		t.Fatalf("failed to checkpoint, got error %v", err)
+	}
+	if health, err = HealthCheck(db); err != nil || health.DatabaseSize == 0 || health.WALSize != 0 {
+		t.Errorf("expected the data to move from the WAL to the database file, got %+v, error %v", health, err)
+	}
+
+	t.Run("in-memory", func(t *testing.T) {
+		health, err := HealthCheck(openTestDB(t, Config{}))
+		if err != nil || health.Path != "" || health.WALSize != 0 {
+			t.Errorf("unexpected health %+v, error %v", health, err)
+		}
+	})
+}
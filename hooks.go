@@ -0,0 +1,86 @@
+package duckdb
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// MigrationHooks are called by the Migrator, e.g. during AutoMigrate, so that
+// applications can audit, veto or extend schema changes. An error returned by
+// a hook aborts the migration.
+type MigrationHooks struct {
+	// BeforeCreateTable and AfterCreateTable are called around the creation of
+	// the table of each model; tx can run additional statements such as grants
+	BeforeCreateTable func(tx *gorm.DB, stmt *gorm.Statement) error
+	AfterCreateTable  func(tx *gorm.DB, stmt *gorm.Statement) error
+	// BeforeAlterColumn and AfterAlterColumn are called around changes of the
+	// type, nullability or default of an existing column
+	BeforeAlterColumn func(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field) error
+	AfterAlterColumn  func(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field) error
+	// OnDDL is called with each statement the Migrator is about to execute, its
+	// variables inlined. Statements run on tx are not passed to OnDDL again.
+	OnDDL func(tx *gorm.DB, sql string) error
+	// Progress is called by AutoMigrate after migrating each model, e.g. to
+	// report the progress of large migrations
+	Progress func(progress MigrationProgress)
+}
+
+// MigrationProgress is the progress of AutoMigrate after migrating a model
+type MigrationProgress struct {
+	// Model is the name of the model and Table the name of its table
+	Model string
+	Table string
+	// Step is the number of models migrated so far, including this one, and
+	// Pending the number of models left, which includes dependencies added to
+	// the models passed to AutoMigrate
+	Step    int
+	Pending int
+	// Duration is the time taken to migrate this model
+	Duration time.Duration
+	// Error is the error that aborted the migration of this model
+	Error error
+}
+
+// migratorContextKey marks the statements executed by the Migrator
+type migratorContextKey struct{}
+
+// hooks returns the MigrationHooks of the dialector
+func (m Migrator) hooks() MigrationHooks {
+	if dialector, ok := m.Dialector.(Dialector); ok && dialector.Config != nil {
+		return dialector.MigrationHooks
+	}
+	return MigrationHooks{}
+}
+
+// onDDL passes the statements executed by the Migrator to MigrationHooks.OnDDL,
+// which vetoes a statement by returning an error
+func (dialector Dialector) onDDL(db *gorm.DB) {
+	if db.Error != nil || db.DryRun || !isMigratorStatement(db) {
+		return
+	}
+	if onDDL := dialector.MigrationHooks.OnDDL; onDDL != nil {
+		if err := onDDL(unmarkedDB(db), dialector.Explain(db.Statement.SQL.String(), db.Statement.Vars...)); err != nil {
+			db.AddError(err)
+			return
+		}
+	}
+	if dialector.AuditDDL {
+		db.InstanceSet(ddlStartKey, time.Now())
+	}
+}
+
+func isMigratorStatement(db *gorm.DB) bool {
+	return db.Statement.Context.Value(migratorContextKey{}) == true
+}
+
+// unmarkedDB returns a new session on the connection of db whose statements are
+// not treated as the Migrator's
+func unmarkedDB(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{
+		NewDB:   true,
+		Context: context.WithValue(db.Statement.Context, migratorContextKey{}, false),
+	})
+}
@@ -0,0 +1,128 @@
+package duckdb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type hookRecord struct {
+	Name  string
+	Score int32
+}
+
+type hookRecordV2 struct {
+	Name  string
+	Score float64
+}
+
+func (hookRecordV2) TableName() string {
+	return "hook_records"
+}
+
+func TestMigrationHooks(t *testing.T) {
+	var events, statements []string
+	db := openTestDB(t, Config{MigrationHooks: MigrationHooks{
+		BeforeCreateTable: func(tx *gorm.DB, stmt *gorm.Statement) error {
+			events = append(events, "before create "+stmt.Table)
+			return nil
+		},
+		AfterCreateTable: func(tx *gorm.DB, stmt *gorm.Statement) error {
+			events = append(events, "after create "+stmt.Table)
+			return tx.Exec("COMMENT ON TABLE ? IS 'managed'", clause.Table{Name: stmt.Table}).Error
+		},
+		AfterAlterColumn: func(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field) error {
+			events = append(events, "after alter "+stmt.Table+"."+field.DBName)
+			return nil
+		},
+		OnDDL: func(tx *gorm.DB, sql string) error {
+			statements = append(statements, sql)
+			if strings.Contains(sql, "vetoed") {
+				return errors.New("vetoed")
+			}
+			return nil
+		},
+	}})
+
+	if err := db.AutoMigrate(&hookRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Table("hook_records").AutoMigrate(&hookRecordV2{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	want := []string{"before create hook_records", "after create hook_records", "after alter hook_records.score"}
+	if strings.Join(events, ", ") != strings.Join(want, ", ") {
+		t.Errorf("expected events %v, got %v", want, events)
+	}
+	if len(statements) != 3 || !strings.HasPrefix(statements[0], `CREATE TABLE "hook_records"`) ||
+		statements[1] != `COMMENT ON TABLE "hook_records" IS 'managed'` ||
+		!strings.HasPrefix(statements[2], `ALTER TABLE "hook_records" ALTER COLUMN "score" TYPE DOUBLE`) {
+		t.Errorf("expected the CREATE, COMMENT and ALTER statements, got %q", statements)
+	}
+
+	var comment string
+	if err := db.Raw("SELECT comment FROM duckdb_tables() WHERE table_name = 'hook_records'").Scan(&comment).Error; err != nil || comment != "managed" {
+		t.Errorf("expected the table comment added by the hook, got %q, error %v", comment, err)
+	}
+
+	if err := db.Table("vetoed").AutoMigrate(&hookRecord{}); err == nil || err.Error() != "vetoed" {
+		t.Errorf("expected the statement to be vetoed, got error %v", err)
+	}
+	if db.Migrator().HasTable("vetoed") {
+		t.Errorf("expected the vetoed table not to be created")
+	}
+
+	if err := db.Exec("CREATE TABLE unrelated (id INTEGER)").Error; err != nil || len(statements) != 4 {
+		t.Errorf("expected statements outside the Migrator not to be passed to OnDDL, got %q, error %v", statements, err)
+	}
+}
+
+type progressCustomer struct {
+	Code string `gorm:"primaryKey"`
+}
+
+type progressOrder struct {
+	Number       int `gorm:"primaryKey"`
+	CustomerCode string
+	Customer     progressCustomer `gorm:"foreignKey:CustomerCode"`
+}
+
+type progressInvalid struct {
+	Name string `gorm:"type:NOT_A_TYPE"`
+}
+
+func TestMigrationHooks_progress(t *testing.T) {
+	var reports []MigrationProgress
+	db := openTestDB(t, Config{MigrationHooks: MigrationHooks{
+		Progress: func(progress MigrationProgress) {
+			reports = append(reports, progress)
+		},
+	}})
+
+	if err := db.AutoMigrate(&progressOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("expected a report per model and dependency, got %+v", reports)
+	}
+	if r := reports[0]; r.Model != "progressCustomer" || r.Table != "progress_customers" || r.Step != 1 || r.Pending != 1 || r.Duration <= 0 || r.Error != nil {
+		t.Errorf("expected the dependency to be migrated first, got %+v", r)
+	}
+	if r := reports[1]; r.Model != "progressOrder" || r.Table != "progress_orders" || r.Step != 2 || r.Pending != 0 || r.Error != nil {
+		t.Errorf("expected the model to be migrated last, got %+v", r)
+	}
+	if !db.Migrator().HasTable(&progressCustomer{}) || !db.Migrator().HasTable(&progressOrder{}) {
+		t.Errorf("expected tables to be created")
+	}
+
+	reports = nil
+	err := db.AutoMigrate(&progressInvalid{}, &progressCustomer{})
+	if err == nil || len(reports) != 1 || reports[0].Table != "progress_invalids" || !errors.Is(reports[0].Error, err) {
+		t.Errorf("expected the failing model to be reported and to abort the migration, got %+v, error %v", reports, err)
+	}
+}
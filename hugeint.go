@@ -0,0 +1,85 @@
+package duckdb
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// HugeIntSerializerName is the serializer storing big.Int fields in HUGEINT
+// columns, 128-bit integers that hold values beyond the range of int64:
+//
+//	type Account struct {
+//		ID      uint
+//		Balance *big.Int `gorm:"serializer:hugeint"`
+//	}
+//
+// Nil pointers are stored as NULL. Values beyond 128 bits fail to be written.
+const HugeIntSerializerName = "hugeint"
+
+func init() {
+	schema.RegisterSerializer(HugeIntSerializerName, HugeIntSerializer{})
+}
+
+// HugeIntSerializer converts big.Int and *big.Int fields to and from HUGEINT
+// columns; values are bound as decimal text and cast to the column's type
+type HugeIntSerializer struct{}
+
+// Scan assigns the HUGEINT value read from the database to the field
+func (HugeIntSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := field.ReflectValueOf(ctx, dst)
+	if dbValue == nil {
+		fieldValue.Set(reflect.Zero(field.FieldType))
+		return nil
+	}
+
+	n := new(big.Int)
+	switch value := dbValue.(type) {
+	case *big.Int:
+		n.Set(value)
+	case int64:
+		n.SetInt64(value)
+	case int32:
+		n.SetInt64(int64(value))
+	case []byte:
+		if _, ok := n.SetString(string(value), 10); !ok {
+			return fmt.Errorf("failed to scan %s: invalid integer %q", field.Name, value)
+		}
+	case string:
+		if _, ok := n.SetString(value, 10); !ok {
+			return fmt.Errorf("failed to scan %s: invalid integer %q", field.Name, value)
+		}
+	default:
+		return fmt.Errorf("failed to scan %s: unsupported value %T", field.Name, dbValue)
+	}
+
+	if field.FieldType.Kind() == reflect.Pointer {
+		fieldValue.Set(reflect.ValueOf(n))
+	} else {
+		fieldValue.Set(reflect.ValueOf(n).Elem())
+	}
+	return nil
+}
+
+// Value returns the field as decimal text, leaving nil pointers NULL
+func (HugeIntSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	switch value := fieldValue.(type) {
+	case *big.Int:
+		if value == nil {
+			return nil, nil
+		}
+		return value.String(), nil
+	case big.Int:
+		return value.String(), nil
+	}
+	return nil, fmt.Errorf("unsupported value %T of %s, expected a big.Int", fieldValue, field.Name)
+}
+
+// isHugeInt reports whether field is stored in a HUGEINT column
+func isHugeInt(field *schema.Field) bool {
+	_, ok := field.Serializer.(HugeIntSerializer)
+	return ok
+}
@@ -0,0 +1,44 @@
+package duckdb
+
+import (
+	"math/big"
+	"testing"
+)
+
+type hugeIntAccount struct {
+	Code    string   `gorm:"primaryKey"`
+	Balance *big.Int `gorm:"serializer:hugeint"`
+	Limit   big.Int  `gorm:"serializer:hugeint"`
+}
+
+func TestHugeIntSerializer(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&hugeIntAccount{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var dataType string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'huge_int_accounts' AND column_name = 'balance'").Scan(&dataType).Error; err != nil || dataType != "HUGEINT" {
+		t.Errorf("expected a HUGEINT column, got %q, error %v", dataType, err)
+	}
+
+	balance, _ := new(big.Int).SetString("-170141183460469231731687303715884105728", 10)
+	limit, _ := new(big.Int).SetString("98765432109876543210", 10)
+	accounts := []hugeIntAccount{{Code: "a", Balance: balance, Limit: *limit}, {Code: "b"}}
+	if err := db.Create(&accounts).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+
+	var found []hugeIntAccount
+	if err := db.Order("code").Find(&found).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if len(found) != 2 || found[0].Balance == nil || found[0].Balance.Cmp(balance) != 0 || found[0].Limit.Cmp(limit) != 0 ||
+		found[1].Balance != nil || found[1].Limit.Sign() != 0 {
+		t.Errorf("expected the integers to round-trip, got %+v", found)
+	}
+
+	var count int64
+	if err := db.Model(&hugeIntAccount{}).Where("\"limit\" > ?", big.NewInt(1<<62)).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected to compare with big.Int arguments, got %v, error %v", count, err)
+	}
+}
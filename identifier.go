@@ -0,0 +1,49 @@
+package duckdb
+
+import "strings"
+
+// DuckDB preserves the case of identifiers but resolves them case-insensitively,
+// whether they are quoted or not. The Migrator compares the names it looks up in
+// the catalog through identifierMatches, after removing quotes with
+// unquoteIdentifier, so that "Users", users and USERS are the same table.
+
+// quoteIdentifier quotes name as an identifier
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// unquoteIdentifier returns the name of a quoted identifier such as "Order",
+// and other names as they are
+func unquoteIdentifier(name string) string {
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		return strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	}
+	return name
+}
+
+// splitIdentifier splits a qualified name such as main."Order.Items" into its
+// unquoted parts, ignoring dots within quotes
+func splitIdentifier(name string) (parts []string) {
+	var (
+		start  int
+		quoted bool
+	)
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '"':
+			quoted = !quoted
+		case '.':
+			if !quoted {
+				parts = append(parts, unquoteIdentifier(name[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, unquoteIdentifier(name[start:]))
+}
+
+// identifierMatches returns the condition comparing the catalog column to the
+// identifier bound to it
+func identifierMatches(column string) string {
+	return "lower(" + column + ") = lower(?)"
+}
@@ -0,0 +1,89 @@
+package duckdb
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// CSVOptions configures ImportCSV
+type CSVOptions struct {
+	// NoHeader reads the first line as data, matching values to the table
+	// columns by position instead of by name
+	NoHeader bool
+	// Delimiter separates values, detected by DuckDB when empty
+	Delimiter string
+}
+
+// ImportCSV loads CSV data read from r into the table of model, e.g. an upload
+// in an HTTP handler, and returns the number of rows inserted. The data is
+// spooled to a temporary file in Config.TempDirectory, or the system default,
+// for DuckDB to read it, and header columns are matched to table columns by
+// name.
+func ImportCSV(db *gorm.DB, model interface{}, r io.Reader, options ...CSVOptions) (rowsAffected int64, err error) {
+	var opts CSVOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return 0, err
+	}
+	table := stmt.Table
+	if db.Statement != nil && db.Statement.Table != "" {
+		table = db.Statement.Table
+	}
+
+	path, err := spool(db, r, "*.csv")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(path)
+
+	sql, vars := "INSERT INTO ? BY NAME SELECT * FROM read_csv(?, header = true", []interface{}{gorm.Expr(stmt.Quote(table)), path}
+	if opts.NoHeader {
+		sql = "INSERT INTO ? SELECT * FROM read_csv(?, header = false"
+	}
+	if opts.Delimiter != "" {
+		sql += ", delim = ?"
+		vars = append(vars, opts.Delimiter)
+	}
+	result := db.Session(&gorm.Session{NewDB: true}).Exec(sql+")", vars...)
+	return result.RowsAffected, result.Error
+}
+
+// spool copies r to a new temporary file and returns its path
+func spool(db *gorm.DB, r io.Reader, pattern string) (string, error) {
+	f, err := createTemp(db, pattern)
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		err = errors.Join(err, f.Close())
+	} else {
+		err = f.Close()
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// createTemp creates a temporary file in Config.TempDirectory, or the system
+// default
+func createTemp(db *gorm.DB, pattern string) (*os.File, error) {
+	dir := ""
+	if dialector, ok := dialectorOf(db); ok {
+		dir = dialector.TempDirectory
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	return os.CreateTemp(dir, pattern)
+}
@@ -0,0 +1,49 @@
+package duckdb
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type importRecord struct {
+	ID   int
+	Name string
+}
+
+func TestImportCSV(t *testing.T) {
+	spoolDir := filepath.Join(t.TempDir(), "spool")
+	db := openTestDB(t, Config{TempDirectory: spoolDir})
+	if err := db.Exec("CREATE TABLE import_records (id INTEGER, name VARCHAR)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		options []CSVOptions
+		rows    int64
+	}{
+		{name: "it should match header columns by name", data: "name,id\na,1\nb,2\n", rows: 2},
+		{name: "it should match columns by position without header", data: "3;c\n", options: []CSVOptions{{NoHeader: true, Delimiter: ";"}}, rows: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ImportCSV(db, &importRecord{}, strings.NewReader(tt.data), tt.options...)
+			if err != nil || rows != tt.rows {
+				t.Fatalf("failed to import, got %v rows, error %v", rows, err)
+			}
+		})
+	}
+
+	var records []importRecord
+	if err := db.Order("id").Find(&records).Error; err != nil {
+		t.Fatalf("failed to read rows, got error %v", err)
+	}
+	if len(records) != 3 || records[0] != (importRecord{1, "a"}) || records[2] != (importRecord{3, "c"}) {
+		t.Errorf("expected imported records, got %+v", records)
+	}
+	if files, _ := filepath.Glob(filepath.Join(spoolDir, "*.csv")); len(files) != 0 {
+		t.Errorf("expected spooled files to be removed, got %v", files)
+	}
+}
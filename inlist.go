@@ -0,0 +1,196 @@
+package duckdb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	inListTablesKey = "duckdb:in_list_tables"
+	inListWhereKey  = "duckdb:in_list_where"
+	// inListBatchSize is the number of values inserted into a temporary table
+	// per statement
+	inListBatchSize = 1000
+)
+
+var inListSeq atomic.Uint64
+
+// inListTable matches a column against the values of a temporary table
+type inListTable struct {
+	Column interface{}
+	Table  string
+}
+
+func (in inListTable) Build(builder clause.Builder) {
+	builder.WriteQuoted(in.Column)
+	builder.WriteString(" IN (SELECT value FROM temp.")
+	builder.WriteQuoted(in.Table)
+	builder.WriteByte(')')
+}
+
+func (in inListTable) NegationBuild(builder clause.Builder) {
+	builder.WriteQuoted(in.Column)
+	builder.WriteString(" NOT IN (SELECT value FROM temp.")
+	builder.WriteQuoted(in.Table)
+	builder.WriteByte(')')
+}
+
+// loadInLists loads the values of IN conditions longer than
+// Config.InListThreshold into temporary tables and rewrites the conditions to
+// sub-queries on them, which DuckDB plans as a join instead of binding and
+// comparing every value. Conditions built with clause.IN, e.g. by Find and
+// Delete with primary keys or Where with a map, and slices bound in Where
+// strings such as "id IN ?" are rewritten. The statement is pinned to a
+// connection, as temporary tables are local to it.
+func (dialector Dialector) loadInLists(db *gorm.DB) {
+	if dialector.InListThreshold <= 0 || db.Error != nil || db.DryRun {
+		return
+	}
+	c, ok := db.Statement.Clauses["WHERE"]
+	if !ok {
+		return
+	}
+	where, ok := c.Expression.(clause.Where)
+	if !ok {
+		return
+	}
+
+	loader := &inListLoader{db: db, threshold: dialector.InListThreshold}
+	exprs := loader.rewrite(where.Exprs)
+	if db.InstanceSet(inListTablesKey, loader.tables); len(loader.tables) == 0 || db.Error != nil {
+		return
+	}
+	db.InstanceSet(inListWhereKey, c)
+	c.Expression = clause.Where{Exprs: exprs}
+	db.Statement.Clauses["WHERE"] = c
+}
+
+// dropInLists drops the temporary tables of loadInLists and restores the
+// conditions of the statement for its next use
+func dropInLists(db *gorm.DB) {
+	if c, ok := db.InstanceGet(inListWhereKey); ok {
+		db.Statement.Clauses["WHERE"] = c.(clause.Clause)
+	}
+	if tables, ok := db.InstanceGet(inListTablesKey); ok {
+		for _, table := range tables.([]string) {
+			if _, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, "DROP TABLE IF EXISTS temp."+quoteIdentifier(table)); err != nil {
+				db.AddError(err)
+			}
+		}
+	}
+}
+
+type inListLoader struct {
+	db        *gorm.DB
+	threshold int
+	tables    []string
+}
+
+// rewrite returns exprs with long IN lists replaced, leaving exprs unchanged as
+// the statement may be executed again
+func (l *inListLoader) rewrite(exprs []clause.Expression) []clause.Expression {
+	rewritten := make([]clause.Expression, len(exprs))
+	for i, expr := range exprs {
+		switch expr := expr.(type) {
+		case clause.IN:
+			rewritten[i] = expr
+			if len(expr.Values) > l.threshold {
+				if table, ok := l.load(expr.Values); ok {
+					rewritten[i] = inListTable{Column: expr.Column, Table: table}
+				}
+			}
+		case clause.Expr:
+			rewritten[i] = l.rewriteExpr(expr)
+		case clause.AndConditions:
+			rewritten[i] = clause.AndConditions{Exprs: l.rewrite(expr.Exprs)}
+		case clause.OrConditions:
+			rewritten[i] = clause.OrConditions{Exprs: l.rewrite(expr.Exprs)}
+		case clause.NotConditions:
+			rewritten[i] = clause.NotConditions{Exprs: l.rewrite(expr.Exprs)}
+		default:
+			rewritten[i] = expr
+		}
+	}
+	return rewritten
+}
+
+// rewriteExpr replaces the long slices bound in expr by sub-queries, which are
+// parenthesized unless the placeholder is, as gorm does for slices
+func (l *inListLoader) rewriteExpr(expr clause.Expr) clause.Expr {
+	var (
+		vars             []interface{}
+		afterParenthesis bool
+		idx              int
+	)
+	for _, b := range []byte(expr.SQL) {
+		if b != '?' || idx >= len(expr.Vars) {
+			afterParenthesis = b == '('
+			continue
+		}
+		if values, ok := l.listValues(expr.Vars[idx]); ok {
+			if table, ok := l.load(values); ok {
+				if vars == nil {
+					vars = append([]interface{}{}, expr.Vars...)
+				}
+				subQuery := "SELECT value FROM temp." + quoteIdentifier(table)
+				if !afterParenthesis && !expr.WithoutParentheses {
+					subQuery = "(" + subQuery + ")"
+				}
+				vars[idx] = clause.Expr{SQL: subQuery}
+			}
+		}
+		idx++
+	}
+	if vars != nil {
+		expr.Vars = vars
+	}
+	return expr
+}
+
+// listValues returns the values of v when it is a slice longer than the
+// threshold
+func (l *inListLoader) listValues(v interface{}) ([]interface{}, bool) {
+	if _, ok := v.(driver.Valuer); ok {
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array || rv.Type().Elem().Kind() == reflect.Uint8 || rv.Len() <= l.threshold {
+		return nil, false
+	}
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, true
+}
+
+// load creates a temporary table holding values in its value column
+func (l *inListLoader) load(values []interface{}) (string, bool) {
+	if !pinConnection(l.db) {
+		return "", false
+	}
+
+	table := fmt.Sprintf("duckdb_in_list_%d", inListSeq.Add(1))
+	l.tables = append(l.tables, table)
+	for start := 0; start < len(values); start += inListBatchSize {
+		batch := values[start:min(start+inListBatchSize, len(values))]
+		rows := strings.Repeat("(?),", len(batch))
+		rows = rows[:len(rows)-1]
+
+		query := "INSERT INTO temp." + quoteIdentifier(table) + " VALUES " + rows
+		if start == 0 {
+			query = "CREATE TEMP TABLE " + quoteIdentifier(table) + " AS SELECT * FROM (VALUES " + rows + ") AS v(value)"
+		}
+		if _, err := l.db.Statement.ConnPool.ExecContext(l.db.Statement.Context, query, batch...); err != nil {
+			l.db.AddError(fmt.Errorf("failed to load IN list: %w", err))
+			return "", false
+		}
+	}
+	return table, true
+}
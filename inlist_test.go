@@ -0,0 +1,142 @@
+package duckdb
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type inListRecord struct {
+	ID   int
+	Kind string
+}
+
+func TestInListThreshold(t *testing.T) {
+	var statements []string
+	db := openTestDB(t, Config{InListThreshold: 100, QueryStats: func(_ context.Context, stats QueryStats) {
+		statements = append(statements, stats.SQL)
+	}})
+	if err := db.Exec("CREATE TABLE in_list_records AS SELECT range::INTEGER AS id, CASE WHEN range % 2 = 0 THEN 'even' ELSE 'odd' END AS kind FROM range(5000)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	ids := make([]int, 2500)
+	for i := range ids {
+		ids[i] = i * 2
+	}
+	assertTempTables := func(t *testing.T) {
+		t.Helper()
+		var count int64
+		if err := db.Raw("SELECT count(*) FROM duckdb_tables() WHERE temporary").Scan(&count).Error; err != nil || count != 0 {
+			t.Errorf("expected temporary tables to be dropped, got %d, error %v", count, err)
+		}
+	}
+
+	t.Run("primary keys", func(t *testing.T) {
+		var records []inListRecord
+		if err := db.Find(&records, ids).Error; err != nil || len(records) != 2500 {
+			t.Errorf("expected 2500 records, got %d, error %v", len(records), err)
+		}
+		if sql := statements[len(statements)-1]; !strings.Contains(sql, `"in_list_records"."id" IN (SELECT value FROM temp."duckdb_in_list_`) {
+			t.Errorf("expected a sub-query on a temporary table, got %v", sql)
+		}
+		assertTempTables(t)
+	})
+
+	t.Run("where strings", func(t *testing.T) {
+		var count int64
+		if err := db.Model(&inListRecord{}).Where("id IN ? AND kind = ?", ids, "even").Count(&count).Error; err != nil || count != 2500 {
+			t.Errorf("expected 2500 records, got %d, error %v", count, err)
+		}
+		if err := db.Model(&inListRecord{}).Where("id NOT IN (?)", ids).Count(&count).Error; err != nil || count != 2500 {
+			t.Errorf("expected 2500 records, got %d, error %v", count, err)
+		}
+		if sql := statements[len(statements)-1]; !strings.Contains(sql, `id NOT IN (SELECT value FROM temp."duckdb_in_list_`) {
+			t.Errorf("expected a sub-query on a temporary table, got %v", sql)
+		}
+		if err := db.Model(&inListRecord{}).Where("id IN ?", ids[:10]).Count(&count).Error; err != nil || count != 10 {
+			t.Errorf("expected 10 records, got %d, error %v", count, err)
+		}
+		if sql := statements[len(statements)-1]; strings.Contains(sql, "temp.") {
+			t.Errorf("expected short lists to be inlined, got %v", sql)
+		}
+		assertTempTables(t)
+	})
+
+	t.Run("reused statement", func(t *testing.T) {
+		tx := db.Model(&inListRecord{}).Not(clause.IN{Column: "id", Values: toInterfaces(ids)}).Session(&gorm.Session{})
+		for i := 0; i < 2; i++ {
+			var count int64
+			if err := tx.Count(&count).Error; err != nil || count != 2500 {
+				t.Errorf("expected 2500 records, got %d, error %v", count, err)
+			}
+		}
+		assertTempTables(t)
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&inListRecord{}, ids[:1000]).Error; err != nil {
+				return err
+			}
+			var count int64
+			return tx.Model(&inListRecord{}).Where(map[string]interface{}{"id": ids}).Count(&count).Error
+		})
+		if err != nil {
+			t.Errorf("failed to delete in transaction, got error %v", err)
+		}
+		var count int64
+		if err := db.Model(&inListRecord{}).Count(&count).Error; err != nil || count != 4000 {
+			t.Errorf("expected 4000 records, got %d, error %v", count, err)
+		}
+		assertTempTables(t)
+	})
+}
+
+func TestInListThreshold_writes(t *testing.T) {
+	// a single writer connection, which the statements must not hold on to
+	db := openTestDB(t, Config{DSN: filepath.Join(t.TempDir(), "in_list.duckdb"), ReadConns: 1, InListThreshold: 100})
+	if err := db.Exec("CREATE TABLE in_list_records AS SELECT range::INTEGER AS id, 'odd' AS kind FROM range(5000)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get the pool, got error %v", err)
+	}
+
+	ids := make([]int, 2500)
+	for i := range ids {
+		ids[i] = i * 2
+	}
+	for i := 0; i < 2; i++ {
+		if err := db.Model(&inListRecord{}).Where("id IN ?", ids).Update("kind", "even").Error; err != nil {
+			t.Fatalf("failed to update, got error %v", err)
+		}
+		if err := db.Delete(&inListRecord{}, ids[:1000+i*1000]).Error; err != nil {
+			t.Fatalf("failed to delete, got error %v", err)
+		}
+		if inUse := sqlDB.Stats().InUse; inUse != 0 {
+			t.Errorf("expected the connections to be returned to the pool, got %d in use", inUse)
+		}
+	}
+
+	var count int64
+	if err := db.Model(&inListRecord{}).Where("kind = ?", "even").Count(&count).Error; err != nil || count != 500 {
+		t.Errorf("expected 500 records left to be updated, got %d, error %v", count, err)
+	}
+	if err := db.Raw("SELECT count(*) FROM duckdb_tables() WHERE temporary").Scan(&count).Error; err != nil || count != 0 {
+		t.Errorf("expected temporary tables to be dropped, got %d, error %v", count, err)
+	}
+}
+
+func toInterfaces(ids []int) []interface{} {
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	return values
+}
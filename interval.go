@@ -0,0 +1,33 @@
+package duckdb
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// isIntervalField reports whether field is stored in an INTERVAL column: it is a
+// time.Duration or has the interval type tag. Durations are bound as intervals
+// of microseconds, see bindValue, and intervals are read as durations, see
+// convertRows.
+func isIntervalField(field *schema.Field) bool {
+	return field.IndirectFieldType == durationType || strings.EqualFold(string(field.DataType), "interval")
+}
+
+// intervalValue returns the interval of a duration bound to an INTERVAL column,
+// as the driver binds durations as integers, and other values as they are
+func intervalValue(value interface{}) interface{} {
+	switch d := value.(type) {
+	case time.Duration:
+		return newIntervalValue(d.Microseconds())
+	case *time.Duration:
+		if d != nil {
+			return newIntervalValue(d.Microseconds())
+		}
+	}
+	return value
+}
@@ -0,0 +1,82 @@
+package duckdb
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type intervalTask struct {
+	ID      uint
+	Name    string
+	Timeout time.Duration
+	Backoff *time.Duration
+}
+
+func TestIntervalColumns(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&intervalTask{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	columnTypes, err := db.Migrator().ColumnTypes(&intervalTask{})
+	if err != nil {
+		t.Fatalf("failed to get column types, got error %v", err)
+	}
+	for _, columnType := range columnTypes {
+		if name := columnType.Name(); (name == "timeout" || name == "backoff") && columnType.DatabaseTypeName() != "INTERVAL" {
+			t.Errorf("expected an INTERVAL column for %s, got %s", name, columnType.DatabaseTypeName())
+		}
+	}
+
+	backoff := 1500 * time.Millisecond
+	tasks := []intervalTask{{Name: "fetch", Timeout: 90 * time.Second, Backoff: &backoff}, {Name: "sync"}}
+	if err := db.Create(&tasks).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+	var minutes int64
+	if err := db.Raw("SELECT minute(timeout) FROM interval_tasks WHERE name = 'fetch'").Scan(&minutes).Error; err != nil || minutes != 1 {
+		t.Errorf("expected an interval of a minute and a half, got %d minutes, error %v", minutes, err)
+	}
+
+	var found []intervalTask
+	if err := db.Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if len(found) != 2 || found[0].Timeout != 90*time.Second || found[0].Backoff == nil || *found[0].Backoff != backoff ||
+		found[1].Timeout != 0 || found[1].Backoff != nil {
+		t.Errorf("expected the durations back, got %+v", found)
+	}
+
+	if err := db.Model(&tasks[1]).Update("timeout", 2*time.Hour).Error; err != nil {
+		t.Fatalf("failed to update record, got error %v", err)
+	}
+	var timeout time.Duration
+	if err := db.Raw("SELECT timeout + INTERVAL 1 DAY FROM interval_tasks WHERE name = 'sync'").Scan(&timeout).Error; err != nil || timeout != 26*time.Hour {
+		t.Errorf("expected intervals to scan into durations, got %v, error %v", timeout, err)
+	}
+}
+
+func TestIntervalColumns_prepareStmt(t *testing.T) {
+	db := openTestDB(t, Config{}).Session(&gorm.Session{PrepareStmt: true})
+	if err := db.AutoMigrate(&intervalTask{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	backoff := 250 * time.Millisecond
+	task := intervalTask{Name: "prepared", Timeout: 3 * time.Minute, Backoff: &backoff}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+
+	var found intervalTask
+	if err := db.First(&found, task.ID).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if found.Timeout != task.Timeout || found.Backoff == nil || *found.Backoff != backoff {
+		t.Errorf("expected the durations back, got %+v", found)
+	}
+	var timeout time.Duration
+	if err := db.Raw("SELECT timeout FROM interval_tasks WHERE id = ?", task.ID).Scan(&timeout).Error; err != nil || timeout != task.Timeout {
+		t.Errorf("expected intervals to scan into durations, got %v, error %v", timeout, err)
+	}
+}
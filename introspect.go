@@ -0,0 +1,134 @@
+package duckdb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Extension describes a DuckDB extension as listed by duckdb_extensions()
+type Extension struct {
+	Name        string `gorm:"column:extension_name"`
+	Version     string `gorm:"column:extension_version"`
+	Description string
+	Installed   bool
+	Loaded      bool
+	InstallPath string
+}
+
+// Extensions lists the extensions known to DuckDB, whether installed or not
+func Extensions(db *gorm.DB) (extensions []Extension, err error) {
+	err = db.Session(&gorm.Session{NewDB: true}).Raw(
+		"SELECT extension_name, extension_version, description, installed, loaded, install_path FROM duckdb_extensions() ORDER BY extension_name",
+	).Find(&extensions).Error
+	return
+}
+
+// ExtensionLoaded reports whether the extension name is loaded, so that
+// features depending on it can be enabled
+func ExtensionLoaded(db *gorm.DB, name string) (loaded bool, err error) {
+	err = db.Session(&gorm.Session{NewDB: true}).Raw(
+		"SELECT count(*) > 0 FROM duckdb_extensions() WHERE extension_name = ? AND loaded", name,
+	).Find(&loaded).Error
+	return
+}
+
+// Database describes an attached database as listed by duckdb_databases()
+type Database struct {
+	Name string `gorm:"column:database_name"`
+	// Path is the file of the database, empty for in-memory databases
+	Path     string
+	Type     string
+	ReadOnly bool `gorm:"column:readonly"`
+	Internal bool
+}
+
+// currentCatalogSQL selects the database that names without a catalog resolve
+// to, as switched by Use
+const currentCatalogSQL = "SELECT database_name, coalesce(path, '') AS path, type, readonly, internal " +
+	"FROM duckdb_databases() WHERE database_name = current_database()"
+
+// CurrentCatalog describes the database that tables named without a catalog
+// are in, which is the database opened unless Use switched to an attached one
+func CurrentCatalog(db *gorm.DB) (catalog Database, err error) {
+	err = db.Session(&gorm.Session{NewDB: true}).Raw(currentCatalogSQL).Take(&catalog).Error
+	return
+}
+
+// CurrentSchema returns the schema of the current catalog that tables named
+// without a schema are in, main unless Use switched to another
+func CurrentSchema(db *gorm.DB) (schema string, err error) {
+	err = db.Session(&gorm.Session{NewDB: true}).Raw("SELECT current_schema()").Row().Scan(&schema)
+	return
+}
+
+// Function describes one overload of a function, macro or pragma as listed by
+// duckdb_functions()
+type Function struct {
+	SchemaName     string
+	Name           string
+	Type           string
+	Description    string
+	ReturnType     string
+	Parameters     []string
+	ParameterTypes []string
+	Varargs        string
+	HasSideEffects bool
+	Internal       bool
+}
+
+// Functions returns the overloads of the function name, which are empty when
+// no such function exists
+func Functions(db *gorm.DB, name string) (functions []Function, err error) {
+	rows, err := db.Session(&gorm.Session{NewDB: true}).Raw(
+		"SELECT schema_name, function_name, function_type, description, return_type, parameters, parameter_types, varargs, "+
+			"has_side_effects, internal FROM duckdb_functions() WHERE function_name = ? ORDER BY function_oid", name,
+	).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			function                         Function
+			description, returnType, varargs sql.NullString
+			parameters, parameterTypes       interface{}
+			hasSideEffects                   sql.NullBool
+		)
+		if err := rows.Scan(&function.SchemaName, &function.Name, &function.Type, &description, &returnType,
+			&parameters, &parameterTypes, &varargs, &hasSideEffects, &function.Internal); err != nil {
+			return nil, err
+		}
+		function.Description, function.ReturnType, function.Varargs = description.String, returnType.String, varargs.String
+		function.HasSideEffects = hasSideEffects.Bool
+		function.Parameters, function.ParameterTypes = stringList(parameters), stringList(parameterTypes)
+		functions = append(functions, function)
+	}
+	return functions, rows.Err()
+}
+
+// HasFunction reports whether a function, macro or pragma called name exists,
+// including those of loaded extensions
+func HasFunction(db *gorm.DB, name string) (exists bool, err error) {
+	err = db.Session(&gorm.Session{NewDB: true}).Raw(
+		"SELECT count(*) > 0 FROM duckdb_functions() WHERE function_name = ?", name,
+	).Find(&exists).Error
+	return
+}
+
+// stringList converts a LIST value as returned by the driver into strings
+func stringList(value interface{}) []string {
+	list, _ := value.([]interface{})
+	if len(list) == 0 {
+		return nil
+	}
+	strs := make([]string, len(list))
+	for i, v := range list {
+		if v != nil {
+			strs[i] = fmt.Sprint(v)
+		}
+	}
+	return strs
+}
@@ -0,0 +1,102 @@
+package duckdb
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExtensions(t *testing.T) {
+	db := openTestDB(t, Config{})
+
+	extensions, err := Extensions(db)
+	if err != nil || len(extensions) == 0 {
+		t.Fatalf("failed to list extensions, got %v, error %v", extensions, err)
+	}
+	for _, extension := range extensions {
+		if extension.Name == "json" && !extension.Loaded {
+			t.Errorf("expected the json extension to be loaded, got %+v", extension)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "json", want: true},
+		{name: "no_such_extension", want: false},
+	}
+	for _, tt := range tests {
+		if loaded, err := ExtensionLoaded(db, tt.name); err != nil || loaded != tt.want {
+			t.Errorf("ExtensionLoaded(%q) = %v, %v, want %v", tt.name, loaded, err, tt.want)
+		}
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE MACRO add_one(x) AS x + 1").Error; err != nil {
+		t.Fatalf("failed to create macro, got error %v", err)
+	}
+
+	functions, err := Functions(db, "add_one")
+	if err != nil || len(functions) != 1 {
+		t.Fatalf("failed to list functions, got %+v, error %v", functions, err)
+	}
+	if functions[0].Type != "macro" || len(functions[0].Parameters) != 1 || functions[0].Parameters[0] != "x" {
+		t.Errorf("expected the macro and its parameters, got %+v", functions[0])
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "add_one", want: true},
+		{name: "json_extract", want: true},
+		{name: "no_such_function", want: false},
+	}
+	for _, tt := range tests {
+		if exists, err := HasFunction(db, tt.name); err != nil || exists != tt.want {
+			t.Errorf("HasFunction(%q) = %v, %v, want %v", tt.name, exists, err, tt.want)
+		}
+	}
+}
+
+func TestCurrentCatalog(t *testing.T) {
+	db := openTestDB(t, Config{})
+
+	catalog, err := CurrentCatalog(db)
+	if err != nil || catalog.Name != "memory" || catalog.Path != "" || catalog.ReadOnly {
+		t.Errorf("expected the in-memory database, got %+v, error %v", catalog, err)
+	}
+	if name := db.Migrator().CurrentDatabase(); name != "memory" {
+		t.Errorf("expected memory, got %s", name)
+	}
+	if schema, err := CurrentSchema(db); err != nil || schema != "main" {
+		t.Errorf("expected main, got %s, error %v", schema, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "archive.duckdb")
+	if err := Attach(db, path, "archive"); err != nil {
+		t.Fatalf("failed to attach, got error %v", err)
+	}
+	if name := db.Table("archive.orders").Migrator().CurrentDatabase(); name != "archive" {
+		t.Errorf("expected the catalog of the table, got %s", name)
+	}
+	if err := db.Exec("CREATE SCHEMA archive.history").Error; err != nil {
+		t.Fatalf("failed to create schema, got error %v", err)
+	}
+	if err := Use(db, "archive", "history"); err != nil {
+		t.Fatalf("failed to use archive, got error %v", err)
+	}
+
+	catalog, err = CurrentCatalog(db)
+	if err != nil || catalog.Name != "archive" || catalog.Path != path || catalog.Type != "duckdb" {
+		t.Errorf("expected the attached database, got %+v, error %v", catalog, err)
+	}
+	if name := db.Migrator().CurrentDatabase(); name != "archive" {
+		t.Errorf("expected archive, got %s", name)
+	}
+	if schema, err := CurrentSchema(db); err != nil || schema != "history" {
+		t.Errorf("expected history, got %s, error %v", schema, err)
+	}
+}
@@ -0,0 +1,137 @@
+package duckdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// isJSONField reports whether field is stored in a JSON column: it has the json
+// data type, as gorm.io/datatypes.JSON and fields with the json type tag do, or
+// the json serializer. Queries of models with such fields read their columns
+// as text, see selectTextColumns; raw queries must cast them to VARCHAR.
+func isJSONField(field *schema.Field) bool {
+	if _, ok := field.Serializer.(schema.JSONSerializer); ok {
+		return true
+	}
+	return strings.EqualFold(string(field.DataType), "json")
+}
+
+// JSONPath is an expression selecting a value inside a JSON column, built with
+// JSONExtract or JSONExtractString. It can be used wherever gorm accepts an
+// expression:
+//
+//	db.Select("id, ?", duckdb.JSONExtractString("attrs", "$.color")).Find(&rows)
+//	db.Where(duckdb.JSONExtractString("attrs", "$.color").Eq("red")).Find(&rows)
+type JSONPath struct {
+	column string
+	path   string
+	text   bool
+}
+
+// JSONExtract selects the value at path, e.g. $.a.b or /a/b, in the JSON column
+// as JSON, like the -> operator
+func JSONExtract(column, path string) JSONPath {
+	return JSONPath{column: column, path: path}
+}
+
+// JSONExtractString selects the value at path in the JSON column as text, like
+// the ->> operator
+func JSONExtractString(column, path string) JSONPath {
+	return JSONPath{column: column, path: path, text: true}
+}
+
+// Build writes the column and operator, binding the path as a parameter
+func (p JSONPath) Build(builder clause.Builder) {
+	builder.WriteByte('(')
+	builder.WriteQuoted(columnOf(p.column))
+	if p.text {
+		builder.WriteString(" ->> ")
+	} else {
+		builder.WriteString(" -> ")
+	}
+	builder.AddVar(builder, p.path)
+	builder.WriteByte(')')
+}
+
+// Eq compares the selected value with value
+func (p JSONPath) Eq(value interface{}) clause.Expression {
+	return clause.Expr{SQL: "? = ?", Vars: []interface{}{p, value}}
+}
+
+// columnOf splits a possibly table qualified column name
+func columnOf(name string) clause.Column {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return clause.Column{Table: name[:i], Name: name[i+1:]}
+	}
+	return clause.Column{Name: name}
+}
+
+// JSONSetExpr updates keys inside a JSON column, built with JSONSet
+type JSONSetExpr struct {
+	column string
+	paths  []string
+	values []interface{}
+}
+
+// JSONSet returns an expression for Update and Updates that changes keys inside
+// the JSON column instead of writing the whole document:
+//
+//	db.Model(&user).Updates(map[string]interface{}{
+//		"attrs": duckdb.JSONSet("attrs").Set("$.theme", "dark").Set("$.beta", nil),
+//	})
+//
+// Changes are applied with json_merge_patch, so setting a key to nil removes
+// it and setting it to an object merges the object into the current value.
+func JSONSet(column string) JSONSetExpr {
+	return JSONSetExpr{column: column}
+}
+
+// Set sets the value at path, a dotted object path such as $.a.b
+func (e JSONSetExpr) Set(path string, value interface{}) JSONSetExpr {
+	return JSONSetExpr{
+		column: e.column,
+		paths:  append(e.paths[:len(e.paths):len(e.paths)], path),
+		values: append(e.values[:len(e.values):len(e.values)], value),
+	}
+}
+
+// Build writes the merge of the changes into the current document, which is
+// treated as an empty object when NULL
+func (e JSONSetExpr) Build(builder clause.Builder) {
+	patch := map[string]interface{}{}
+	for i, path := range e.paths {
+		keys := strings.Split(strings.TrimPrefix(strings.TrimPrefix(path, "$"), "."), ".")
+		object := patch
+		for j, key := range keys {
+			if key == "" || strings.ContainsAny(key, "[]") {
+				builder.AddError(fmt.Errorf("unsupported JSON path %q, expected object keys such as $.a.b", path))
+				return
+			}
+			if j == len(keys)-1 {
+				object[key] = e.values[i]
+				break
+			}
+			child, ok := object[key].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				object[key] = child
+			}
+			object = child
+		}
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		builder.AddError(err)
+		return
+	}
+	builder.WriteString("json_merge_patch(COALESCE(")
+	builder.WriteQuoted(columnOf(e.column))
+	builder.WriteString(", '{}'), CAST(")
+	builder.AddVar(builder, string(data))
+	builder.WriteString(" AS JSON))")
+}
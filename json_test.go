@@ -0,0 +1,154 @@
+package duckdb
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type jsonRecord struct {
+	ID    int
+	Attrs string `gorm:"type:JSON"`
+}
+
+func TestJSONPath(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec(`CREATE TABLE json_records (id INTEGER, attrs JSON);
+		INSERT INTO json_records VALUES (1, '{"color": "red", "size": {"w": 2}}'), (2, '{"color": "blue"}')`).Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	var ids []int
+	if err := db.Model(&jsonRecord{}).Where(JSONExtractString("attrs", "$.color").Eq("blue")).Pluck("id", &ids).Error; err != nil || len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("expected to filter by a JSON value, got %v, error %v", ids, err)
+	}
+
+	var widths []string
+	if err := db.Model(&jsonRecord{}).Select("?", JSONExtractString("json_records.attrs", "$.size.w")).Where("id = ?", 1).Find(&widths).Error; err != nil || len(widths) != 1 || widths[0] != "2" {
+		t.Errorf("expected to select a JSON value as text, got %v, error %v", widths, err)
+	}
+
+	var sizes []int
+	if err := db.Model(&jsonRecord{}).Where(JSONExtract("attrs", "$.size").Eq(`{"w":2}`)).Pluck("id", &sizes).Error; err != nil || len(sizes) != 1 || sizes[0] != 1 {
+		t.Errorf("expected to compare a JSON value, got %v, error %v", sizes, err)
+	}
+}
+
+func TestJSONSet(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec(`CREATE TABLE json_records (id INTEGER, attrs JSON);
+		INSERT INTO json_records VALUES (1, '{"theme": "light", "beta": true, "limits": {"rows": 10, "cols": 5}}'), (2, NULL)`).Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	update := JSONSet("attrs").Set("$.theme", "dark").Set("$.beta", nil).Set("limits.rows", 20)
+	if err := db.Model(&jsonRecord{}).Where("id IS NOT NULL").Updates(map[string]interface{}{"attrs": update}).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+
+	var attrs []string
+	if err := db.Model(&jsonRecord{}).Order("id").Pluck("CAST(attrs AS VARCHAR)", &attrs).Error; err != nil {
+		t.Fatalf("failed to read rows, got error %v", err)
+	}
+	want := []string{`{"limits":{"cols":5,"rows":20},"theme":"dark"}`, `{"limits":{"rows":20},"theme":"dark"}`}
+	if len(attrs) != 2 || attrs[0] != want[0] || attrs[1] != want[1] {
+		t.Errorf("expected %v, got %v", want, attrs)
+	}
+
+	if err := db.Model(&jsonRecord{}).Where("id = 1").Update("attrs", JSONSet("attrs").Set("$.items[0]", 1)).Error; err == nil {
+		t.Errorf("expected an error for array paths")
+	}
+}
+
+// jsonDocument is a JSON type like gorm.io/datatypes.JSON
+type jsonDocument json.RawMessage
+
+func (jsonDocument) GormDataType() string { return "json" }
+
+func (j *jsonDocument) Scan(value interface{}) error {
+	switch value := value.(type) {
+	case string:
+		*j = jsonDocument(value)
+	case []byte:
+		*j = append((*j)[:0], value...)
+	default:
+		return fmt.Errorf("failed to unmarshal JSON value: %v", value)
+	}
+	return nil
+}
+
+func (j jsonDocument) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+type jsonProfile struct {
+	ID       int
+	Settings jsonDocument
+	Tags     []string          `gorm:"serializer:json"`
+	Extra    map[string]string `gorm:"type:json;serializer:json"`
+}
+
+func TestJSONColumns(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&jsonProfile{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var types []string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'json_profiles' AND column_name <> 'id' ORDER BY column_index").Scan(&types).Error; err != nil {
+		t.Fatalf("failed to query column types, got error %v", err)
+	}
+	if expected := []string{"JSON", "JSON", "JSON"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected JSON columns, got %v", types)
+	}
+
+	profile := jsonProfile{
+		ID:       1,
+		Settings: jsonDocument(`{"sizes":[1,2],"theme":"dark"}`),
+		Tags:     []string{"a", "b"},
+		Extra:    map[string]string{"k": "v"},
+	}
+	if err := db.Create(&profile).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	var found jsonProfile
+	if err := db.First(&found, 1).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if !reflect.DeepEqual(found, profile) {
+		t.Errorf("expected %+v, got %+v", profile, found)
+	}
+	var theme string
+	if err := db.Raw("SELECT settings->>'$.theme' FROM json_profiles").Scan(&theme).Error; err != nil || theme != "dark" {
+		t.Errorf("expected queryable JSON, got %q, error %v", theme, err)
+	}
+
+	documents := []string{`"hello"`, `12345678901234567890`, `[1.0,2]`, `{"z":1,"a":2}`}
+	for i, document := range documents {
+		profile := jsonProfile{ID: i + 2, Settings: jsonDocument(document)}
+		if err := db.Create(&profile).Error; err != nil {
+			t.Fatalf("failed to create record, got error %v", err)
+		}
+		var found jsonProfile
+		if err := db.First(&found, profile.ID).Error; err != nil {
+			t.Fatalf("failed to find record, got error %v", err)
+		}
+		if string(found.Settings) != document {
+			t.Errorf("expected the JSON text %s back, got %s", document, found.Settings)
+		}
+	}
+
+	recorder := &sqlRecorder{Interface: db.Logger}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&jsonProfile{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected JSON columns to be left as they are, got %v", recorder.sql)
+	}
+}
@@ -0,0 +1,54 @@
+package duckdb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// ListSerializerName is the serializer storing a slice field in a LIST column,
+// typed after the elements of the slice, instead of encoding it as text:
+//
+//	type Post struct {
+//		ID      uint
+//		Tags    []string `gorm:"serializer:list"`
+//		Ratings []int64  `gorm:"serializer:list"`
+//	}
+//	// CREATE TABLE "posts" ("id" UBIGINT, "tags" VARCHAR[], "ratings" BIGINT[], ...)
+//
+// Elements may be structs, slices and maps of string keys, converted like the
+// fields of EmbeddedStructSerializer. Nil slices are stored as NULL and empty
+// slices as empty lists. DuckDB rejects updates of LIST columns in tables with
+// a primary key as duplicate keys.
+const ListSerializerName = "list"
+
+func init() {
+	schema.RegisterSerializer(ListSerializerName, ListSerializer{})
+}
+
+// ListSerializer converts slice fields to and from LIST columns; values are
+// bound as JSON and cast to the column's type
+type ListSerializer struct{}
+
+// Scan assigns the LIST value read from the database to the field
+func (ListSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	return EmbeddedStructSerializer{}.Scan(ctx, field, dst, dbValue)
+}
+
+// Value returns the field as JSON, leaving nil slices NULL
+func (ListSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	if value := reflect.ValueOf(fieldValue); value.Kind() == reflect.Slice && value.IsNil() {
+		return nil, nil
+	}
+	return EmbeddedStructSerializer{}.Value(ctx, field, dst, fieldValue)
+}
+
+// isList reports whether field is stored in a LIST column, as fields with the
+// list serializer or the list type tag are. The type tag names the column type
+// of fields scanning and valuing lists themselves.
+func isList(field *schema.Field) bool {
+	_, ok := field.Serializer.(ListSerializer)
+	return ok || strings.EqualFold(string(field.DataType), "list")
+}
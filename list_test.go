@@ -0,0 +1,101 @@
+package duckdb
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type listPost struct {
+	ID      uint
+	Tags    []string  `gorm:"serializer:list"`
+	Ratings []int64   `gorm:"serializer:list"`
+	Scores  []float64 `gorm:"serializer:list"`
+}
+
+func TestListSerializer(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&listPost{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var types []string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'list_posts' AND column_name <> 'id' ORDER BY column_index").Scan(&types).Error; err != nil {
+		t.Fatalf("failed to query column types, got error %v", err)
+	}
+	if expected := []string{"VARCHAR[]", "BIGINT[]", "DOUBLE[]"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected LIST columns %v, got %v", expected, types)
+	}
+
+	posts := []listPost{
+		{Tags: []string{"go", "duck's"}, Ratings: []int64{5, 3}, Scores: []float64{}},
+		{},
+	}
+	if err := db.Create(&posts).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+
+	var found []listPost
+	if err := db.Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if !reflect.DeepEqual(found, posts) {
+		t.Errorf("expected %+v, got %+v", posts, found)
+	}
+	var nulls int64
+	db.Raw("SELECT count(*) FROM list_posts WHERE tags IS NULL AND scores IS NULL").Scan(&nulls)
+	if nulls != 1 {
+		t.Errorf("expected nil slices to be stored as NULL, got %d NULL rows", nulls)
+	}
+
+	recorder := &sqlRecorder{Interface: db.Logger}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&listPost{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected LIST columns to be left as they are, got %v", recorder.sql)
+	}
+}
+
+type listLabel struct {
+	Name   string
+	Values []string `gorm:"serializer:list"`
+}
+
+func TestListSerializer_update(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&listLabel{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Create(&listLabel{Name: "colors", Values: []string{"red"}}).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	if err := db.Model(&listLabel{}).Where("name = ?", "colors").Update("values", []string{"red", "green"}).Error; err != nil {
+		t.Fatalf("failed to update list, got error %v", err)
+	}
+	var label listLabel
+	if err := db.First(&label, "name = ?", "colors").Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if !reflect.DeepEqual(label.Values, []string{"red", "green"}) {
+		t.Errorf("expected the updated list, got %v", label.Values)
+	}
+}
+
+func TestListSerializer_appender(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&listPost{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	posts := []listPost{{Tags: []string{"a", "b"}, Ratings: []int64{1}}, {}}
+	if err := db.Scopes(WithAppender()).Create(&posts).Error; err != nil {
+		t.Fatalf("failed to append records, got error %v", err)
+	}
+	var found []listPost
+	if err := db.Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if !reflect.DeepEqual(found, posts) {
+		t.Errorf("expected %+v, got %+v", posts, found)
+	}
+}
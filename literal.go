@@ -0,0 +1,150 @@
+package duckdb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// inlineSQL returns query with vars inlined as DuckDB literals, for SQL that is
+// stored and run again such as the queries of materialized views and column
+// defaults. Unlike the SQL logged, the literals keep the values as bound:
+// binary values are inlined as BLOB literals and times as TIMESTAMPTZ literals
+// with their offset. Values without a literal fail to be inlined.
+func (dialector Dialector) inlineSQL(query string, vars ...interface{}) (string, error) {
+	positional := make([]interface{}, 0, len(vars))
+	named := map[string]interface{}{}
+	for _, v := range vars {
+		if arg, ok := v.(sql.NamedArg); ok && arg.Name != "" {
+			named[arg.Name] = arg.Value
+		} else {
+			positional = append(positional, v)
+		}
+	}
+
+	var (
+		builder strings.Builder
+		next    int
+		quote   byte
+	)
+	write := func(value interface{}) error {
+		literal, err := sqlLiteral(value)
+		builder.WriteString(literal)
+		return err
+	}
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?' && !dialector.numberedPlaceholders():
+			if next >= len(positional) {
+				return "", fmt.Errorf("missing value of parameter %d", next+1)
+			}
+			if err := write(positional[next]); err != nil {
+				return "", err
+			}
+			next++
+			continue
+		case c == '$' && i+1 < len(query):
+			end := i + 1
+			for end < len(query) && (query[end] == '_' || isAlphanumeric(query[end])) {
+				end++
+			}
+			name := query[i+1 : end]
+			if n, err := strconv.Atoi(name); err == nil && dialector.numberedPlaceholders() {
+				if n < 1 || n > len(positional) {
+					return "", fmt.Errorf("missing value of parameter %d", n)
+				}
+				if err := write(positional[n-1]); err != nil {
+					return "", err
+				}
+				i = end - 1
+				continue
+			} else if value, ok := named[name]; ok {
+				if err := write(value); err != nil {
+					return "", err
+				}
+				i = end - 1
+				continue
+			}
+		}
+		builder.WriteByte(c)
+	}
+	return builder.String(), nil
+}
+
+// isAlphanumeric reports whether c is an ASCII letter or digit
+func isAlphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// sqlLiteral returns the DuckDB literal of value
+func sqlLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case sensitiveValue:
+		return sqlLiteral(v.value)
+	case string:
+		return quoteString(v), nil
+	case []byte:
+		var builder strings.Builder
+		builder.WriteByte('\'')
+		for _, b := range v {
+			fmt.Fprintf(&builder, `\x%02X`, b)
+		}
+		builder.WriteString("'::BLOB")
+		return builder.String(), nil
+	case time.Time:
+		return quoteString(v.Format("2006-01-02 15:04:05.999999-07:00")) + "::TIMESTAMPTZ", nil
+	case driver.Valuer:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return "NULL", nil
+		}
+		dv, err := v.Value()
+		if err != nil {
+			return "", err
+		}
+		return sqlLiteral(dv)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return sqlLiteral(rv.Elem().Interface())
+	case reflect.Bool:
+		if rv.Bool() {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		typeName := "DOUBLE"
+		if rv.Kind() == reflect.Float32 {
+			typeName = "FLOAT"
+		}
+		if f := rv.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return quoteString(strconv.FormatFloat(f, 'g', -1, 64)) + "::" + typeName, nil
+		}
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()) + "::" + typeName, nil
+	case reflect.String:
+		return quoteString(rv.String()), nil
+	}
+	return "", fmt.Errorf("cannot inline value %v of type %T in SQL", value, value)
+}
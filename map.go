@@ -0,0 +1,54 @@
+package duckdb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// MapSerializerName is the serializer storing a map field of string keys in a
+// MAP column, typed after the values of the map, instead of encoding it as
+// text:
+//
+//	type Product struct {
+//		ID         uint
+//		Attributes map[string]string `gorm:"serializer:map"`
+//		Stock      map[string]int64  `gorm:"serializer:map"`
+//	}
+//	// CREATE TABLE "products" ("id" UBIGINT, "attributes" MAP(VARCHAR, VARCHAR), "stock" MAP(VARCHAR, BIGINT), ...)
+//
+// Values may be structs, slices and maps of string keys, converted like the
+// fields of EmbeddedStructSerializer. Nil maps are stored as NULL and empty
+// maps as empty MAPs.
+const MapSerializerName = "map"
+
+func init() {
+	schema.RegisterSerializer(MapSerializerName, MapSerializer{})
+}
+
+// MapSerializer converts map fields to and from MAP columns; values are bound
+// as JSON and cast to the column's type
+type MapSerializer struct{}
+
+// Scan assigns the MAP value read from the database to the field
+func (MapSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	return EmbeddedStructSerializer{}.Scan(ctx, field, dst, dbValue)
+}
+
+// Value returns the field as JSON, leaving nil maps NULL
+func (MapSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	if value := reflect.ValueOf(fieldValue); value.Kind() == reflect.Map && value.IsNil() {
+		return nil, nil
+	}
+	return EmbeddedStructSerializer{}.Value(ctx, field, dst, fieldValue)
+}
+
+// isMap reports whether field is stored in a MAP column, as fields with the map
+// serializer or the map type tag are. The type tag names the column type of
+// fields scanning and valuing maps themselves.
+func isMap(field *schema.Field) bool {
+	_, ok := field.Serializer.(MapSerializer)
+	return ok || strings.EqualFold(string(field.DataType), "map")
+}
@@ -0,0 +1,56 @@
+package duckdb
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type mapProduct struct {
+	ID         uint
+	Attributes map[string]string `gorm:"serializer:map"`
+	Stock      map[string]int64  `gorm:"serializer:map"`
+}
+
+func TestMapSerializer(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&mapProduct{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var types []string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'map_products' AND column_name <> 'id' ORDER BY column_index").Scan(&types).Error; err != nil {
+		t.Fatalf("failed to query column types, got error %v", err)
+	}
+	if expected := []string{"MAP(VARCHAR, VARCHAR)", "MAP(VARCHAR, BIGINT)"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected MAP columns %v, got %v", expected, types)
+	}
+
+	products := []mapProduct{
+		{Attributes: map[string]string{"color": "red", "size": "it's large"}, Stock: map[string]int64{"berlin": 3, "tokyo": 0}},
+		{Stock: map[string]int64{}},
+	}
+	if err := db.Create(&products).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+
+	var found []mapProduct
+	if err := db.Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if !reflect.DeepEqual(found, products) {
+		t.Errorf("expected %+v, got %+v", products, found)
+	}
+	var color string
+	if err := db.Raw("SELECT attributes['color'][1] FROM map_products WHERE id = ?", products[0].ID).Scan(&color).Error; err != nil || color != "red" {
+		t.Errorf("expected the MAP to be queryable by key, got %q, error %v", color, err)
+	}
+
+	recorder := &sqlRecorder{Interface: db.Logger}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&mapProduct{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected MAP columns to be left as they are, got %v", recorder.sql)
+	}
+}
@@ -0,0 +1,143 @@
+package duckdb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaterializedViewsTable is the table holding the definitions of materialized
+// views
+const MaterializedViewsTable = "schema_materialized_views"
+
+// RefreshMode is how RefreshView updates a materialized view
+type RefreshMode int
+
+const (
+	// RefreshFull replaces all rows of the view with the result of its query
+	RefreshFull RefreshMode = iota
+	// RefreshIncremental appends the rows of the query whose key is greater than
+	// the greatest key in the view, for append-only sources
+	RefreshIncremental
+)
+
+// RefreshPolicy is how a materialized view is refreshed
+type RefreshPolicy struct {
+	Mode RefreshMode
+	// Key is the column compared by incremental refreshes, e.g. an ID or a
+	// creation time increasing with every new row
+	Key string
+}
+
+// MaterializedView is the definition of a materialized view
+type MaterializedView struct {
+	Name        string `gorm:"primaryKey"`
+	Query       string
+	Incremental bool
+	Key         string
+	RefreshedAt time.Time
+}
+
+func (MaterializedView) TableName() string {
+	return MaterializedViewsTable
+}
+
+// CreateMaterializedView materializes query, a SQL string or a query built on
+// a session, into the table name, which DuckDB has no materialized views for.
+// The definition is kept in MaterializedViewsTable for RefreshView:
+//
+//	duckdb.CreateMaterializedView(db, "daily_sales",
+//		db.Model(&Order{}).Select("date_trunc('day', created_at) AS day, sum(total) AS total").Group("day"),
+//		duckdb.RefreshPolicy{})
+//
+// Values bound in query are stored inlined as SQL literals, see inlineSQL.
+func CreateMaterializedView(db *gorm.DB, name string, query interface{}, policy RefreshPolicy) error {
+	querySQL, err := materializedQuery(query)
+	if err != nil {
+		return err
+	}
+	if policy.Mode == RefreshIncremental && policy.Key == "" {
+		return errors.New("incremental refreshes require a key")
+	}
+
+	return db.Session(&gorm.Session{NewDB: true}).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("CREATE OR REPLACE TABLE ? AS "+querySQL, clause.Table{Name: name}).Error; err != nil {
+			return err
+		}
+		if err := tx.Migrator().AutoMigrate(&MaterializedView{}); err != nil {
+			return err
+		}
+		return tx.Save(&MaterializedView{
+			Name:        name,
+			Query:       querySQL,
+			Incremental: policy.Mode == RefreshIncremental,
+			Key:         policy.Key,
+			RefreshedAt: time.Now(),
+		}).Error
+	})
+}
+
+// RefreshView updates the materialized view name by its refresh policy in a
+// transaction, so that readers see either the old or the new rows
+func RefreshView(db *gorm.DB, name string) error {
+	return db.Session(&gorm.Session{NewDB: true}).Transaction(func(tx *gorm.DB) error {
+		var view MaterializedView
+		if err := tx.Where("name = ?", name).Take(&view).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("materialized view %s does not exist", name)
+			}
+			return err
+		}
+
+		table := clause.Table{Name: name}
+		if view.Incremental {
+			key := clause.Column{Name: view.Key}
+			if err := tx.Exec(
+				"INSERT INTO ? BY NAME SELECT * FROM ("+view.Query+") AS q WHERE (SELECT max(?) FROM ?) IS NULL OR q.? > (SELECT max(?) FROM ?)",
+				table, key, table, key, key, table,
+			).Error; err != nil {
+				return err
+			}
+		} else {
+			if err := tx.Exec("DELETE FROM ?", table).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("INSERT INTO ? BY NAME "+view.Query, table).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&view).Update("refreshed_at", time.Now()).Error
+	})
+}
+
+// DropMaterializedView drops the materialized view name and its definition
+func DropMaterializedView(db *gorm.DB, name string) error {
+	return db.Session(&gorm.Session{NewDB: true}).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DROP TABLE IF EXISTS ?", clause.Table{Name: name}).Error; err != nil {
+			return err
+		}
+		if !tx.Migrator().HasTable(&MaterializedView{}) {
+			return nil
+		}
+		return tx.Where("name = ?", name).Delete(&MaterializedView{}).Error
+	})
+}
+
+// materializedQuery returns the SQL of query with its values inlined
+func materializedQuery(query interface{}) (string, error) {
+	switch query := query.(type) {
+	case string:
+		return query, nil
+	case *gorm.DB:
+		stmt := query.Session(&gorm.Session{DryRun: true}).Find(&[]map[string]interface{}{}).Statement
+		if stmt.Error != nil {
+			return "", stmt.Error
+		}
+		dialector, _ := dialectorOf(query)
+		return dialector.inlineSQL(stmt.SQL.String(), stmt.Vars...)
+	}
+	return "", fmt.Errorf("unsupported query %T, expected a SQL string or *gorm.DB", query)
+}
@@ -0,0 +1,111 @@
+package duckdb
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type matviewOrder struct {
+	ID     int `gorm:"primaryKey;autoIncrement:false"`
+	Region string
+	Total  float64
+}
+
+type matviewEvent struct {
+	ID       int `gorm:"primaryKey;autoIncrement:false"`
+	Kind     []byte
+	Occurred time.Time
+}
+
+func TestMaterializedView(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&matviewOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Create(&[]matviewOrder{{1, "eu", 10}, {2, "us", 20}, {3, "eu", 5}}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	query := db.Model(&matviewOrder{}).Select("region, sum(total) AS total").Where("total > ?", 1).Group("region")
+	if err := CreateMaterializedView(db, "region_totals", query, RefreshPolicy{}); err != nil {
+		t.Fatalf("failed to create view, got error %v", err)
+	}
+	if err := CreateMaterializedView(db, "recent_orders", "SELECT id, total FROM matview_orders", RefreshPolicy{Mode: RefreshIncremental, Key: "id"}); err != nil {
+		t.Fatalf("failed to create view, got error %v", err)
+	}
+
+	if err := db.Create(&[]matviewOrder{{4, "us", 30}}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	// an incremental refresh does not see changes of existing rows
+	if err := db.Model(&matviewOrder{}).Where("id = 1").Update("total", 100).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+
+	var total float64
+	if err := db.Table("region_totals").Where("region = ?", "us").Pluck("total", &total).Error; err != nil || total != 20 {
+		t.Errorf("expected the materialized total before refreshing, got %v, error %v", total, err)
+	}
+	for _, name := range []string{"region_totals", "recent_orders"} {
+		if err := RefreshView(db, name); err != nil {
+			t.Fatalf("failed to refresh %s, got error %v", name, err)
+		}
+	}
+	if err := db.Table("region_totals").Where("region = ?", "us").Pluck("total", &total).Error; err != nil || total != 50 {
+		t.Errorf("expected the refreshed total, got %v, error %v", total, err)
+	}
+	var totals []float64
+	if err := db.Table("recent_orders").Order("id").Pluck("total", &totals).Error; err != nil || len(totals) != 4 || totals[0] != 10 || totals[3] != 30 {
+		t.Errorf("expected the new row to be appended, got %v, error %v", totals, err)
+	}
+
+	if err := RefreshView(db, "missing"); err == nil {
+		t.Errorf("expected an error for unknown views")
+	}
+	if err := DropMaterializedView(db, "region_totals"); err != nil || db.Migrator().HasTable("region_totals") {
+		t.Errorf("expected the view to be dropped, got error %v", err)
+	}
+	var count int64
+	if err := db.Model(&MaterializedView{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected one definition left, got %d, error %v", count, err)
+	}
+}
+
+func TestMaterializedView_inlinedValues(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&matviewEvent{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	since := time.Date(2024, 1, 1, 9, 0, 0, 0, time.FixedZone("", 9*60*60))
+	if err := db.Create(&[]matviewEvent{
+		{1, []byte{0x00, 0xFF}, since.Add(-time.Hour)},
+		{2, []byte{0x00, 0xFF}, since.Add(time.Hour)},
+		{3, []byte("'"), since.Add(time.Hour)},
+	}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	query := db.Model(&matviewEvent{}).Select("id").Where("kind = ? AND occurred > ?", []byte{0x00, 0xFF}, since)
+	if err := CreateMaterializedView(db, "recent_events", query, RefreshPolicy{}); err != nil {
+		t.Fatalf("failed to create view, got error %v", err)
+	}
+	var definition MaterializedView
+	if err := db.First(&definition, "name = ?", "recent_events").Error; err != nil {
+		t.Fatalf("failed to find the definition, got error %v", err)
+	}
+	if !strings.Contains(definition.Query, `'\x00\xFF'::BLOB`) || !strings.Contains(definition.Query, "'2024-01-01 09:00:00+09:00'::TIMESTAMPTZ") {
+		t.Errorf("expected the values to be stored as literals, got %v", definition.Query)
+	}
+
+	if err := db.Create(&matviewEvent{4, []byte{0x00, 0xFF}, since.Add(2 * time.Hour)}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	if err := RefreshView(db, "recent_events"); err != nil {
+		t.Fatalf("failed to refresh, got error %v", err)
+	}
+	var ids []int
+	if err := db.Table("recent_events").Order("id").Pluck("id", &ids).Error; err != nil || len(ids) != 2 || ids[0] != 2 || ids[1] != 4 {
+		t.Errorf("expected the refreshed view to filter by the bound values, got %v, error %v", ids, err)
+	}
+}
@@ -0,0 +1,127 @@
+package duckdb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryUsage is the memory used by one component of DuckDB, as listed by
+// duckdb_memory()
+type MemoryUsage struct {
+	// Tag is the component, e.g. HASH_TABLE or ORDER_BY
+	Tag string
+	// MemoryBytes is the memory held by the buffer manager for the component
+	MemoryBytes int64 `gorm:"column:memory_usage_bytes"`
+	// TemporaryStorageBytes is the data of the component spilled to disk
+	TemporaryStorageBytes int64
+}
+
+// TemporaryFile is a file data is spilled to, as listed by
+// duckdb_temporary_files()
+type TemporaryFile struct {
+	Path string
+	Size int64
+}
+
+// MemoryStats is a snapshot of the memory and temporary storage of a database,
+// as returned by Memory
+type MemoryStats struct {
+	// Usage is the usage by component
+	Usage []MemoryUsage
+	// TemporaryFiles are the files spilled data is kept in
+	TemporaryFiles []TemporaryFile
+	// MemoryBytes and TemporaryStorageBytes are the totals of Usage
+	MemoryBytes           int64
+	TemporaryStorageBytes int64
+	// MemoryLimit is the memory_limit setting, e.g. 4.6 GiB
+	MemoryLimit string
+	// SampledAt is when the snapshot was taken
+	SampledAt time.Time
+}
+
+// Memory reports the memory and temporary storage used by the database, e.g. to
+// find what grows before DuckDB runs out of memory
+func Memory(db *gorm.DB) (*MemoryStats, error) {
+	tx := db.Session(&gorm.Session{NewDB: true})
+	stats := &MemoryStats{SampledAt: time.Now()}
+	if err := tx.Raw("SELECT tag, memory_usage_bytes, temporary_storage_bytes FROM duckdb_memory() ORDER BY tag").Find(&stats.Usage).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Raw("SELECT path, size FROM duckdb_temporary_files() ORDER BY path").Find(&stats.TemporaryFiles).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Raw("SELECT current_setting('memory_limit')").Row().Scan(&stats.MemoryLimit); err != nil {
+		return nil, err
+	}
+	for _, usage := range stats.Usage {
+		stats.MemoryBytes += usage.MemoryBytes
+		stats.TemporaryStorageBytes += usage.TemporaryStorageBytes
+	}
+	return stats, nil
+}
+
+// MemorySampler takes snapshots of Memory periodically until stopped
+type MemorySampler struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// SampleMemory starts taking a snapshot of Memory every interval, passed to fn
+// with the error of taking it. Without fn, snapshots are logged at info level
+// and errors at error level through the logger of db:
+//
+//	sampler, err := duckdb.SampleMemory(db, time.Minute, nil)
+//	if err != nil {
+//		...
+//	}
+//	defer sampler.Stop()
+//
+// It fails for intervals that are not positive.
+func SampleMemory(db *gorm.DB, interval time.Duration, fn func(stats *MemoryStats, err error)) (*MemorySampler, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid memory sampling interval %v, expected a positive duration", interval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &MemorySampler{cancel: cancel, done: make(chan struct{})}
+	if fn == nil {
+		fn = func(stats *MemoryStats, err error) {
+			if err != nil {
+				db.Logger.Error(ctx, "duckdb: failed to sample memory: %v", err)
+				return
+			}
+			db.Logger.Info(ctx, "duckdb: memory %d bytes of %s, temporary storage %d bytes in %d files",
+				stats.MemoryBytes, stats.MemoryLimit, stats.TemporaryStorageBytes, len(stats.TemporaryFiles))
+		}
+	}
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stats, err := Memory(db.WithContext(ctx))
+				if ctx.Err() != nil {
+					return
+				}
+				fn(stats, err)
+			}
+		}
+	}()
+	return s, nil
+}
+
+// Stop stops taking snapshots and waits for the one being taken
+func (s *MemorySampler) Stop() {
+	s.once.Do(s.cancel)
+	<-s.done
+}
@@ -0,0 +1,61 @@
+package duckdb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	db := openTestDB(t, Config{Settings: map[string]string{"memory_limit": "512MB"}})
+	if err := db.Exec("CREATE TABLE memory_records AS SELECT range AS id, repeat('x', 100) AS payload FROM range(100000)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	stats, err := Memory(db)
+	if err != nil {
+		t.Fatalf("failed to get memory, got error %v", err)
+	}
+	if len(stats.Usage) == 0 || stats.MemoryBytes <= 0 || stats.MemoryLimit != "488.2 MiB" {
+		t.Errorf("expected memory usage within the limit, got %+v", stats)
+	}
+	var total int64
+	for _, usage := range stats.Usage {
+		total += usage.MemoryBytes
+	}
+	if total != stats.MemoryBytes {
+		t.Errorf("expected the total of the usage by component, got %d and %d", total, stats.MemoryBytes)
+	}
+
+	samples := make(chan *MemoryStats, 1)
+	sampler, err := SampleMemory(db, 10*time.Millisecond, func(stats *MemoryStats, err error) {
+		if err != nil {
+			t.Errorf("failed to sample memory, got error %v", err)
+		}
+		select {
+		case samples <- stats:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to start sampling memory, got error %v", err)
+	}
+	select {
+	case stats := <-samples:
+		if stats.MemoryBytes <= 0 {
+			t.Errorf("expected a sample of the memory usage, got %+v", stats)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("expected a sample")
+	}
+	sampler.Stop()
+	sampler.Stop()
+}
+
+func TestSampleMemory_invalidInterval(t *testing.T) {
+	db := openTestDB(t, Config{})
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if sampler, err := SampleMemory(db, interval, nil); err == nil || sampler != nil {
+			t.Errorf("expected an interval of %v to fail, got %v", interval, err)
+		}
+	}
+}
@@ -2,9 +2,12 @@ package duckdb
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -29,13 +32,19 @@ FROM pragma_index_info(?)
 
 var typeAliasMap = map[string][]string{
 	"int":      {"integer"},
-	"integer":  {"int"},
+	"integer":  {"int", "int4", "signed"},
+	"tinyint":  {"int1"},
+	"smallint": {"int2", "short"},
+	"bigint":   {"int8", "long"},
 	"bool":     {"boolean"},
 	"boolean":  {"bool"},
 	"varchar":  {"string", "text"},
 	"double":   {"float", "real"},
 	"blob":     {"binary"},
+	"bit":      {"bitstring"},
 	"datetime": {"timestamp"},
+	// DuckDB reports TIMESTAMPTZ columns by the standard name
+	"timestamp with time zone": {"timestamptz"},
 }
 
 type Migrator struct {
@@ -52,9 +61,19 @@ func (m Migrator) queryRaw(sql string, values ...interface{}) (tx *gorm.DB) {
 	return queryTx.Raw(sql, values...)
 }
 
+// CurrentDatabase returns the catalog of the table of the Migrator when it is
+// qualified by one, e.g. with db.Table("archive.orders"), and the current
+// catalog otherwise, see CurrentCatalog
 func (m Migrator) CurrentDatabase() (name string) {
-	m.queryRaw("SELECT CURRENT_DATABASE()").Scan(&name)
-	return
+	if stmt := m.DB.Statement; stmt.Table != "" {
+		catalog, _, _ := m.qualifiedTable(stmt, stmt.Table)
+		if name, ok := catalog.(string); ok {
+			return name
+		}
+	}
+	var catalog Database
+	m.queryRaw(currentCatalogSQL).Scan(&catalog)
+	return catalog.Name
 }
 
 func (m Migrator) BuildIndexOptions(opts []schema.IndexOption, stmt *gorm.Statement) (results []interface{}) {
@@ -79,24 +98,43 @@ func (m Migrator) BuildIndexOptions(opts []schema.IndexOption, stmt *gorm.Statem
 func (m Migrator) HasIndex(value interface{}, name string) bool {
 	var count int64
 	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		name = unquoteIdentifier(name)
 		if stmt.Schema != nil {
 			if idx := stmt.Schema.LookIndex(name); idx != nil {
 				name = idx.Name
 			}
 		}
+		catalog, currentSchema, curTable := m.qualifiedTable(stmt, stmt.Table)
 		return m.queryRaw(
-			"SELECT COUNT(*) FROM pragma_index_list(?) WHERE name = ?",
-			m.CurrentTable(stmt), name,
+			"SELECT COUNT(*) FROM duckdb_indexes() WHERE database_name = ? AND "+
+				identifierMatches("schema_name")+" AND "+identifierMatches("table_name")+" AND "+identifierMatches("index_name"),
+			catalog, currentSchema, curTable, name,
 		).Scan(&count).Error
 	})
 
 	return count > 0
 }
 
+// postgresIndexTypes are index methods of PostgreSQL that DuckDB does not
+// know, dropped from CreateIndex so that models written for PostgreSQL migrate
+var postgresIndexTypes = map[string]bool{"btree": true, "hash": true, "gin": true, "gist": true, "spgist": true, "brin": true}
+
+var indexSortPattern = regexp.MustCompile(`(?i)^(ASC|DESC)(\s+NULLS\s+(FIRST|LAST))?$|^NULLS\s+(FIRST|LAST)$`)
+
 func (m Migrator) CreateIndex(value interface{}, name string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
 		if stmt.Schema != nil {
 			if idx := stmt.Schema.LookIndex(name); idx != nil {
+				for _, field := range idx.Fields {
+					if field.Sort != "" && !indexSortPattern.MatchString(strings.TrimSpace(field.Sort)) {
+						return fmt.Errorf("invalid sort %q of index %s", field.Sort, idx.Name)
+					}
+				}
+				// DuckDB has no partial indexes, a unique one cannot be emulated
+				if idx.Where != "" && strings.EqualFold(idx.Class, "UNIQUE") {
+					return fmt.Errorf("partial unique index %s is not supported by DuckDB", idx.Name)
+				}
+
 				opts := m.BuildIndexOptions(idx.Fields, stmt)
 				values := []interface{}{clause.Column{Name: idx.Name}, m.CurrentTable(stmt), opts}
 
@@ -104,26 +142,23 @@ func (m Migrator) CreateIndex(value interface{}, name string) error {
 				if idx.Class != "" {
 					createIndexSQL += idx.Class + " "
 				}
-				createIndexSQL += "INDEX "
-
-				if strings.TrimSpace(strings.ToUpper(idx.Option)) == "CONCURRENTLY" {
-					createIndexSQL += "CONCURRENTLY "
-				}
-
-				createIndexSQL += "IF NOT EXISTS ? ON ?"
+				createIndexSQL += "INDEX IF NOT EXISTS ? ON ?"
 
-				if idx.Type != "" {
-					createIndexSQL += " USING " + idx.Type + "(?)"
-				} else {
-					createIndexSQL += " ?"
+				if idx.Type != "" && postgresIndexTypes[strings.ToLower(idx.Type)] {
+					m.DB.Logger.Warn(stmt.Context, "duckdb: dropped unsupported index type %s of index %s", idx.Type, idx.Name)
+				} else if idx.Type != "" {
+					createIndexSQL += " USING " + idx.Type
 				}
+				createIndexSQL += " ?"
 
-				if idx.Option != "" && strings.TrimSpace(strings.ToUpper(idx.Option)) != "CONCURRENTLY" {
-					createIndexSQL += " " + idx.Option
+				if option := strings.TrimSpace(idx.Option); strings.EqualFold(option, "CONCURRENTLY") {
+					m.DB.Logger.Warn(stmt.Context, "duckdb: dropped unsupported option %s of index %s", option, idx.Name)
+				} else if option != "" {
+					createIndexSQL += " " + option
 				}
 
 				if idx.Where != "" {
-					createIndexSQL += " WHERE " + idx.Where
+					m.DB.Logger.Warn(stmt.Context, "duckdb: dropped unsupported condition WHERE %s of index %s", idx.Where, idx.Name)
 				}
 
 				return m.DB.Exec(createIndexSQL, values...).Error
@@ -155,37 +190,85 @@ func (m Migrator) DropIndex(value interface{}, name string) error {
 	})
 }
 
+// GetTables returns the tables of the current schema, leaving out internal and
+// temporary tables
 func (m Migrator) GetTables() (tableList []string, err error) {
-	return tableList, m.queryRaw("SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'").Scan(&tableList).Error
+	return tableList, m.queryRaw(
+		"SELECT table_name FROM duckdb_tables() WHERE database_name = current_database() AND schema_name = current_schema() AND NOT internal AND NOT temporary ORDER BY table_name",
+	).Scan(&tableList).Error
 }
 
-func (m Migrator) CreateTable(values ...interface{}) (err error) {
-	// First create tables without sequences
-	if err = m.Migrator.CreateTable(values...); err != nil {
-		return
+// AutoMigrate migrates the models one by one when MigrationHooks.Progress is
+// set, so that the progress can be reported after each of them
+func (m Migrator) AutoMigrate(values ...interface{}) error {
+	progress := m.hooks().Progress
+	if progress == nil {
+		return m.Migrator.AutoMigrate(values...)
+	}
+
+	models := m.ReorderModels(values, true)
+	for i, value := range models {
+		start := time.Now()
+		err := m.Migrator.AutoMigrate(value)
+
+		report := MigrationProgress{Step: i + 1, Pending: len(models) - i - 1, Duration: time.Since(start), Error: err}
+		m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			report.Table = stmt.Table
+			if stmt.Schema != nil {
+				report.Model = stmt.Schema.Name
+			}
+			return nil
+		})
+		progress(report)
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Then add sequences and update default values
+func (m Migrator) CreateTable(values ...interface{}) (err error) {
+	hooks := m.hooks()
 	for _, value := range m.ReorderModels(values, false) {
+		if hooks.BeforeCreateTable != nil {
+			if err = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+				return hooks.BeforeCreateTable(m.DB, stmt)
+			}); err != nil {
+				return
+			}
+		}
+
+		// First create the sequences of auto-increment fields, which the
+		// columns of the table default to
+		if err = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			for _, field := range autoIncrementFields(stmt) {
+				if err := m.DB.Exec("CREATE SEQUENCE IF NOT EXISTS " + m.sequenceName(stmt, field) + " START 1").Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return
+		}
+
+		if err = m.createTable(value); err != nil {
+			return
+		}
+
+		// Then add comments
 		if err = m.RunWithValue(value, func(stmt *gorm.Statement) error {
 			if stmt.Schema != nil {
 				for _, field := range stmt.Schema.Fields {
-					if field.Name == "ID" && field.AutoIncrement {
-						tableName := stmt.Table
-						seqName := tableName + "_seq"
-						
-						// Create sequence
-						if err := m.DB.Exec("CREATE SEQUENCE IF NOT EXISTS " + seqName + " START 1").Error; err != nil {
-							return err
-						}
-
-						// Alter column to use sequence
-						if err := m.DB.Exec("ALTER TABLE " + tableName + " ALTER COLUMN " + field.DBName + " SET DEFAULT nextval('" + seqName + "')").Error; err != nil {
+					if field.Comment != "" && field.DBName != "" && m.supports(FeatureCommentOn) {
+						if err := m.commentOnColumn(stmt, field); err != nil {
 							return err
 						}
 					}
 				}
 			}
+			if hooks.AfterCreateTable != nil {
+				return hooks.AfterCreateTable(m.DB, stmt)
+			}
 			return nil
 		}); err != nil {
 			return
@@ -195,25 +278,138 @@ func (m Migrator) CreateTable(values ...interface{}) (err error) {
 	return nil
 }
 
+// createTable creates the table of value like the generic migrator, with
+// only the foreign keys DuckDB can create and the indexes created afterwards
+func (m Migrator) createTable(value interface{}) error {
+	tx := m.DB.Session(&gorm.Session{})
+	return m.RunWithValue(value, func(stmt *gorm.Statement) (err error) {
+		if stmt.Schema == nil {
+			return errors.New("failed to get schema")
+		}
+
+		var (
+			createTableSQL          = "CREATE TABLE ? ("
+			values                  = []interface{}{m.CurrentTable(stmt)}
+			hasPrimaryKeyInDataType bool
+		)
+
+		for _, dbName := range stmt.Schema.DBNames {
+			field := stmt.Schema.FieldsByDBName[dbName]
+			if !field.IgnoreMigration {
+				createTableSQL += "? ?,"
+				hasPrimaryKeyInDataType = hasPrimaryKeyInDataType || strings.Contains(strings.ToUpper(m.DataTypeOf(field)), "PRIMARY KEY")
+				dataType, err := m.fullDataTypeOf(tx, stmt, field)
+				if err != nil {
+					return err
+				}
+				if isAutoIncrement(field) {
+					dataType.SQL += " DEFAULT nextval(" + quoteString(m.sequenceName(stmt, field)) + ")"
+				}
+				values = append(values, clause.Column{Name: dbName}, dataType)
+			}
+		}
+
+		if !hasPrimaryKeyInDataType && len(stmt.Schema.PrimaryFields) > 0 {
+			createTableSQL += "PRIMARY KEY ?,"
+			primaryKeys := make([]interface{}, 0, len(stmt.Schema.PrimaryFields))
+			for _, field := range stmt.Schema.PrimaryFields {
+				primaryKeys = append(primaryKeys, clause.Column{Name: field.DBName})
+			}
+			values = append(values, primaryKeys)
+		}
+
+		for _, idx := range stmt.Schema.ParseIndexes() {
+			defer func(name string) {
+				if err == nil {
+					err = tx.Migrator().CreateIndex(value, name)
+				}
+			}(idx.Name)
+		}
+
+		for _, constraint := range m.foreignKeys(stmt) {
+			sql, vars := constraint.Build()
+			createTableSQL += sql + ","
+			values = append(values, vars...)
+		}
+
+		for _, uni := range stmt.Schema.ParseUniqueConstraints() {
+			createTableSQL += "CONSTRAINT ? UNIQUE (?),"
+			values = append(values, clause.Column{Name: uni.Name}, clause.Expr{SQL: stmt.Quote(uni.Field.DBName)})
+		}
+
+		for _, chk := range stmt.Schema.ParseCheckConstraints() {
+			createTableSQL += "CONSTRAINT ? CHECK (?),"
+			values = append(values, clause.Column{Name: chk.Name}, clause.Expr{SQL: chk.Constraint})
+		}
+
+		createTableSQL = strings.TrimSuffix(createTableSQL, ",") + ")"
+		if tableOption, ok := m.DB.Get("gorm:table_options"); ok {
+			createTableSQL += fmt.Sprint(tableOption)
+		}
+		return tx.Exec(createTableSQL, values...).Error
+	})
+}
+
 func (m Migrator) HasTable(value interface{}) bool {
 	var count int64
 	m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		currentDatabase := m.DB.Migrator().CurrentDatabase()
-		return m.DB.Raw(
-			"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = ? AND table_name = ?",
-			currentDatabase, stmt.Table,
+		catalog, currentSchema, curTable := m.qualifiedTable(stmt, stmt.Table)
+		return m.queryRaw(
+			"SELECT COUNT(*) FROM information_schema.tables WHERE table_catalog = ? AND "+
+				identifierMatches("table_schema")+" AND "+identifierMatches("table_name"),
+			catalog, currentSchema, curTable,
 		).Scan(&count).Error
 	})
 
 	return count > 0
 }
 
+// DropTable drops the tables of values with their sequences and ENUM types.
+// The join tables of their many2many relationships are dropped too when the
+// tables they join are all dropped or missing; a join table shared with a table
+// that is kept still references the dropped table, which then fails to drop
+// until the join table is dropped itself.
 func (m Migrator) DropTable(values ...interface{}) error {
 	values = m.ReorderModels(values, false)
 	tx := m.DB.Session(&gorm.Session{})
+	dropped := make(map[string]bool, len(values))
+	for _, value := range values {
+		m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			dropped[stmt.Table] = true
+			return nil
+		})
+	}
+	for _, value := range values {
+		if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			return m.dropJoinTables(tx, stmt, dropped)
+		}); err != nil {
+			return err
+		}
+	}
 	for i := len(values) - 1; i >= 0; i-- {
 		if err := m.RunWithValue(values[i], func(stmt *gorm.Statement) error {
-			return tx.Exec("DROP TABLE IF EXISTS ? CASCADE", m.CurrentTable(stmt)).Error
+			sequences, err := m.columnSequences(stmt)
+			if err != nil {
+				return err
+			}
+			enumTypes, err := m.tableEnumTypes(stmt)
+			if err != nil {
+				return err
+			}
+			if err := tx.Exec("DROP TABLE IF EXISTS ? CASCADE", m.CurrentTable(stmt)).Error; err != nil {
+				return err
+			}
+			for _, name := range sequences {
+				if err := m.dropSequence(tx, stmt, name); err != nil {
+					return err
+				}
+			}
+			for _, name := range enumTypes {
+				if err := tx.Exec("DROP TYPE IF EXISTS " + name).Error; err != nil {
+					return err
+				}
+			}
+			return nil
 		}); err != nil {
 			return err
 		}
@@ -222,7 +418,24 @@ func (m Migrator) DropTable(values ...interface{}) error {
 }
 
 func (m Migrator) AddColumn(value interface{}, field string) error {
-	if err := m.Migrator.AddColumn(value, field); err != nil {
+	// columns of ENUM fields get the type of the field, created first
+	if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if stmt.Schema != nil {
+			if f := stmt.Schema.LookUpField(field); f != nil && !f.IgnoreMigration {
+				if _, ok := generatedExpression(f); ok {
+					return fmt.Errorf("failed to add generated column %q: DuckDB only creates generated columns with their table", f.DBName)
+				}
+				if _, ok := parseEnumType(m.DataTypeOf(f)); ok {
+					dataType, err := m.fullDataTypeOf(m.DB, stmt, f)
+					if err != nil {
+						return err
+					}
+					return m.DB.Exec("ALTER TABLE ? ADD ? ?", m.CurrentTable(stmt), clause.Column{Name: f.DBName}, dataType).Error
+				}
+			}
+		}
+		return m.Migrator.AddColumn(value, field)
+	}); err != nil {
 		return err
 	}
 	m.resetPreparedStmts()
@@ -230,11 +443,8 @@ func (m Migrator) AddColumn(value interface{}, field string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
 		if stmt.Schema != nil {
 			if field := stmt.Schema.LookUpField(field); field != nil {
-				if field.Comment != "" {
-					if err := m.DB.Exec(
-						"COMMENT ON COLUMN ?.? IS ?",
-						m.CurrentTable(stmt), clause.Column{Name: field.DBName}, gorm.Expr(m.Migrator.Dialector.Explain("$1", field.Comment)),
-					).Error; err != nil {
+				if field.Comment != "" && m.supports(FeatureCommentOn) {
+					if err := m.commentOnColumn(stmt, field); err != nil {
 						return err
 					}
 				}
@@ -244,26 +454,112 @@ func (m Migrator) AddColumn(value interface{}, field string) error {
 	})
 }
 
+func (m Migrator) commentOnColumn(stmt *gorm.Statement, field *schema.Field) error {
+	return m.DB.Exec(
+		"COMMENT ON COLUMN ?.? IS ?",
+		m.CurrentTable(stmt), clause.Column{Name: field.DBName}, clause.Expr{SQL: quoteString(field.Comment)},
+	).Error
+}
+
+// supports reports whether the connected DuckDB version supports feature
+func (m Migrator) supports(feature Feature) bool {
+	if dialector, ok := m.Dialector.(Dialector); ok {
+		return dialector.Supports(feature)
+	}
+	return true
+}
+
+// dialector returns the dialector of the Migrator
+func (m Migrator) dialector() Dialector {
+	dialector, _ := m.Dialector.(Dialector)
+	return dialector
+}
+
 func (m Migrator) HasColumn(value interface{}, field string) bool {
 	var count int64
 	m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		name := field
+		name := unquoteIdentifier(field)
 		if stmt.Schema != nil {
-			if field := stmt.Schema.LookUpField(field); field != nil {
+			if field := stmt.Schema.LookUpField(name); field != nil {
 				name = field.DBName
 			}
 		}
 
 		return m.queryRaw(
-			"SELECT count(*) FROM pragma_table_info(?) WHERE name = ?",
-			m.CurrentTable(stmt), name,
+			"SELECT count(*) FROM pragma_table_info(?) WHERE "+identifierMatches("name"),
+			m.tableInfoName(stmt), name,
 		).Scan(&count).Error
 	})
 
 	return count > 0
 }
 
+// integerType is the size of an integer type in bits and whether it is signed
+type integerType struct {
+	bits   int
+	signed bool
+}
+
+// integerTypes are the integer types of DuckDB
+var integerTypes = map[string]integerType{
+	"TINYINT": {8, true}, "SMALLINT": {16, true}, "INTEGER": {32, true}, "BIGINT": {64, true}, "HUGEINT": {128, true},
+	"UTINYINT": {8, false}, "USMALLINT": {16, false}, "UINTEGER": {32, false}, "UBIGINT": {64, false}, "UHUGEINT": {128, false},
+}
+
+// widensInteger reports whether the integer type to holds every value of the
+// integer type from and more
+func widensInteger(from, to string) bool {
+	f, t := integerTypes[from], integerTypes[to]
+	return t.bits > f.bits && (t.signed || !f.signed)
+}
+
+// keptColumnType reports the type of a column as the field's, so that gorm
+// does not alter columns whose type is kept
+type keptColumnType struct {
+	columnType
+	name string
+}
+
+// columnType names the embedded gorm.ColumnType apart from its ColumnType method
+type columnType = gorm.ColumnType
+
+func (c keptColumnType) DatabaseTypeName() string {
+	return c.name
+}
+
+// MigrateColumn alters the column of field when its type, collation, sequence
+// or constraints changed. ENUM columns whose members did not change are kept.
+// Integer columns are widened to the type of the field when it holds more
+// values, which fails for key and indexed columns as DuckDB cannot change
+// their type, and kept otherwise; AlterColumn converts them to the type of
+// the field. Generated columns are left as they are.
 func (m Migrator) MigrateColumn(value interface{}, field *schema.Field, columnType gorm.ColumnType) error {
+	if _, ok := generatedExpression(field); ok {
+		return nil
+	}
+	if same, isEnum := m.sameEnumType(field, columnType); isEnum && !same {
+		return m.AlterColumn(value, field.DBName)
+	} else if isEnum {
+		columnType = keptColumnType{columnType: columnType, name: m.DataTypeOf(field)}
+	} else if dataType, current := m.DataTypeOf(field), strings.ToUpper(columnType.DatabaseTypeName()); current != dataType && integerTypes[current].bits > 0 && integerTypes[dataType].bits > 0 {
+		if widensInteger(current, dataType) {
+			if err := m.AlterColumn(value, field.DBName); err != nil {
+				return fmt.Errorf("failed to widen column %q from %s to %s: %w", field.DBName, current, dataType, err)
+			}
+			return nil
+		}
+		columnType = keptColumnType{columnType: columnType, name: dataType}
+	}
+	if field.DataType == schema.String {
+		if same, err := m.sameCollation(value, field); err != nil {
+			return err
+		} else if !same {
+			return m.AlterColumn(value, field.DBName)
+		}
+	}
+	if autoIncrement, ok := columnType.AutoIncrement(); ok && autoIncrement != isSequenceBacked(field) {
+		return m.AlterColumn(value, field.DBName)
+	}
 	if !field.PrimaryKey {
 		if err := m.Migrator.MigrateColumn(value, field, columnType); err != nil {
 			return err
@@ -275,9 +571,16 @@ func (m Migrator) MigrateColumn(value interface{}, field *schema.Field, columnTy
 
 // AlterColumn alter value's `field` column' type based on schema definition
 func (m Migrator) AlterColumn(value interface{}, field string) error {
+	hooks := m.hooks()
 	err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
 		if stmt.Schema != nil {
 			if field := stmt.Schema.LookUpField(field); field != nil {
+				if hooks.BeforeAlterColumn != nil {
+					if err := hooks.BeforeAlterColumn(m.DB, stmt, field); err != nil {
+						return err
+					}
+				}
+
 				var (
 					columnTypes, _  = m.DB.Migrator().ColumnTypes(value)
 					fieldColumnType *migrator.ColumnType
@@ -296,39 +599,47 @@ func (m Migrator) AlterColumn(value interface{}, field string) error {
 					// if different, also check for aliases
 					aliases := m.GetTypeAliases(fieldColumnType.DatabaseTypeName())
 					for _, alias := range aliases {
-						if strings.HasPrefix(fileType.SQL, alias) {
+						if strings.HasPrefix(strings.ToLower(fileType.SQL), strings.ToLower(alias)) {
 							isSameType = true
 							break
 						}
 					}
 				}
 
+				if same, isEnum := m.sameEnumType(field, fieldColumnType); isEnum {
+					isSameType = same
+				}
+				if collation, err := m.columnCollation(stmt, field.DBName); err != nil {
+					return err
+				} else if !strings.EqualFold(collation, collationOf(field)) {
+					isSameType = false
+				}
+
 				// not same, migrate
 				if !isSameType {
-					filedColumnAutoIncrement, _ := fieldColumnType.AutoIncrement()
-					if field.AutoIncrement && filedColumnAutoIncrement { // update
-						serialDatabaseType, _ := getSerialDatabaseType(fileType.SQL)
-						if t, _ := fieldColumnType.ColumnType(); t != serialDatabaseType {
-							if err := m.UpdateSequence(m.DB, stmt, field, serialDatabaseType); err != nil {
-								return err
-							}
-						}
-					} else if field.AutoIncrement && !filedColumnAutoIncrement { // create
-						serialDatabaseType, _ := getSerialDatabaseType(fileType.SQL)
-						if err := m.CreateSequence(m.DB, stmt, field, serialDatabaseType); err != nil {
-							return err
-						}
-					} else if !field.AutoIncrement && filedColumnAutoIncrement { // delete
-						if err := m.DeleteSequence(m.DB, stmt, field, fileType); err != nil {
-							return err
-						}
-					} else {
-						if err := m.modifyColumn(stmt, field, fileType, fieldColumnType); err != nil {
-							return err
-						}
+					if name, ok, err := m.enumTypeOf(m.DB, stmt, field); err != nil {
+						return err
+					} else if ok {
+						fileType.SQL = name
+					}
+					if err := m.modifyColumn(stmt, field, fileType, fieldColumnType); err != nil {
+						return err
 					}
 				}
 
+				columnAutoIncrement, _ := fieldColumnType.AutoIncrement()
+				if isAutoIncrement(field) && !columnAutoIncrement { // create
+					if err := m.CreateSequence(m.DB, stmt, field, fileType.SQL); err != nil {
+						return err
+					}
+					fieldColumnType.DefaultValueValue = sql.NullString{}
+				} else if !isSequenceBacked(field) && columnAutoIncrement { // delete
+					if err := m.DeleteSequence(m.DB, stmt, field, fileType); err != nil {
+						return err
+					}
+					fieldColumnType.DefaultValueValue = sql.NullString{}
+				}
+
 				if null, _ := fieldColumnType.Nullable(); null == field.NotNull {
 					if field.NotNull {
 						if err := m.DB.Exec("ALTER TABLE ? ALTER COLUMN ? SET NOT NULL", m.CurrentTable(stmt), clause.Column{Name: field.DBName}).Error; err != nil {
@@ -344,11 +655,13 @@ func (m Migrator) AlterColumn(value interface{}, field string) error {
 				if v, ok := fieldColumnType.DefaultValue(); (field.DefaultValueInterface == nil && ok) || v != field.DefaultValue {
 					if field.HasDefaultValue && (field.DefaultValueInterface != nil || field.DefaultValue != "") {
 						if field.DefaultValueInterface != nil {
-							defaultStmt := &gorm.Statement{Vars: []interface{}{field.DefaultValueInterface}}
-							m.Dialector.BindVarTo(defaultStmt, defaultStmt, field.DefaultValueInterface)
+							defaultValue, err := sqlLiteral(field.DefaultValueInterface)
+							if err != nil {
+								return err
+							}
 							if err := m.DB.Exec(
 								"ALTER TABLE ? ALTER COLUMN ? SET DEFAULT ?",
-								m.CurrentTable(stmt), clause.Column{Name: field.DBName}, clause.Expr{SQL: m.Dialector.Explain(defaultStmt.SQL.String(), field.DefaultValueInterface)},
+								m.CurrentTable(stmt), clause.Column{Name: field.DBName}, clause.Expr{SQL: defaultValue},
 							).Error; err != nil {
 								return err
 							}
@@ -369,6 +682,9 @@ func (m Migrator) AlterColumn(value interface{}, field string) error {
 						}
 					}
 				}
+				if hooks.AfterAlterColumn != nil {
+					return hooks.AfterAlterColumn(m.DB, stmt, field)
+				}
 				return nil
 			}
 		}
@@ -383,7 +699,7 @@ func (m Migrator) AlterColumn(value interface{}, field string) error {
 }
 
 func (m Migrator) modifyColumn(stmt *gorm.Statement, field *schema.Field, targetType clause.Expr, existingColumn *migrator.ColumnType) error {
-	alterSQL := "ALTER TABLE ? ALTER COLUMN ? TYPE ? USING ?::?"
+	alterSQL := "ALTER TABLE ? ALTER COLUMN ? TYPE ?" + collateClause(field) + " USING ?::?"
 	isUncastableDefaultValue := false
 
 	if targetType.SQL == "boolean" {
@@ -408,28 +724,45 @@ func (m Migrator) modifyColumn(stmt *gorm.Statement, field *schema.Field, target
 func (m Migrator) HasConstraint(value interface{}, name string) bool {
 	var count int64
 	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		name = unquoteIdentifier(name)
 		constraint, table := m.GuessConstraintInterfaceAndTable(stmt, name)
+		if fk, ok := constraint.(*schema.Constraint); ok {
+			if m.hasForeignKey(stmt, table, fk) {
+				count = 1
+			}
+			return nil
+		}
 		if constraint != nil {
 			name = constraint.GetName()
 		}
-		currentSchema, curTable := m.CurrentSchema(stmt, table)
+		catalog, currentSchema, curTable := m.qualifiedTable(stmt, table)
 
 		return m.queryRaw(
-			"SELECT count(*) FROM INFORMATION_SCHEMA.table_constraints WHERE table_schema = ? AND table_name = ? AND constraint_name = ?",
-			currentSchema, curTable, name,
+			"SELECT count(*) FROM INFORMATION_SCHEMA.table_constraints WHERE table_catalog = ? AND "+
+				identifierMatches("table_schema")+" AND "+identifierMatches("table_name")+" AND "+identifierMatches("constraint_name"),
+			catalog, currentSchema, curTable, name,
 		).Scan(&count).Error
 	})
 
 	return count > 0
 }
 
+// decimalTypePattern matches the DECIMAL types DuckDB reports, capturing their
+// precision and scale
+var decimalTypePattern = regexp.MustCompile(`^DECIMAL\((\d+),\s*(\d+)\)$`)
+
 func (m Migrator) ColumnTypes(value interface{}) (columnTypes []gorm.ColumnType, err error) {
 	columnTypes = make([]gorm.ColumnType, 0)
 	err = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		enumTypes, err := m.enumTypes()
+		if err != nil {
+			return err
+		}
+
 		var columns *sql.Rows
 		columns, err = m.queryRaw(
-			"SELECT name, type, notnull, dflt_value FROM pragma_table_info(?)",
-			m.CurrentTable(stmt)).Rows()
+			`SELECT name, type, "notnull", dflt_value FROM pragma_table_info(?)`,
+			m.tableInfoName(stmt)).Rows()
 
 		if err != nil {
 			return err
@@ -447,9 +780,14 @@ func (m Migrator) ColumnTypes(value interface{}) (columnTypes []gorm.ColumnType,
 				return err
 			}
 
+			dataType := typeName
+			if values, ok := parseEnumType(typeName); ok {
+				dataType = enumTypeName(enumTypes, values)
+			}
+
 			column := &migrator.ColumnType{
 				NameValue:         sql.NullString{String: name, Valid: true},
-				DataTypeValue:     sql.NullString{String: typeName, Valid: true},
+				DataTypeValue:     sql.NullString{String: dataType, Valid: true},
 				ColumnTypeValue:   sql.NullString{String: typeName, Valid: true},
 				NullableValue:     sql.NullBool{Bool: !notNull, Valid: true},
 				DefaultValueValue: defaultValue,
@@ -457,12 +795,29 @@ func (m Migrator) ColumnTypes(value interface{}) (columnTypes []gorm.ColumnType,
 				UniqueValue:       sql.NullBool{Valid: true},
 			}
 
+			if matches := decimalTypePattern.FindStringSubmatch(typeName); matches != nil {
+				precision, _ := strconv.ParseInt(matches[1], 10, 64)
+				scale, _ := strconv.ParseInt(matches[2], 10, 64)
+				column.DecimalSizeValue = sql.NullInt64{Int64: precision, Valid: true}
+				column.ScaleValue = sql.NullInt64{Int64: scale, Valid: true}
+			}
+
 			columnTypes = append(columnTypes, column)
 		}
 		columns.Close()
 
+		sequences, err := m.columnSequences(stmt)
+		if err != nil {
+			return err
+		}
+		for _, c := range columnTypes {
+			mc := c.(*migrator.ColumnType)
+			_, autoIncrement := sequences[mc.NameValue.String]
+			mc.AutoIncrementValue = sql.NullBool{Bool: autoIncrement, Valid: true}
+		}
+
 		// Get primary key and unique constraints
-		pkRows, err := m.queryRaw("SELECT name FROM pragma_table_info(?) WHERE pk > 0", m.CurrentTable(stmt)).Rows()
+		pkRows, err := m.queryRaw("SELECT name FROM pragma_table_info(?) WHERE pk > 0", m.tableInfoName(stmt)).Rows()
 		if err != nil {
 			return err
 		}
@@ -482,7 +837,7 @@ func (m Migrator) ColumnTypes(value interface{}) (columnTypes []gorm.ColumnType,
 		pkRows.Close()
 
 		// assign sql column type using current connection
-		rows, err := m.DB.Session(&gorm.Session{}).Table(stmt.Table).Limit(1).Rows()
+		rows, err := m.DB.Session(&gorm.Session{}).Raw("SELECT * FROM " + m.tableInfoName(stmt) + " LIMIT 1").Rows()
 		if err != nil {
 			return err
 		}
@@ -516,65 +871,69 @@ func (m Migrator) GetRows(currentSchema interface{}, table interface{}) (*sql.Ro
 	}).Rows()
 }
 
-func (m Migrator) CurrentSchema(stmt *gorm.Statement, table string) (interface{}, interface{}) {
-	if strings.Contains(table, ".") {
-		if tables := strings.Split(table, `.`); len(tables) == 2 {
-			return tables[0], tables[1]
-		}
-	}
+// tableInfoName returns the quoted name of the table of stmt, qualified by its
+// catalog and schema, for table functions such as pragma_table_info
+func (m Migrator) tableInfoName(stmt *gorm.Statement) string {
+	_, _, table := m.qualifiedTable(stmt, stmt.Table)
+	return m.schemaObjectName(stmt, table.(string))
+}
 
-	if stmt.TableExpr != nil {
-		if tables := strings.Split(stmt.TableExpr.SQL, `"."`); len(tables) == 2 {
-			return strings.TrimPrefix(tables[0], `"`), table
+// schemaObjectName returns the quoted name of the object name in the schema of
+// the table of stmt, such as the sequences and types of its columns
+func (m Migrator) schemaObjectName(stmt *gorm.Statement, object string) string {
+	catalog, currentSchema, _ := m.qualifiedTable(stmt, stmt.Table)
+	name := quoteIdentifier(object)
+	if currentSchema, ok := currentSchema.(string); ok {
+		name = quoteIdentifier(currentSchema) + "." + name
+		if catalog, ok := catalog.(string); ok {
+			name = quoteIdentifier(catalog) + "." + name
 		}
 	}
-	return clause.Expr{SQL: "CURRENT_SCHEMA()"}, table
-}
-
-func (m Migrator) CreateSequence(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field,
-	serialDatabaseType string) (err error) {
-	// DuckDBではAUTOINCREMENTを使用
-	return tx.Exec("ALTER TABLE ? MODIFY COLUMN ? ? AUTOINCREMENT",
-		m.CurrentTable(stmt), clause.Column{Name: field.DBName},
-		clause.Expr{SQL: serialDatabaseType}).Error
-}
-
-func (m Migrator) UpdateSequence(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field,
-	serialDatabaseType string) (err error) {
-	// DuckDBではAUTOINCREMENTの変更は新しいカラムの作成と古いカラムの削除が必要
-	return tx.Exec("ALTER TABLE ? MODIFY COLUMN ? ? AUTOINCREMENT",
-		m.CurrentTable(stmt), clause.Column{Name: field.DBName},
-		clause.Expr{SQL: serialDatabaseType}).Error
+	return name
 }
 
-func (m Migrator) DeleteSequence(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field,
-	fileType clause.Expr) (err error) {
-	// DuckDBではAUTOINCREMENTを通常のカラムに変更
-	return tx.Exec("ALTER TABLE ? MODIFY COLUMN ? ?",
-		m.CurrentTable(stmt), clause.Column{Name: field.DBName}, fileType).Error
+// CurrentSchema returns the schema and the name of table, unquoted
+func (m Migrator) CurrentSchema(stmt *gorm.Statement, table string) (interface{}, interface{}) {
+	_, currentSchema, name := m.qualifiedTable(stmt, table)
+	return currentSchema, name
 }
 
-func (m Migrator) getColumnSequenceName(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field) (
-	sequenceName string, err error) {
-	_, table := m.CurrentSchema(stmt, stmt.Table)
-
-	// DefaultValueValue is reset by ColumnTypes, search again.
-	var columnDefault string
-	err = tx.Raw(
-		`SELECT column_default FROM information_schema.columns WHERE table_name = ? AND column_name = ?`,
-		table, field.DBName).Scan(&columnDefault).Error
-
-	if err != nil {
-		return
+// qualifiedTable returns the catalog, schema and name of table, unquoted. A
+// name with two parts is in a schema of the current database or, when there is
+// no such schema, in the main schema of the attached database of that name.
+func (m Migrator) qualifiedTable(stmt *gorm.Statement, table string) (catalog, currentSchema, name interface{}) {
+	catalog, currentSchema, name = clause.Expr{SQL: "CURRENT_DATABASE()"}, clause.Expr{SQL: "CURRENT_SCHEMA()"}, unquoteIdentifier(table)
+	parts := splitIdentifier(table)
+	if len(parts) == 1 && stmt.TableExpr != nil {
+		if qualifier := splitIdentifier(stmt.TableExpr.SQL); len(qualifier) > 1 {
+			parts = append(qualifier[:len(qualifier)-1], name.(string))
+		}
 	}
 
-	sequenceName = strings.TrimSuffix(
-		strings.TrimPrefix(columnDefault, `nextval('`),
-		`'::regclass)`,
-	)
+	switch len(parts) {
+	case 3:
+		return parts[0], parts[1], parts[2]
+	case 2:
+		if m.isAttachedCatalog(parts[0]) {
+			return parts[0], "main", parts[1]
+		}
+		return catalog, parts[0], parts[1]
+	}
 	return
 }
 
+// isAttachedCatalog reports whether name is an attached database rather than a
+// schema of the current database
+func (m Migrator) isAttachedCatalog(name string) bool {
+	var attached bool
+	m.queryRaw(
+		"SELECT EXISTS (SELECT 1 FROM duckdb_databases() WHERE "+identifierMatches("database_name")+
+			") AND NOT EXISTS (SELECT 1 FROM duckdb_schemas() WHERE database_name = CURRENT_DATABASE() AND "+identifierMatches("schema_name")+")",
+		name, name,
+	).Scan(&attached)
+	return attached
+}
+
 func (m Migrator) GetIndexes(value interface{}) ([]gorm.Index, error) {
 	indexes := make([]gorm.Index, 0)
 
@@ -625,19 +984,15 @@ func groupByIndexName(indexList []*Index) map[string][]*Index {
 	return columnIndexMap
 }
 
-func getSerialDatabaseType(columnType string) (string, error) {
-	switch columnType {
-	case "smallserial", "serial2":
-		return "smallint", nil
-	case "serial", "serial4":
-		return "integer", nil
-	case "bigserial", "serial8":
-		return "bigint", nil
-	}
-	return "", fmt.Errorf("invalid serial type: %s", columnType)
-}
-
+// GetTypeAliases returns the other names of databaseTypeName, from
+// Config.TypeAliases before the built-in ones
 func (m Migrator) GetTypeAliases(databaseTypeName string) []string {
+	databaseTypeName = strings.ToLower(databaseTypeName)
+	if dialector, ok := m.Dialector.(Dialector); ok && dialector.Config != nil {
+		if aliases, ok := dialector.TypeAliases[databaseTypeName]; ok {
+			return aliases
+		}
+	}
 	return typeAliasMap[databaseTypeName]
 }
 
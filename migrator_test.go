@@ -1,6 +1,13 @@
 package duckdb
 
-import "testing"
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
 
 func Test_parseDefaultValueValue(t *testing.T) {
 	type args struct {
@@ -100,3 +107,491 @@ func Test_parseDefaultValueValue(t *testing.T) {
 		})
 	}
 }
+
+type indexRecord struct {
+	Name string `gorm:"index:idx_index_records_name"`
+}
+
+func TestMigrator_HasIndex(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&indexRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Exec("CREATE SCHEMA other; CREATE TABLE other.index_records (name VARCHAR); CREATE INDEX other_idx ON other.index_records (name)").Error; err != nil {
+		t.Fatalf("failed to create index, got error %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		index string
+		want  bool
+	}{
+		{name: "it should find indexes by field name", value: &indexRecord{}, index: "Name", want: true},
+		{name: "it should find indexes by name", value: &indexRecord{}, index: "idx_index_records_name", want: true},
+		{name: "it should compare names case-insensitively", value: &indexRecord{}, index: "IDX_Index_Records_Name", want: true},
+		{name: "it should not find indexes of other schemas", value: &indexRecord{}, index: "other_idx", want: false},
+		{name: "it should not find missing indexes", value: &indexRecord{}, index: "idx_missing", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := db.Migrator().HasIndex(tt.value, tt.index); got != tt.want {
+				t.Errorf("HasIndex(%q) = %v, want %v", tt.index, got, tt.want)
+			}
+		})
+	}
+}
+
+type mixedCaseRecord struct {
+	UserName string `gorm:"column:UserName"`
+}
+
+func (mixedCaseRecord) TableName() string {
+	return "MixedCase"
+}
+
+func TestMigrator_identifierCase(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec(`CREATE SCHEMA "Other"; CREATE TABLE "Other"."MixedCase" ("UserName" VARCHAR UNIQUE); CREATE INDEX "Idx_UserName" ON "Other"."MixedCase" ("UserName")`).Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	if err := db.AutoMigrate(&mixedCaseRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Exec(`CREATE INDEX "Idx_UserName" ON "MixedCase" ("UserName")`).Error; err != nil {
+		t.Fatalf("failed to create index, got error %v", err)
+	}
+
+	migrator := db.Migrator()
+	for _, table := range []string{"MixedCase", "mixedcase", `"MixedCase"`, `main."MixedCase"`, `Other.MIXEDCASE`, `"Other"."MixedCase"`} {
+		if !migrator.HasTable(table) {
+			t.Errorf("expected table %s to exist", table)
+		}
+		if !migrator.HasColumn(table, "username") || !migrator.HasColumn(table, `"UserName"`) {
+			t.Errorf("expected column UserName of %s to exist", table)
+		}
+		if !migrator.HasIndex(table, "idx_username") || !migrator.HasIndex(table, `"Idx_UserName"`) {
+			t.Errorf("expected index Idx_UserName of %s to exist", table)
+		}
+	}
+	if !migrator.HasConstraint(`"Other"."MixedCase"`, "mixedcase_username_key") {
+		t.Errorf("expected generated constraint names to match case-insensitively")
+	}
+	if migrator.HasTable(`"Mixed.Case"`) || migrator.HasTable("missing.MixedCase") || migrator.HasColumn(&mixedCaseRecord{}, "user_name") {
+		t.Errorf("expected missing identifiers not to be found")
+	}
+	if err := db.AutoMigrate(&mixedCaseRecord{}); err != nil {
+		t.Errorf("failed to migrate again, got error %v", err)
+	}
+	// lookups run in dry runs, like the other lookups of the migrator
+	if !db.Session(&gorm.Session{DryRun: true}).Migrator().HasTable(&mixedCaseRecord{}) {
+		t.Errorf("expected the table to be found in a dry run")
+	}
+}
+
+type postgresIndexRecord struct {
+	Name  string `gorm:"index:idx_pg_name,type:btree,option:CONCURRENTLY"`
+	Code  string `gorm:"index:idx_pg_code,where:code <> ''"`
+	Email string `gorm:"index:idx_pg_email,sort:desc nulls last"`
+}
+
+type invalidIndexRecord struct {
+	Name string `gorm:"index:idx_invalid_name,sort:desc nulls middle"`
+	Code string `gorm:"uniqueIndex:idx_invalid_code,where:code <> ''"`
+}
+
+func TestMigrator_CreateIndex(t *testing.T) {
+	recorder := &recordingLogger{Interface: logger.Discard}
+	db := openTestDB(t, Config{})
+	db = db.Session(&gorm.Session{Logger: recorder})
+
+	if err := db.AutoMigrate(&postgresIndexRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	for _, index := range []string{"idx_pg_name", "idx_pg_code", "idx_pg_email"} {
+		if !db.Migrator().HasIndex(&postgresIndexRecord{}, index) {
+			t.Errorf("expected index %s to be created", index)
+		}
+	}
+	warnings := recorder.warnings()
+	for _, want := range []string{"index type btree of index idx_pg_name", "option CONCURRENTLY of index idx_pg_name", "condition WHERE code <> '' of index idx_pg_code"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("expected warning about %q, got %q", want, warnings)
+		}
+	}
+
+	if err := db.AutoMigrate(&invalidIndexRecord{}); err == nil {
+		t.Errorf("expected invalid index options to be rejected")
+	}
+	for _, index := range []string{"idx_invalid_name", "idx_invalid_code"} {
+		if err := db.Migrator().CreateIndex(&invalidIndexRecord{}, index); err == nil {
+			t.Errorf("expected index %s to be rejected", index)
+		}
+	}
+}
+
+type typeAliasedRecord struct {
+	Code  string `gorm:"primaryKey"`
+	Total int64  `gorm:"type:int8"`
+}
+
+func TestMigrator_TypeAliases(t *testing.T) {
+	db := openTestDB(t, Config{TypeAliases: map[string][]string{"bigint": {"int8"}}})
+	if err := db.AutoMigrate(&typeAliasedRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&typeAliasedRecord{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected the aliased type not to be altered, got %v", recorder.sql)
+	}
+
+	if aliases := db.Migrator().GetTypeAliases("BOOLEAN"); len(aliases) != 1 || aliases[0] != "bool" {
+		t.Errorf("expected the built-in aliases to remain, got %v", aliases)
+	}
+}
+
+func TestMigrator_GetTables(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&indexRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE "Accounts" (id INTEGER); CREATE VIEW account_names AS SELECT 1;
+		CREATE SCHEMA other; CREATE TABLE other.others (id INTEGER); CREATE TEMP TABLE scratch (id INTEGER)`).Error; err != nil {
+		t.Fatalf("failed to create tables, got error %v", err)
+	}
+
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		t.Fatalf("failed to get tables, got error %v", err)
+	}
+	if want := []string{"Accounts", "index_records"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("expected tables %v, got %v", want, tables)
+	}
+}
+
+type sequenceRecord struct {
+	ID   uint
+	Name string `gorm:"index"`
+}
+
+type sequencePlain struct {
+	Code   string `gorm:"primaryKey"`
+	Number int
+}
+
+func (sequencePlain) TableName() string {
+	return "sequence_toggles"
+}
+
+type sequenceToggled struct {
+	Code   string `gorm:"primaryKey"`
+	Number int    `gorm:"autoIncrement"`
+}
+
+func (sequenceToggled) TableName() string {
+	return "sequence_toggles"
+}
+
+func TestMigrator_sequences(t *testing.T) {
+	db := openTestDB(t, Config{})
+	hasSequence := func(t *testing.T, name string) bool {
+		t.Helper()
+		var count int64
+		if err := db.Raw("SELECT count(*) FROM duckdb_sequences() WHERE sequence_name = ?", name).Scan(&count).Error; err != nil {
+			t.Fatalf("failed to list sequences, got error %v", err)
+		}
+		return count > 0
+	}
+	autoIncrement := func(t *testing.T, value interface{}, column string) bool {
+		t.Helper()
+		columnTypes, err := db.Migrator().ColumnTypes(value)
+		if err != nil {
+			t.Fatalf("failed to get column types, got error %v", err)
+		}
+		for _, columnType := range columnTypes {
+			if columnType.Name() == column {
+				autoIncrement, ok := columnType.AutoIncrement()
+				if !ok {
+					t.Errorf("expected the auto-increment of %s to be known", column)
+				}
+				return autoIncrement
+			}
+		}
+		t.Fatalf("column %s not found", column)
+		return false
+	}
+
+	if err := db.AutoMigrate(&sequenceRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	records := []sequenceRecord{{Name: "a"}, {Name: "b"}}
+	if err := db.Create(&records).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+	if records[0].ID != 1 || records[1].ID != 2 {
+		t.Errorf("expected keys 1 and 2, got %v and %v", records[0].ID, records[1].ID)
+	}
+	if !autoIncrement(t, &sequenceRecord{}, "id") || autoIncrement(t, &sequenceRecord{}, "name") {
+		t.Errorf("expected only id to be auto-increment")
+	}
+
+	t.Run("toggle", func(t *testing.T) {
+		if err := db.AutoMigrate(&sequencePlain{}); err != nil {
+			t.Fatalf("failed to migrate, got error %v", err)
+		}
+		if err := db.Create(&sequencePlain{Code: "a", Number: 5}).Error; err != nil {
+			t.Fatalf("failed to create record, got error %v", err)
+		}
+
+		if err := db.AutoMigrate(&sequenceToggled{}); err != nil {
+			t.Fatalf("failed to migrate to auto-increment, got error %v", err)
+		}
+		if !autoIncrement(t, &sequenceToggled{}, "number") {
+			t.Errorf("expected number to be auto-increment")
+		}
+		toggled := sequenceToggled{Code: "b"}
+		if err := db.Create(&toggled).Error; err != nil {
+			t.Fatalf("failed to create record, got error %v", err)
+		}
+		if toggled.Number != 6 {
+			t.Errorf("expected the sequence to continue after 5, got %v", toggled.Number)
+		}
+
+		if err := db.AutoMigrate(&sequencePlain{}); err != nil {
+			t.Fatalf("failed to migrate from auto-increment, got error %v", err)
+		}
+		if autoIncrement(t, &sequencePlain{}, "number") {
+			t.Errorf("expected number not to be auto-increment")
+		}
+		if hasSequence(t, "sequence_toggles_number_seq") {
+			t.Errorf("expected the sequence to be dropped")
+		}
+	})
+
+	t.Run("drop table", func(t *testing.T) {
+		if err := db.Exec("CREATE TABLE sequence_sharers (id INTEGER DEFAULT nextval('sequence_records_seq'))").Error; err != nil {
+			t.Fatalf("failed to create table, got error %v", err)
+		}
+		if err := db.Migrator().DropTable(&sequenceRecord{}); err != nil {
+			t.Fatalf("failed to drop table, got error %v", err)
+		}
+		if !hasSequence(t, "sequence_records_seq") {
+			t.Errorf("expected the sequence still in use to be kept")
+		}
+		if err := db.Migrator().DropTable("sequence_sharers"); err != nil {
+			t.Fatalf("failed to drop table, got error %v", err)
+		}
+		if hasSequence(t, "sequence_records_seq") {
+			t.Errorf("expected the sequence to be dropped with the table")
+		}
+	})
+}
+
+type decimalRecord struct {
+	Code   string  `gorm:"primaryKey"`
+	Amount float64 `gorm:"precision:18;scale:4"`
+	Ratio  float64
+}
+
+type decimalRescaled struct {
+	Code   string  `gorm:"primaryKey"`
+	Amount float64 `gorm:"precision:18;scale:2"`
+	Ratio  float64
+}
+
+func (decimalRescaled) TableName() string {
+	return "decimal_records"
+}
+
+func TestMigrator_decimal(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&decimalRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	decimalSize := func(t *testing.T, column string) (precision, scale int64, ok bool) {
+		t.Helper()
+		columnTypes, err := db.Migrator().ColumnTypes(&decimalRecord{})
+		if err != nil {
+			t.Fatalf("failed to get column types, got error %v", err)
+		}
+		for _, columnType := range columnTypes {
+			if columnType.Name() == column {
+				return columnType.DecimalSize()
+			}
+		}
+		t.Fatalf("column %s not found", column)
+		return
+	}
+	if precision, scale, ok := decimalSize(t, "amount"); !ok || precision != 18 || scale != 4 {
+		t.Errorf("expected DECIMAL(18,4), got %v, %v, %v", precision, scale, ok)
+	}
+	if _, _, ok := decimalSize(t, "ratio"); ok {
+		t.Errorf("expected no decimal size for DOUBLE columns")
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&decimalRecord{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected decimal columns not to be altered, got %v", recorder.sql)
+	}
+
+	if err := db.AutoMigrate(&decimalRescaled{}); err != nil {
+		t.Fatalf("failed to migrate the scale, got error %v", err)
+	}
+	if precision, scale, _ := decimalSize(t, "amount"); precision != 18 || scale != 2 {
+		t.Errorf("expected DECIMAL(18,2), got %v, %v", precision, scale)
+	}
+}
+
+type unsignedRecord struct {
+	ID     uint
+	Tiny   uint8
+	Small  uint16
+	Medium uint32
+	Large  uint64
+}
+
+func TestMigrator_unsignedIntegers(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE SEQUENCE unsigned_records_seq; CREATE TABLE unsigned_records (id INTEGER DEFAULT nextval('unsigned_records_seq') PRIMARY KEY, tiny INTEGER)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&unsignedRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if recorder.contains("ALTER COLUMN") {
+		t.Errorf("expected existing integer columns to be kept, got %v", recorder.sql)
+	}
+
+	var types []string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'unsigned_records' ORDER BY column_index").Scan(&types).Error; err != nil {
+		t.Fatalf("failed to query column types, got error %v", err)
+	}
+	if expected := []string{"INTEGER", "INTEGER", "USMALLINT", "UINTEGER", "UBIGINT"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected columns %v, got %v", expected, types)
+	}
+
+	record := unsignedRecord{ID: 1, Tiny: 255, Small: 65535, Medium: 4294967295, Large: 18446744073709551615}
+	if err := db.Create(&record).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	var found unsignedRecord
+	if err := db.First(&found, 1).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if found != record {
+		t.Errorf("expected %+v, got %+v", record, found)
+	}
+
+	if err := db.Migrator().AlterColumn(&unsignedRecord{}, "Tiny"); err != nil {
+		t.Fatalf("failed to alter column, got error %v", err)
+	}
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'unsigned_records' AND column_name = 'tiny'").Scan(&types).Error; err != nil || types[0] != "UTINYINT" {
+		t.Errorf("expected AlterColumn to convert the column, got %v, error %v", types, err)
+	}
+}
+
+type signedRecord struct {
+	ID     int
+	Tiny   int8
+	Small  int16
+	Medium int32
+	Large  int64
+	Alias  int64 `gorm:"type:int8"`
+}
+
+func TestMigrator_signedIntegers(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&signedRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var types []string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'signed_records' ORDER BY column_index").Scan(&types).Error; err != nil {
+		t.Fatalf("failed to query column types, got error %v", err)
+	}
+	if expected := []string{"BIGINT", "TINYINT", "SMALLINT", "INTEGER", "BIGINT", "BIGINT"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected columns %v, got %v", expected, types)
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&signedRecord{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER COLUMN") {
+		t.Errorf("expected columns to be kept, got %v", recorder.sql)
+	}
+}
+
+type narrowCounter struct {
+	ID     uint
+	Hits   int32
+	Visits uint32
+}
+
+type wideCounter struct {
+	ID     uint
+	Hits   int64
+	Visits uint64
+}
+
+func (wideCounter) TableName() string { return "narrow_counters" }
+
+type narrowIndexedCounter struct {
+	ID   uint
+	Hits int32 `gorm:"index"`
+}
+
+type wideIndexedCounter struct {
+	ID   uint
+	Hits int64 `gorm:"index"`
+}
+
+func (wideIndexedCounter) TableName() string { return "narrow_indexed_counters" }
+
+func TestMigrator_widenIntegers(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&narrowCounter{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Create(&narrowCounter{Hits: 1, Visits: 2}).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	if err := db.AutoMigrate(&wideCounter{}); err != nil {
+		t.Fatalf("failed to migrate to wider fields, got error %v", err)
+	}
+
+	var types []string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'narrow_counters' ORDER BY column_index").Scan(&types).Error; err != nil {
+		t.Fatalf("failed to query column types, got error %v", err)
+	}
+	if expected := []string{"UBIGINT", "BIGINT", "UBIGINT"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected widened columns %v, got %v", expected, types)
+	}
+	if err := db.Create(&wideCounter{Hits: 1 << 40, Visits: 1 << 40}).Error; err != nil {
+		t.Errorf("failed to create record beyond 32 bits, got error %v", err)
+	}
+
+	// narrower fields keep the columns
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&narrowCounter{}); err != nil {
+		t.Fatalf("failed to migrate to narrower fields, got error %v", err)
+	}
+	if recorder.contains("ALTER COLUMN") {
+		t.Errorf("expected wider columns to be kept, got %v", recorder.sql)
+	}
+
+	// DuckDB cannot change the type of columns of indexed tables
+	if err := db.AutoMigrate(&narrowIndexedCounter{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.AutoMigrate(&wideIndexedCounter{}); err == nil || !strings.Contains(err.Error(), "failed to widen column") {
+		t.Errorf("expected widening an indexed column to fail, got %v", err)
+	}
+}
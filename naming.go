@@ -0,0 +1,60 @@
+package duckdb
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// defaultIdentifierMaxLength is the length of names generated by NamingStrategy
+// when IdentifierMaxLength is zero, as in gorm
+const defaultIdentifierMaxLength = 64
+
+// NamingStrategy is gorm's naming strategy with lower-cased names of indexes and
+// constraints, as DuckDB would match names differing in case only to the same
+// object, and names longer than IdentifierMaxLength characters truncated with a
+// hash suffix without splitting multi-byte characters. It is the default of
+// dialectors opened without a naming strategy.
+type NamingStrategy struct {
+	schema.NamingStrategy
+}
+
+// RelationshipFKName generates the name of the foreign key of rel
+func (ns NamingStrategy) RelationshipFKName(rel schema.Relationship) string {
+	return ns.formatName("fk", rel.Schema.Table, ns.ColumnName("", rel.Name))
+}
+
+// CheckerName generates the name of a check constraint
+func (ns NamingStrategy) CheckerName(table, column string) string {
+	return ns.formatName("chk", table, column)
+}
+
+// IndexName generates the name of an index
+func (ns NamingStrategy) IndexName(table, column string) string {
+	return ns.formatName("idx", table, ns.ColumnName("", column))
+}
+
+// UniqueName generates the name of a unique constraint
+func (ns NamingStrategy) UniqueName(table, column string) string {
+	return ns.formatName("uni", table, ns.ColumnName("", column))
+}
+
+func (ns NamingStrategy) formatName(prefix, table, name string) string {
+	formatted := strings.ReplaceAll(strings.Join([]string{prefix, table, name}, "_"), ".", "_")
+	if !ns.NoLowerCase {
+		formatted = strings.ToLower(formatted)
+	}
+
+	maxLength := ns.IdentifierMaxLength
+	if maxLength <= 0 {
+		maxLength = defaultIdentifierMaxLength
+	}
+	// the same names as gorm's for ASCII names
+	if runes := []rune(formatted); len(runes) > maxLength {
+		sum := sha1.Sum([]byte(formatted))
+		formatted = string(runes[:max(maxLength-8, 0)]) + hex.EncodeToString(sum[:])[:min(maxLength, 8)]
+	}
+	return formatted
+}
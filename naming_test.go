@@ -0,0 +1,78 @@
+package duckdb
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+type namingRecordWithAVeryLongModelNameForTestingIdentifierTruncation struct {
+	CustomerReferenceNumber string `gorm:"index"`
+	CustomerReferenceCode   string `gorm:"index"`
+}
+
+type namingMixedCase struct {
+	Code string `gorm:"uniqueIndex"`
+}
+
+func (namingMixedCase) TableName() string {
+	return "NamingMixedCase"
+}
+
+func TestNamingStrategy(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		db := openTestDB(t, Config{})
+		if _, ok := db.NamingStrategy.(NamingStrategy); !ok {
+			t.Fatalf("expected the dialector's naming strategy, got %T", db.NamingStrategy)
+		}
+		if err := db.AutoMigrate(&namingRecordWithAVeryLongModelNameForTestingIdentifierTruncation{}, &namingMixedCase{}); err != nil {
+			t.Fatalf("failed to migrate, got error %v", err)
+		}
+
+		var indexes []string
+		if err := db.Raw(
+			"SELECT index_name FROM duckdb_indexes() WHERE table_name = ?", "naming_record_with_a_very_long_model_name_for_testing_identifier_truncations",
+		).Scan(&indexes).Error; err != nil || len(indexes) != 2 {
+			t.Fatalf("expected 2 distinct indexes, got %v, error %v", indexes, err)
+		}
+		for _, index := range indexes {
+			if len(index) != 64 {
+				t.Errorf("expected index names to be truncated to 64 characters, got %q", index)
+			}
+		}
+		if !db.Migrator().HasIndex(&namingMixedCase{}, "idx_namingmixedcase_code") {
+			t.Errorf("expected a lower-cased index name")
+		}
+	})
+
+	t.Run("identifier max length", func(t *testing.T) {
+		db := openTestDB(t, Config{IdentifierMaxLength: 32})
+		name := db.NamingStrategy.IndexName("namings", "CustomerReferenceNumberWithSuffix")
+		if len(name) != 32 || !strings.HasPrefix(name, "idx_namings_customer_re") || name == db.NamingStrategy.IndexName("namings", "CustomerReferenceNumberWithSuffix2") {
+			t.Errorf("expected a distinct name truncated to 32 characters, got %q", name)
+		}
+	})
+
+	t.Run("multi-byte characters", func(t *testing.T) {
+		ns := NamingStrategy{schema.NamingStrategy{IdentifierMaxLength: 16}}
+		name := ns.IndexName("日本語のテーブル名", "Name")
+		if utf8.RuneCountInString(name) != 16 || !utf8.ValidString(name) {
+			t.Errorf("expected a valid name of 16 characters, got %q", name)
+		}
+	})
+
+	t.Run("custom strategy", func(t *testing.T) {
+		db, err := gorm.Open(New(Config{}), &gorm.Config{Logger: logger.Discard, NamingStrategy: schema.NamingStrategy{TablePrefix: "app_"}})
+		if err != nil {
+			t.Fatalf("failed to open database, got error %v", err)
+		}
+		defer Close(db)
+		if _, ok := db.NamingStrategy.(schema.NamingStrategy); !ok {
+			t.Errorf("expected the configured naming strategy to be kept, got %T", db.NamingStrategy)
+		}
+	})
+}
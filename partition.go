@@ -0,0 +1,219 @@
+package duckdb
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PartitionPeriod is the time span covered by each table of a PartitionedTable
+type PartitionPeriod int
+
+const (
+	// PartitionDaily keeps a table per day, e.g. events_2024_01_31
+	PartitionDaily PartitionPeriod = iota
+	// PartitionMonthly keeps a table per month, e.g. events_2024_01
+	PartitionMonthly
+	// PartitionYearly keeps a table per year, e.g. events_2024
+	PartitionYearly
+)
+
+var partitionLayouts = map[PartitionPeriod]struct{ layout, pattern string }{
+	PartitionDaily:   {"2006_01_02", `\d{4}_\d{2}_\d{2}`},
+	PartitionMonthly: {"2006_01", `\d{4}_\d{2}`},
+	PartitionYearly:  {"2006", `\d{4}`},
+}
+
+// PartitionedTable keeps the rows of a model in one table per period and a
+// view named like the model's table that combines them with UNION ALL BY NAME,
+// as DuckDB has no partitioned tables. Rows are written to the partition of
+// their time and read through the view:
+//
+//	events, _ := duckdb.NewPartitionedTable(db, &Event{}, duckdb.PartitionMonthly)
+//	tx, _ := events.Partition(event.CreatedAt)
+//	tx.Create(&event)                            // INSERT INTO "events_2024_01" ...
+//	db.Where("kind = ?", "click").Find(&clicks) // SELECT * FROM "events" ...
+//
+// Partitions are migrated with AutoMigrate; columns added later only exist in
+// new partitions and read as NULL from older ones.
+type PartitionedTable struct {
+	// Retention is the number of past periods kept by Maintain besides the
+	// current one, all periods are kept when zero
+	Retention int
+
+	db      *gorm.DB
+	model   interface{}
+	table   string
+	period  PartitionPeriod
+	mu      sync.Mutex
+	ensured map[string]bool
+}
+
+// NewPartitionedTable returns the partitioned table of model
+func NewPartitionedTable(db *gorm.DB, model interface{}, period PartitionPeriod) (*PartitionedTable, error) {
+	if _, ok := partitionLayouts[period]; !ok {
+		return nil, fmt.Errorf("unknown partition period %d", period)
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return &PartitionedTable{
+		db:      db.Session(&gorm.Session{NewDB: true}),
+		model:   model,
+		table:   stmt.Table,
+		period:  period,
+		ensured: map[string]bool{},
+	}, nil
+}
+
+// Name returns the name of the partition holding rows of time t
+func (p *PartitionedTable) Name(t time.Time) string {
+	return p.table + "_" + p.start(t).Format(partitionLayouts[p.period].layout)
+}
+
+// Partition returns a session writing to the partition of time t, creating it
+// first if needed
+func (p *PartitionedTable) Partition(t time.Time) (*gorm.DB, error) {
+	if err := p.Ensure(t); err != nil {
+		return nil, err
+	}
+	return p.db.Table(p.Name(t)), nil
+}
+
+// Ensure creates the partition of time t unless it exists and adds it to the
+// view
+func (p *PartitionedTable) Ensure(t time.Time) error {
+	name := p.Name(t)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ensured[name] {
+		return nil
+	}
+	if err := p.db.Table(name).AutoMigrate(p.model); err != nil {
+		return err
+	}
+	if err := p.refreshView(); err != nil {
+		return err
+	}
+	p.ensured[name] = true
+	return nil
+}
+
+// Maintain creates the partitions of now and of the next period, so that
+// writers never wait for a migration at the turn of a period, and prunes
+// partitions older than Retention periods
+func (p *PartitionedTable) Maintain(now time.Time) error {
+	if err := p.Ensure(now); err != nil {
+		return err
+	}
+	if err := p.Ensure(p.shift(p.start(now), 1)); err != nil {
+		return err
+	}
+	if p.Retention > 0 {
+		if _, err := p.Prune(p.shift(p.start(now), -p.Retention)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune drops the partitions of periods before the one of time t and returns
+// their names
+func (p *PartitionedTable) Prune(t time.Time) (dropped []string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	partitions, err := p.partitions()
+	if err != nil {
+		return nil, err
+	}
+	limit := p.Name(t)
+	for _, name := range partitions {
+		// the names sort like their periods
+		if name < limit {
+			dropped = append(dropped, name)
+		}
+	}
+	if len(dropped) == 0 {
+		return nil, nil
+	}
+
+	// drop the view first, it would fail to bind once a partition is gone
+	if err := p.db.Exec("DROP VIEW IF EXISTS ?", clause.Table{Name: p.table}).Error; err != nil {
+		return nil, err
+	}
+	for _, name := range dropped {
+		if err := p.db.Exec("DROP TABLE IF EXISTS ?", clause.Table{Name: name}).Error; err != nil {
+			return nil, err
+		}
+		delete(p.ensured, name)
+	}
+	return dropped, p.refreshView()
+}
+
+// Partitions returns the names of the existing partitions, oldest first
+func (p *PartitionedTable) Partitions() ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.partitions()
+}
+
+func (p *PartitionedTable) partitions() (names []string, err error) {
+	pattern := regexp.QuoteMeta(p.table) + "_" + partitionLayouts[p.period].pattern
+	err = p.db.Raw(
+		"SELECT table_name FROM duckdb_tables() WHERE database_name = CURRENT_DATABASE() AND schema_name = CURRENT_SCHEMA() AND regexp_full_match(table_name, ?)",
+		pattern,
+	).Scan(&names).Error
+	slices.Sort(names)
+	return names, err
+}
+
+// refreshView recreates the view over all partitions, or drops it when there
+// are none
+func (p *PartitionedTable) refreshView() error {
+	partitions, err := p.partitions()
+	if err != nil {
+		return err
+	}
+	if len(partitions) == 0 {
+		return p.db.Exec("DROP VIEW IF EXISTS ?", clause.Table{Name: p.table}).Error
+	}
+
+	selects := make([]string, len(partitions))
+	for i, name := range partitions {
+		selects[i] = "SELECT * FROM " + p.db.Statement.Quote(name)
+	}
+	return p.db.Exec(
+		"CREATE OR REPLACE VIEW ? AS "+strings.Join(selects, " UNION ALL BY NAME "), clause.Table{Name: p.table},
+	).Error
+}
+
+// start returns the start of the period of t
+func (p *PartitionedTable) start(t time.Time) time.Time {
+	switch p.period {
+	case PartitionMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case PartitionYearly:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// shift moves the period start by n periods
+func (p *PartitionedTable) shift(start time.Time, n int) time.Time {
+	switch p.period {
+	case PartitionMonthly:
+		return start.AddDate(0, n, 0)
+	case PartitionYearly:
+		return start.AddDate(n, 0, 0)
+	}
+	return start.AddDate(0, 0, n)
+}
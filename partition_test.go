@@ -0,0 +1,85 @@
+package duckdb
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+type partitionedEvent struct {
+	Kind      string
+	CreatedAt time.Time
+}
+
+type partitionedEventV2 struct {
+	Kind      string
+	CreatedAt time.Time
+	Source    string
+}
+
+func (partitionedEventV2) TableName() string {
+	return "partitioned_events"
+}
+
+func TestPartitionedTable(t *testing.T) {
+	db := openTestDB(t, Config{})
+	events, err := NewPartitionedTable(db, &partitionedEvent{}, PartitionMonthly)
+	if err != nil {
+		t.Fatalf("failed to create partitioned table, got error %v", err)
+	}
+
+	jan := time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC)
+	if name := events.Name(jan); name != "partitioned_events_2024_01" {
+		t.Errorf("expected the partition of January, got %s", name)
+	}
+	for _, event := range []partitionedEvent{{"click", jan}, {"view", jan.AddDate(0, 1, 0)}, {"click", jan.AddDate(0, 2, 0)}} {
+		tx, err := events.Partition(event.CreatedAt)
+		if err != nil {
+			t.Fatalf("failed to create partition, got error %v", err)
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			t.Fatalf("failed to insert, got error %v", err)
+		}
+	}
+
+	var clicks int64
+	if err := db.Model(&partitionedEvent{}).Where("kind = ?", "click").Count(&clicks).Error; err != nil || clicks != 2 {
+		t.Errorf("expected to read all partitions through the view, got %d, error %v", clicks, err)
+	}
+
+	// new columns only reach new partitions
+	evolved, _ := NewPartitionedTable(db, &partitionedEventV2{}, PartitionMonthly)
+	apr := jan.AddDate(0, 3, 0)
+	tx, err := evolved.Partition(apr)
+	if err != nil {
+		t.Fatalf("failed to create partition, got error %v", err)
+	}
+	if err := tx.Create(&partitionedEventV2{Kind: "view", CreatedAt: apr, Source: "web"}).Error; err != nil {
+		t.Fatalf("failed to insert, got error %v", err)
+	}
+	var sources []sql.NullString
+	if err := db.Model(&partitionedEventV2{}).Order("created_at").Pluck("source", &sources).Error; err != nil || len(sources) != 4 || sources[0].Valid || sources[3].String != "web" {
+		t.Errorf("expected NULL sources from older partitions, got %v, error %v", sources, err)
+	}
+
+	events.Retention = 1
+	if err := events.Maintain(apr); err != nil {
+		t.Fatalf("failed to maintain, got error %v", err)
+	}
+	partitions, err := events.Partitions()
+	want := []string{"partitioned_events_2024_03", "partitioned_events_2024_04", "partitioned_events_2024_05"}
+	if err != nil || len(partitions) != len(want) || partitions[0] != want[0] || partitions[2] != want[2] {
+		t.Errorf("expected partitions %v, got %v, error %v", want, partitions, err)
+	}
+	var count int64
+	if err := db.Model(&partitionedEvent{}).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("expected the rows of the kept partitions, got %d, error %v", count, err)
+	}
+
+	if dropped, err := events.Prune(apr.AddDate(1, 0, 0)); err != nil || len(dropped) != 3 {
+		t.Errorf("expected all partitions to be dropped, got %v, error %v", dropped, err)
+	}
+	if db.Migrator().HasTable("partitioned_events") {
+		t.Errorf("expected the view to be dropped with the last partition")
+	}
+}
@@ -0,0 +1,257 @@
+package duckdb
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	gormcallbacks "gorm.io/gorm/callbacks"
+	"gorm.io/gorm/schema"
+)
+
+const aggregatedPreloadKey = "duckdb:aggregated_preload"
+
+// AggregatedPreload returns a scope that loads the associations names in the
+// query of their owners instead of a query per association as Preload does,
+// each aggregated with list() in a correlated subquery and unpacked into the
+// association fields:
+//
+//	db.Scopes(duckdb.AggregatedPreload("Orders", "Profile")).Find(&users)
+//
+// It loads direct has one, has many, belongs to and many2many associations
+// without their own associations or conditions, and excludes soft deleted rows.
+// The owners must be selected with the keys the associations refer to.
+func AggregatedPreload(names ...string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.InstanceSet(aggregatedPreloadKey, names)
+	}
+}
+
+// query runs gorm's query, adding the associations of AggregatedPreload to the
+// selected columns and assigning them once the owners are scanned
+func (dialector Dialector) query(db *gorm.DB) {
+	value, ok := db.InstanceGet(aggregatedPreloadKey)
+	if !ok || db.Error != nil || db.Statement.Schema == nil || db.Statement.SQL.Len() > 0 ||
+		indirectType(db.Statement.ReflectValue.Type()) != db.Statement.Schema.ModelType {
+		gormcallbacks.Query(db)
+		return
+	}
+
+	stmt := db.Statement
+	var relations []*schema.Relationship
+	for _, name := range value.([]string) {
+		rel, ok := stmt.Schema.Relationships.Relations[name]
+		if !ok {
+			db.AddError(fmt.Errorf("%s: unsupported relations for schema %s", name, stmt.Schema.Name))
+			return
+		}
+		relations = append(relations, rel)
+	}
+
+	selects := stmt.Selects
+	defer func() { stmt.Selects = selects }()
+	if len(selects) == 0 {
+		stmt.Selects = []string{quoteIdentifier(stmt.Table) + ".*"}
+	} else {
+		stmt.Selects = append([]string{}, selects...)
+	}
+	for i, rel := range relations {
+		stmt.Selects = append(stmt.Selects, aggregatedRelation(stmt.Table, rel)+" AS "+quoteIdentifier(preloadColumn(i)))
+	}
+
+	gormcallbacks.BuildQuerySQL(db)
+	if db.DryRun || db.Error != nil {
+		return
+	}
+	rows, err := stmt.ConnPool.QueryContext(stmt.Context, stmt.SQL.String(), stmt.Vars...)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	defer func() {
+		db.AddError(rows.Close())
+	}()
+
+	preloadRows := &aggregatedRows{Rows: rows, relations: len(relations)}
+	gorm.Scan(preloadRows, db, 0)
+	if db.Error != nil {
+		return
+	}
+
+	owners := []reflect.Value{stmt.ReflectValue}
+	if stmt.ReflectValue.Kind() == reflect.Slice || stmt.ReflectValue.Kind() == reflect.Array {
+		owners = make([]reflect.Value, stmt.ReflectValue.Len())
+		for i := range owners {
+			owners[i] = stmt.ReflectValue.Index(i)
+		}
+	}
+	for i, owner := range owners {
+		if i >= len(preloadRows.values) {
+			break
+		}
+		for j, rel := range relations {
+			if err := assignAggregated(db, reflect.Indirect(owner), rel, preloadRows.values[i][j]); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	}
+}
+
+// preloadColumn is the name of the column aggregating the i-th association
+func preloadColumn(i int) string {
+	return "__duckdb_preload_" + strconv.Itoa(i)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
+}
+
+// aggregatedRelation returns the correlated subquery listing the rows of rel
+// for the owner in table, in the order of their primary keys
+func aggregatedRelation(table string, rel *schema.Relationship) string {
+	var (
+		target     = quoteIdentifier("t")
+		from       = quoteIdentifier(rel.FieldSchema.Table) + " AS " + target
+		join       []string
+		conditions []string
+	)
+	column := func(table, name string) string {
+		return table + "." + quoteIdentifier(name)
+	}
+
+	if rel.JoinTable != nil {
+		joinTable := quoteIdentifier("j")
+		for _, ref := range rel.References {
+			switch {
+			case ref.OwnPrimaryKey:
+				conditions = append(conditions, column(joinTable, ref.ForeignKey.DBName)+" = "+column(quoteIdentifier(table), ref.PrimaryKey.DBName))
+			case ref.PrimaryValue != "":
+				conditions = append(conditions, column(joinTable, ref.ForeignKey.DBName)+" = "+quoteString(ref.PrimaryValue))
+			default:
+				join = append(join, column(joinTable, ref.ForeignKey.DBName)+" = "+column(target, ref.PrimaryKey.DBName))
+			}
+		}
+		from += " JOIN " + quoteIdentifier(rel.JoinTable.Table) + " AS " + joinTable + " ON " + strings.Join(join, " AND ")
+	} else {
+		for _, ref := range rel.References {
+			switch {
+			case ref.OwnPrimaryKey:
+				conditions = append(conditions, column(target, ref.ForeignKey.DBName)+" = "+column(quoteIdentifier(table), ref.PrimaryKey.DBName))
+			case ref.PrimaryValue != "":
+				conditions = append(conditions, column(target, ref.ForeignKey.DBName)+" = "+quoteString(ref.PrimaryValue))
+			default:
+				conditions = append(conditions, column(target, ref.PrimaryKey.DBName)+" = "+column(quoteIdentifier(table), ref.ForeignKey.DBName))
+			}
+		}
+	}
+	for _, field := range rel.FieldSchema.Fields {
+		if field.DBName != "" && field.FieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+			conditions = append(conditions, column(target, field.DBName)+" IS NULL")
+		}
+	}
+
+	order := make([]string, len(rel.FieldSchema.PrimaryFieldDBNames))
+	for i, name := range rel.FieldSchema.PrimaryFieldDBNames {
+		order[i] = column(target, name)
+	}
+	aggregate := "list(" + target
+	if len(order) > 0 {
+		aggregate += " ORDER BY " + strings.Join(order, ", ")
+	}
+	return "(SELECT " + aggregate + ") FROM " + from + " WHERE " + strings.Join(conditions, " AND ") + ")"
+}
+
+// aggregatedRows hides the aggregated associations from gorm's Scan, keeping
+// them for each scanned row
+type aggregatedRows struct {
+	*sql.Rows
+	relations int
+	values    [][]interface{}
+}
+
+func (rows *aggregatedRows) Columns() ([]string, error) {
+	columns, err := rows.Rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	return columns[:len(columns)-rows.relations], nil
+}
+
+func (rows *aggregatedRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	columnTypes, err := rows.Rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	return columnTypes[:len(columnTypes)-rows.relations], nil
+}
+
+func (rows *aggregatedRows) Scan(dest ...interface{}) error {
+	values := make([]interface{}, rows.relations)
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+	rows.values = append(rows.values, values)
+	return rows.Rows.Scan(dest...)
+}
+
+// assignAggregated assigns the list of rows of rel read for owner to its field
+func assignAggregated(db *gorm.DB, owner reflect.Value, rel *schema.Relationship, value interface{}) error {
+	list, _ := value.([]interface{})
+	fieldType := rel.Field.IndirectFieldType
+	elemType := fieldType
+	if fieldType.Kind() == reflect.Slice {
+		elemType = fieldType.Elem()
+	}
+
+	elems := reflect.MakeSlice(reflect.SliceOf(elemType), 0, len(list))
+	for _, item := range list {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected %T for %s", item, rel.Name)
+		}
+		elem := reflect.New(rel.FieldSchema.ModelType)
+		for column, columnValue := range row {
+			field := rel.FieldSchema.LookUpField(column)
+			if field == nil || field.DBName == "" {
+				continue
+			}
+			if err := setScannedValue(db, field, elem.Elem(), columnValue); err != nil {
+				return err
+			}
+		}
+		if elemType.Kind() == reflect.Pointer {
+			elems = reflect.Append(elems, elem)
+		} else {
+			elems = reflect.Append(elems, elem.Elem())
+		}
+	}
+
+	if fieldType.Kind() == reflect.Slice {
+		return rel.Field.Set(db.Statement.Context, owner, elems.Interface())
+	}
+	if elems.Len() > 0 {
+		return rel.Field.Set(db.Statement.Context, owner, elems.Index(0).Interface())
+	}
+	return nil
+}
+
+// setScannedValue sets field of elem to value as gorm's Scan would, through
+// the field's scanner
+func setScannedValue(db *gorm.DB, field *schema.Field, elem reflect.Value, value interface{}) error {
+	scanned := field.NewValuePool.Get()
+	defer field.NewValuePool.Put(scanned)
+	if scanner, ok := scanned.(sql.Scanner); ok {
+		if err := scanner.Scan(value); err != nil {
+			return fmt.Errorf("failed to scan %s: %w", field.Name, err)
+		}
+		return field.Set(db.Statement.Context, elem, scanned)
+	}
+	return field.Set(db.Statement.Context, elem, value)
+}
@@ -0,0 +1,122 @@
+package duckdb
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type preloadCompany struct {
+	Code string `gorm:"primaryKey"`
+	Name string
+}
+
+type preloadProfile struct {
+	ID             uint
+	PreloadOwnerID uint
+	Bio            string
+}
+
+type preloadOrder struct {
+	ID             uint
+	PreloadOwnerID uint
+	Total          float64
+	PlacedAt       time.Time
+	DeletedAt      gorm.DeletedAt
+}
+
+type preloadTag struct {
+	Name string `gorm:"primaryKey"`
+}
+
+type preloadOwner struct {
+	ID          uint
+	Name        string
+	CompanyCode string
+	Company     preloadCompany `gorm:"foreignKey:CompanyCode"`
+	Profile     *preloadProfile
+	Orders      []preloadOrder
+	Tags        []*preloadTag `gorm:"many2many:preload_owner_tags"`
+}
+
+func TestAggregatedPreload(t *testing.T) {
+	var queries []string
+	db := openTestDB(t, Config{QueryStats: func(_ context.Context, stats QueryStats) {
+		queries = append(queries, stats.SQL)
+	}})
+	if err := db.AutoMigrate(&preloadCompany{}, &preloadOwner{}, &preloadProfile{}, &preloadOrder{}, &preloadTag{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	placedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	if err := db.Create(&[]preloadCompany{{Code: "acme", Name: "Acme"}, {Code: "initech", Name: "Initech"}}).Error; err != nil {
+		t.Fatalf("failed to create companies, got error %v", err)
+	}
+	owners := []preloadOwner{{Name: "alice", CompanyCode: "acme"}, {Name: "bob", CompanyCode: "initech"}}
+	if err := db.Create(&owners).Error; err != nil {
+		t.Fatalf("failed to create owners, got error %v", err)
+	}
+	alice := owners[0]
+	if err := db.Create(&preloadProfile{PreloadOwnerID: alice.ID, Bio: "hello"}).Error; err != nil {
+		t.Fatalf("failed to create profile, got error %v", err)
+	}
+	orders := []preloadOrder{
+		{PreloadOwnerID: alice.ID, Total: 10.5, PlacedAt: placedAt},
+		{PreloadOwnerID: alice.ID, Total: 20, PlacedAt: placedAt},
+		{PreloadOwnerID: alice.ID, Total: 30, PlacedAt: placedAt},
+	}
+	if err := db.Create(&orders).Error; err != nil {
+		t.Fatalf("failed to create orders, got error %v", err)
+	}
+	if err := db.Delete(&orders[2]).Error; err != nil {
+		t.Fatalf("failed to soft delete order, got error %v", err)
+	}
+	if err := db.Model(&alice).Association("Tags").Append([]*preloadTag{{Name: "vip"}, {Name: "beta"}}); err != nil {
+		t.Fatalf("failed to append tags, got error %v", err)
+	}
+
+	queries = nil
+	var loaded []preloadOwner
+	if err := db.Scopes(AggregatedPreload("Company", "Profile", "Orders", "Tags")).Order("id").Find(&loaded).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if len(queries) != 1 || !strings.Contains(queries[0], "list(") {
+		t.Errorf("expected a single aggregating query, got %v", queries)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(loaded))
+	}
+
+	alice, bob := loaded[0], loaded[1]
+	if alice.Company.Name != "Acme" || bob.Company.Name != "Initech" {
+		t.Errorf("expected belongs to associations, got %+v and %+v", alice.Company, bob.Company)
+	}
+	if alice.Profile == nil || alice.Profile.Bio != "hello" || bob.Profile != nil {
+		t.Errorf("expected has one associations, got %+v and %+v", alice.Profile, bob.Profile)
+	}
+	if len(alice.Orders) != 2 || alice.Orders[0].Total != 10.5 || !alice.Orders[1].PlacedAt.Equal(placedAt) || len(bob.Orders) != 0 {
+		t.Errorf("expected has many associations without soft deleted rows, got %+v and %+v", alice.Orders, bob.Orders)
+	}
+	if len(alice.Tags) != 2 || alice.Tags[0].Name != "beta" || alice.Tags[1].Name != "vip" {
+		t.Errorf("expected many2many associations, got %+v", alice.Tags)
+	}
+
+	var first preloadOwner
+	if err := db.Scopes(AggregatedPreload("Orders")).Select("id", "name").First(&first).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if first.Name != "alice" || first.CompanyCode != "" || len(first.Orders) != 2 {
+		t.Errorf("expected selected columns and associations, got %+v", first)
+	}
+
+	var count int64
+	if err := db.Model(&preloadOwner{}).Scopes(AggregatedPreload("Orders")).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("expected count to ignore the preload, got %d, error %v", count, err)
+	}
+	if err := db.Scopes(AggregatedPreload("Unknown")).Find(&loaded).Error; err == nil {
+		t.Errorf("expected unknown association to fail")
+	}
+}
@@ -0,0 +1,83 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"unicode"
+)
+
+// readKeywords are the first keywords of the statements sent to the readers
+var readKeywords = []string{"SELECT", "WITH", "FROM", "SHOW", "DESCRIBE", "SUMMARIZE"}
+
+// readConnector opens the read connections of a connector, which it must not
+// close when the readers are closed
+type readConnector struct {
+	connector *connector
+}
+
+// Connect opens a read connection, which runs its statements in read-only
+// transactions so that they fail to write
+func (c readConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dc.(*conn).readOnly = true
+	return dc, nil
+}
+
+func (c readConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// openReaders splits the pool into size read-only connections and a single
+// read-write connection, all of the same database
+func (p *connPool) openReaders(size int) {
+	p.DB.SetMaxOpenConns(1)
+	p.readers = sql.OpenDB(readConnector{connector: p.connector})
+	p.readers.SetMaxOpenConns(size)
+	p.readers.SetMaxIdleConns(size)
+	p.connector.readers = p.readers
+}
+
+// poolOf returns the readers for read-only transactions, the read-write
+// connection otherwise
+func (p *connPool) poolOf(opts *sql.TxOptions) *sql.DB {
+	if p.readers != nil && opts != nil && opts.ReadOnly {
+		return p.readers
+	}
+	return p.DB
+}
+
+// queryPool returns the readers for statements that only read
+func (p *connPool) queryPool(query string) *sql.DB {
+	if p.readers != nil && isReadQuery(query) {
+		return p.readers
+	}
+	return p.DB
+}
+
+func (p *connPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.queryPool(query).QueryContext(ctx, query, args...)
+}
+
+func (p *connPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.queryPool(query).QueryRowContext(ctx, query, args...)
+}
+
+// isReadQuery reports whether query starts with the keyword of a statement that
+// only reads
+func isReadQuery(query string) bool {
+	query = strings.TrimLeftFunc(query, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '('
+	})
+	for _, keyword := range readKeywords {
+		if len(query) > len(keyword) && strings.EqualFold(query[:len(keyword)], keyword) &&
+			!unicode.IsLetter(rune(query[len(keyword)])) && query[len(keyword)] != '_' {
+			return true
+		}
+	}
+	return false
+}
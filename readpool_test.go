@@ -0,0 +1,124 @@
+package duckdb
+
+import (
+	"database/sql"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type readPoolRecord struct {
+	Code string `gorm:"primaryKey"`
+}
+
+func TestConfig_ReadConns(t *testing.T) {
+	db := openTestDB(t, Config{DSN: filepath.Join(t.TempDir(), "read.duckdb"), ReadConns: 2})
+	if err := db.AutoMigrate(&readPoolRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := db.Create(&readPoolRecord{Code: "a"}).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+
+	// the transaction holds the only read-write connection
+	tx := db.Begin()
+	if err := tx.Create(&readPoolRecord{Code: "b"}).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		var count int64
+		err := db.Model(&readPoolRecord{}).Count(&count).Error
+		if err == nil && count != 1 {
+			t.Errorf("expected the committed record only, got %d", count)
+		}
+		done <- db.Transaction(func(tx *gorm.DB) error {
+			return tx.First(&readPoolRecord{}).Error
+		}, &sql.TxOptions{ReadOnly: true})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("failed to read, got error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected reads not to wait for the read-write connection")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		t.Fatalf("failed to commit, got error %v", err)
+	}
+	var count int64
+	if err := db.Model(&readPoolRecord{}).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("expected readers to see committed writes, got %d, error %v", count, err)
+	}
+
+	// readers fail to write, even with statements taken for reads
+	var code string
+	if err := db.Raw(`WITH c AS (SELECT 'c' AS code) INSERT INTO read_pool_records SELECT code FROM c RETURNING code`).
+		Scan(&code).Error; err == nil {
+		t.Errorf("expected readers to be read-only")
+	}
+}
+
+func TestConfig_ReadConns_appender(t *testing.T) {
+	db := openTestDB(t, Config{DSN: filepath.Join(t.TempDir(), "read.duckdb"), ReadConns: 2})
+	if err := db.AutoMigrate(&readPoolRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- db.Transaction(func(tx *gorm.DB) error {
+			if _, err := AppendModels(tx, []readPoolRecord{{Code: "a"}}); err != nil {
+				return err
+			}
+			rows := []string{"b"}
+			_, err := CopyFrom(tx, "read_pool_records", nil, func() ([]any, error) {
+				if len(rows) == 0 {
+					return nil, io.EOF
+				}
+				row := []any{rows[0]}
+				rows = rows[1:]
+				return row, nil
+			})
+			return err
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("failed to append in a transaction, got error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected appenders to use the connection of the transaction")
+	}
+
+	var count int64
+	if err := db.Model(&readPoolRecord{}).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("expected 2 records appended, got %d, error %v", count, err)
+	}
+}
+
+func TestIsReadQuery(t *testing.T) {
+	for query, want := range map[string]bool{
+		`SELECT * FROM "users"`:                 true,
+		"\n  select 1":                          true,
+		"(SELECT 1) UNION (SELECT 2)":           true,
+		"WITH t AS (SELECT 1) SELECT * FROM t":  true,
+		"FROM users":                            true,
+		"INSERT INTO users VALUES (1)":          false,
+		"UPDATE users SET name = 'a'":           false,
+		"SELECTED":                              false,
+		"select_1()":                            false,
+		"CREATE TABLE t AS SELECT * FROM users": false,
+	} {
+		if got := isReadQuery(query); got != want {
+			t.Errorf("expected isReadQuery(%q) to be %v, got %v", query, want, got)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package duckdb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// RedactedValue replaces the bind values Explain leaves out of logged SQL
+const RedactedValue = "[REDACTED]"
+
+// isSensitive reports whether the values of field are redacted from logged SQL,
+// as set by its sensitive tag:
+//
+//	type User struct {
+//		ID       uint
+//		Password string `gorm:"sensitive"`
+//	}
+func isSensitive(field *schema.Field) bool {
+	_, ok := field.TagSettings["SENSITIVE"]
+	return ok
+}
+
+// sensitiveValue is a bind value of a sensitive field. It is passed to the
+// driver as the value it wraps and redacted by Explain.
+type sensitiveValue struct {
+	value interface{}
+}
+
+// Value converts the wrapped value for drivers that do not unwrap it
+func (v sensitiveValue) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(v.value)
+}
+
+// sensitive wraps value of field in a sensitiveValue if the field is sensitive
+func sensitive(field *schema.Field, value interface{}) interface{} {
+	if field == nil || !isSensitive(field) {
+		return value
+	}
+	switch value.(type) {
+	case nil, clause.Expression, sensitiveValue:
+		return value
+	}
+	return sensitiveValue{value: value}
+}
+
+// sensitiveValues wraps the values of the sensitive columns of values
+func sensitiveValues(stmt *gorm.Statement, values clause.Values) clause.Values {
+	if stmt.Schema == nil {
+		return values
+	}
+	fields := make([]*schema.Field, len(values.Columns))
+	found := false
+	for i, column := range values.Columns {
+		if field := stmt.Schema.LookUpField(column.Name); field != nil && isSensitive(field) {
+			fields[i], found = field, true
+		}
+	}
+	if !found {
+		return values
+	}
+
+	rows := make([][]interface{}, len(values.Values))
+	for i, row := range values.Values {
+		rows[i] = make([]interface{}, len(row))
+		for j, value := range row {
+			if j < len(fields) {
+				value = sensitive(fields[j], value)
+			}
+			rows[i][j] = value
+		}
+	}
+	return clause.Values{Columns: values.Columns, Values: rows}
+}
+
+// Explain interpolates vars into query for logging. Values of sensitive fields
+// are replaced by RedactedValue, as are all values with Config.RedactParameters.
+func (dialector Dialector) Explain(query string, vars ...interface{}) string {
+	redactAll := dialector.Config != nil && dialector.RedactParameters
+	redacted := make([]interface{}, len(vars))
+	for i, v := range vars {
+		if arg, ok := v.(sql.NamedArg); ok && redactAll {
+			redacted[i] = sql.Named(arg.Name, RedactedValue)
+		} else if _, ok := v.(sensitiveValue); ok || redactAll {
+			redacted[i] = RedactedValue
+		} else {
+			redacted[i] = v
+		}
+	}
+	return dialector.interpolate(query, redacted)
+}
+
+// namedPlaceholder matches named parameters such as $name
+var namedPlaceholder = regexp.MustCompile(`\$[A-Za-z_][A-Za-z0-9_]*`)
+
+// interpolate interpolates vars into query like logger.ExplainSQL, and the
+// values of named parameters, bound with sql.Named, at their $name
+func (dialector Dialector) interpolate(query string, vars []interface{}) string {
+	positional := make([]interface{}, 0, len(vars))
+	named := map[string]interface{}{}
+	for _, v := range vars {
+		if arg, ok := v.(sql.NamedArg); ok && arg.Name != "" {
+			named[arg.Name] = arg.Value
+		} else {
+			positional = append(positional, v)
+		}
+	}
+	query = logger.ExplainSQL(query, dialector.placeholderPattern(), `'`, positional...)
+	if len(named) == 0 {
+		return query
+	}
+	return namedPlaceholder.ReplaceAllStringFunc(query, func(placeholder string) string {
+		if value, ok := named[placeholder[1:]]; ok {
+			return logger.ExplainSQL("?", nil, `'`, value)
+		}
+		return placeholder
+	})
+}
+
+// placeholderPattern returns the pattern of numbered parameters for
+// logger.ExplainSQL with Config.NumberedPlaceholders, and nil for ?
+func (dialector Dialector) placeholderPattern() *regexp.Regexp {
+	if dialector.numberedPlaceholders() {
+		return numberedPlaceholder
+	}
+	return nil
+}
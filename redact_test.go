@@ -0,0 +1,102 @@
+package duckdb
+
+import (
+	"database/sql"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type redactedAccount struct {
+	ID       uint
+	Name     string
+	Password string `gorm:"sensitive"`
+	Balance  int64  `gorm:"sensitive"`
+}
+
+func TestDialector_Explain_sensitive(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&redactedAccount{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	tx := db.Session(&gorm.Session{Logger: recorder})
+	account := redactedAccount{Name: "alice", Password: "hunter2", Balance: 42}
+	if err := tx.Create(&account).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	if err := tx.Model(&account).Updates(map[string]interface{}{"password": "swordfish", "name": "bob"}).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	if recorder.contains("hunter2") || recorder.contains("swordfish") || recorder.contains("42") {
+		t.Errorf("expected sensitive values to be redacted, got %v", recorder.sql)
+	}
+	if !recorder.contains("'alice'") || !recorder.contains("'bob'") || !recorder.contains("'[REDACTED]'") {
+		t.Errorf("expected other values to be logged, got %v", recorder.sql)
+	}
+
+	var found redactedAccount
+	if err := db.First(&found, account.ID).Error; err != nil {
+		t.Fatalf("failed to find, got error %v", err)
+	}
+	if found.Name != "bob" || found.Password != "swordfish" || found.Balance != 42 {
+		t.Errorf("expected sensitive values to be stored, got %+v", found)
+	}
+}
+
+func TestDialector_Explain_redactParameters(t *testing.T) {
+	db := openTestDB(t, Config{RedactParameters: true})
+	explained := db.Dialector.Explain("SELECT * FROM users WHERE name = ? AND age > ?", "alice", 30)
+	if expected := "SELECT * FROM users WHERE name = '[REDACTED]' AND age > '[REDACTED]'"; explained != expected {
+		t.Errorf("expected %s, got %s", expected, explained)
+	}
+
+	explained = db.Dialector.Explain("SELECT * FROM users WHERE name = $name", sql.Named("name", "alice"))
+	if expected := "SELECT * FROM users WHERE name = '[REDACTED]'"; explained != expected {
+		t.Errorf("expected %s, got %s", expected, explained)
+	}
+
+	explained = New(Config{}).Explain("SELECT * FROM users WHERE name = ?", "alice")
+	if expected := "SELECT * FROM users WHERE name = 'alice'"; explained != expected {
+		t.Errorf("expected %s, got %s", expected, explained)
+	}
+}
+
+func TestDialector_namedParameters(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&redactedAccount{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	accounts := []redactedAccount{{Name: "alice", Balance: 10}, {Name: "bob", Balance: 20}, {Name: "carol", Balance: 30}}
+	if err := db.Create(&accounts).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	tx := db.Session(&gorm.Session{Logger: recorder})
+	var names []string
+	if err := tx.Raw(`SELECT name FROM redacted_accounts WHERE name = $name OR name = $other || $suffix ORDER BY name`,
+		sql.Named("name", "alice"), sql.Named("other", "car"), sql.Named("suffix", "ol")).Scan(&names).Error; err != nil {
+		t.Fatalf("failed to query, got error %v", err)
+	}
+	if len(names) != 2 || names[0] != "alice" || names[1] != "carol" {
+		t.Errorf("expected alice and carol, got %v", names)
+	}
+	if expected := "SELECT name FROM redacted_accounts WHERE name = 'alice' OR name = 'car' || 'ol' ORDER BY name"; !recorder.contains(expected) {
+		t.Errorf("expected %s, got %v", expected, recorder.sql)
+	}
+
+	names = nil
+	if err := tx.Model(&redactedAccount{}).Where("balance >= $min AND balance < $min + 15", sql.Named("min", 10)).
+		Order("name").Pluck("name", &names).Error; err != nil {
+		t.Fatalf("failed to query, got error %v", err)
+	}
+	if len(names) != 2 || names[0] != "alice" || names[1] != "bob" {
+		t.Errorf("expected alice and bob, got %v", names)
+	}
+	if !recorder.contains("balance >= 10 AND balance < 10 + 15") {
+		t.Errorf("expected named parameters to be interpolated, got %v", recorder.sql)
+	}
+}
@@ -0,0 +1,123 @@
+package duckdb
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rowConverters convert the values of columns of a database type as the driver
+// returns them into values that scan into the Go types of the fields stored in
+// such columns, with the type they scan as
+var rowConverters = map[string]struct {
+	convert  func(driver.Value) driver.Value
+	scanType reflect.Type
+}{
+	"UUID":     {uuidText, reflect.TypeOf("")},
+	"INTERVAL": {intervalDuration, reflect.TypeOf(time.Duration(0))},
+}
+
+// convertedRows returns the values of columns with a row converter converted
+type convertedRows struct {
+	driver.Rows
+	types []string
+	// end is called once the rows are closed
+	end func() error
+}
+
+// convertRows returns rows converting the values of UUID columns to text and
+// of INTERVAL columns to time.Duration, calling end
+// once they are closed unless it is nil
+func convertRows(rows driver.Rows, end func() error) driver.Rows {
+	typed, ok := rows.(driver.RowsColumnTypeDatabaseTypeName)
+	if !ok {
+		if end != nil {
+			return &convertedRows{Rows: rows, types: make([]string, len(rows.Columns())), end: end}
+		}
+		return rows
+	}
+	var (
+		types = make([]string, len(rows.Columns()))
+		found bool
+	)
+	for i := range types {
+		if name := typed.ColumnTypeDatabaseTypeName(i); rowConverters[name].convert != nil {
+			types[i], found = name, true
+		}
+	}
+	if !found && end == nil {
+		return rows
+	}
+	return &convertedRows{Rows: rows, types: types, end: end}
+}
+
+func (r *convertedRows) Close() error {
+	err := r.Rows.Close()
+	if r.end != nil {
+		if endErr := r.end(); err == nil {
+			err = endErr
+		}
+	}
+	return err
+}
+
+func (r *convertedRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for i, name := range r.types {
+		if name != "" && dest[i] != nil {
+			dest[i] = rowConverters[name].convert(dest[i])
+		}
+	}
+	return nil
+}
+
+func (r *convertedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if typed, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return typed.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *convertedRows) ColumnTypeScanType(index int) reflect.Type {
+	if name := r.types[index]; name != "" {
+		return rowConverters[name].scanType
+	}
+	if typed, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return typed.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// selectTextColumns selects the columns of queries of models with BIT or JSON
+// fields one by one, those columns cast to text: the driver cannot read BIT
+// values, and decodes JSON values, which would lose their text. Queries
+// selecting or omitting columns or joining other tables are left as they are.
+func selectTextColumns(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || stmt.SQL.Len() > 0 ||
+		len(stmt.Selects) > 0 || len(stmt.Omits) > 0 || len(stmt.Joins) > 0 {
+		return
+	}
+	if _, ok := stmt.Clauses["SELECT"]; ok {
+		return
+	}
+
+	var found bool
+	columns := make([]clause.Column, len(stmt.Schema.DBNames))
+	for i, name := range stmt.Schema.DBNames {
+		column := clause.Column{Table: clause.CurrentTable, Name: name}
+		if field := stmt.Schema.FieldsByDBName[name]; isBitField(field) || isJSONField(field) {
+			column = clause.Column{Name: stmt.Quote(column) + "::VARCHAR AS " + stmt.Quote(name), Raw: true}
+			found = true
+		}
+		columns[i] = column
+	}
+	if found {
+		stmt.AddClause(clause.Select{Distinct: stmt.Distinct, Columns: columns})
+	}
+}
@@ -0,0 +1,180 @@
+package duckdb
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DuckDB has no auto-increment columns: CreateTable backs them with a sequence
+// of their own, which the column draws its default from with nextval(). The
+// Migrator takes columns with such a default as auto-increment columns when
+// the sequence exists, creates and drops sequences as fields become or stop
+// being auto-increment, and drops them with their tables.
+
+// nextvalPattern matches the default of columns generated by a sequence, e.g.
+// nextval('"users_seq"'), capturing the name of the sequence
+var nextvalPattern = regexp.MustCompile(`^nextval\('((?:[^']|'')+)'\)$`)
+
+// sequenceOf returns the name of the sequence in the nextval() default of a
+// column, or false for other defaults
+func sequenceOf(columnDefault string) (string, bool) {
+	matches := nextvalPattern.FindStringSubmatch(columnDefault)
+	if matches == nil {
+		return "", false
+	}
+	return strings.ReplaceAll(matches[1], "''", "'"), true
+}
+
+// isAutoIncrement reports whether field gets a sequence of its own, as
+// auto-increment fields without a default of their own do
+func isAutoIncrement(field *schema.Field) bool {
+	return field.AutoIncrement && field.DefaultValue == "" && field.DefaultValueInterface == nil
+}
+
+// autoIncrementFields returns the fields of stmt that get a sequence of their
+// own
+func autoIncrementFields(stmt *gorm.Statement) (fields []*schema.Field) {
+	if stmt.Schema == nil {
+		return nil
+	}
+	for _, field := range stmt.Schema.Fields {
+		if isAutoIncrement(field) && field.DBName != "" && !field.IgnoreMigration {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// isSequenceBacked reports whether the column of field is generated by a
+// sequence, as auto-increment fields and fields defaulting to nextval() are
+func isSequenceBacked(field *schema.Field) bool {
+	_, ok := sequenceOf(field.DefaultValue)
+	return field.AutoIncrement || ok
+}
+
+// sequenceName returns the quoted name of the sequence generating the
+// auto-increment field of the table of stmt, in the schema of the table:
+// <table>_seq for ID fields and <table>_<column>_seq for others
+func (m Migrator) sequenceName(stmt *gorm.Statement, field *schema.Field) string {
+	_, _, table := m.qualifiedTable(stmt, stmt.Table)
+	if field.Name == "ID" {
+		return m.schemaObjectName(stmt, table.(string)+"_seq")
+	}
+	return m.schemaObjectName(stmt, table.(string)+"_"+field.DBName+"_seq")
+}
+
+// columnSequences returns the names of the sequences generating the columns of
+// the table of stmt, by column, leaving out nextval() defaults of sequences
+// that no longer exist
+func (m Migrator) columnSequences(stmt *gorm.Statement) (map[string]string, error) {
+	catalog, currentSchema, table := m.qualifiedTable(stmt, stmt.Table)
+	var columns []struct {
+		ColumnName    string
+		ColumnDefault string
+	}
+	if err := m.queryRaw(
+		"SELECT column_name, column_default FROM duckdb_columns() WHERE database_name = ? AND "+
+			identifierMatches("schema_name")+" AND "+identifierMatches("table_name")+" AND column_default LIKE 'nextval(%'",
+		catalog, currentSchema, table,
+	).Scan(&columns).Error; err != nil {
+		return nil, err
+	}
+
+	sequences := make(map[string]string, len(columns))
+	for _, column := range columns {
+		name, ok := sequenceOf(column.ColumnDefault)
+		if !ok {
+			continue
+		}
+		if exists, err := m.hasSequence(stmt, name); err != nil {
+			return nil, err
+		} else if exists {
+			sequences[column.ColumnName] = name
+		}
+	}
+	return sequences, nil
+}
+
+// hasSequence reports whether the sequence name exists
+func (m Migrator) hasSequence(stmt *gorm.Statement, name string) (bool, error) {
+	catalog, currentSchema, sequence := m.qualifiedTable(stmt, name)
+	var count int64
+	err := m.queryRaw(
+		"SELECT count(*) FROM duckdb_sequences() WHERE database_name = ? AND "+
+			identifierMatches("schema_name")+" AND "+identifierMatches("sequence_name"),
+		catalog, currentSchema, sequence,
+	).Scan(&count).Error
+	return count > 0, err
+}
+
+// dropSequence drops the sequence name unless the default of a column still
+// draws from it
+func (m Migrator) dropSequence(tx *gorm.DB, stmt *gorm.Statement, name string) error {
+	catalog, currentSchema, sequence := m.qualifiedTable(stmt, name)
+	var dependents int64
+	if err := m.queryRaw(
+		"SELECT count(*) FROM duckdb_dependencies() AS d JOIN duckdb_sequences() AS s ON d.objid = s.sequence_oid WHERE s.database_name = ? AND "+
+			identifierMatches("s.schema_name")+" AND "+identifierMatches("s.sequence_name"),
+		catalog, currentSchema, sequence,
+	).Scan(&dependents).Error; err != nil || dependents > 0 {
+		return err
+	}
+	return tx.Exec("DROP SEQUENCE IF EXISTS " + name).Error
+}
+
+// CreateSequence creates the sequence of the auto-increment field, starting
+// after the largest value of its column, and makes it the default of the
+// column. The column keeps its type, so serialDatabaseType is unused.
+func (m Migrator) CreateSequence(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field,
+	serialDatabaseType string) (err error) {
+	var last sql.NullInt64
+	if err := tx.Raw("SELECT max(?) FROM ?", clause.Column{Name: field.DBName}, m.CurrentTable(stmt)).Scan(&last).Error; err != nil {
+		return err
+	}
+	name := m.sequenceName(stmt, field)
+	if err := tx.Exec("CREATE SEQUENCE IF NOT EXISTS " + name + " START " + strconv.FormatInt(last.Int64+1, 10)).Error; err != nil {
+		return err
+	}
+	return tx.Exec("ALTER TABLE ? ALTER COLUMN ? SET DEFAULT ?",
+		m.CurrentTable(stmt), clause.Column{Name: field.DBName}, clause.Expr{SQL: "nextval(" + quoteString(name) + ")"}).Error
+}
+
+// UpdateSequence changes the type of the auto-increment field to
+// serialDatabaseType, which leaves its sequence as it is
+func (m Migrator) UpdateSequence(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field,
+	serialDatabaseType string) (err error) {
+	return tx.Exec("ALTER TABLE ? ALTER COLUMN ? TYPE ?",
+		m.CurrentTable(stmt), clause.Column{Name: field.DBName}, clause.Expr{SQL: serialDatabaseType}).Error
+}
+
+// DeleteSequence drops the default of the column of field that is no longer
+// auto-increment, and its sequence unless other columns use it. The column
+// keeps its type, so fileType is unused.
+func (m Migrator) DeleteSequence(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field,
+	fileType clause.Expr) (err error) {
+	name, err := m.getColumnSequenceName(tx, stmt, field)
+	if err != nil {
+		return err
+	}
+	if err := tx.Exec("ALTER TABLE ? ALTER COLUMN ? DROP DEFAULT", m.CurrentTable(stmt), clause.Column{Name: field.DBName}).Error; err != nil {
+		return err
+	}
+	if name == "" {
+		return nil
+	}
+	return m.dropSequence(tx, stmt, name)
+}
+
+// getColumnSequenceName returns the name of the sequence generating the column
+// of field, or an empty name when there is none
+func (m Migrator) getColumnSequenceName(tx *gorm.DB, stmt *gorm.Statement, field *schema.Field) (
+	sequenceName string, err error) {
+	sequences, err := m.columnSequences(stmt)
+	return sequences[field.DBName], err
+}
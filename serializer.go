@@ -0,0 +1,49 @@
+package duckdb
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// GobSerializerName is the gob serializer, storing fields in BLOB columns in a
+// compact form for opaque Go values, next to the JSON serializer:
+//
+//	type Session struct {
+//		ID    uint
+//		State State `gorm:"serializer:duckdb_gob"`
+//	}
+//
+// It is registered next to gorm's gob serializer, which is left alone for the
+// other dialectors of the program, and also gets a BLOB column.
+const GobSerializerName = "duckdb_gob"
+
+func init() {
+	schema.RegisterSerializer(GobSerializerName, GobSerializer{})
+}
+
+// GobSerializer is gorm's gob serializer writing nil pointers as NULL instead
+// of failing to encode them
+type GobSerializer struct {
+	schema.GobSerializer
+}
+
+// Value encodes the field, leaving nil pointers NULL
+func (s GobSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(fieldValue)
+	if !rv.IsValid() || rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	return s.GobSerializer.Value(ctx, field, dst, fieldValue)
+}
+
+// isBinarySerialized reports whether field uses a serializer writing bytes,
+// which need a BLOB column
+func isBinarySerialized(field *schema.Field) bool {
+	switch field.Serializer.(type) {
+	case GobSerializer, schema.GobSerializer:
+		return true
+	}
+	return isEncrypted(field)
+}
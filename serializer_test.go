@@ -0,0 +1,67 @@
+package duckdb
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+type gobState struct {
+	Step    int
+	Labels  map[string]string
+	Payload []byte
+}
+
+type gobSession struct {
+	Name  string
+	State gobState  `gorm:"serializer:duckdb_gob"`
+	Last  *gobState `gorm:"serializer:duckdb_gob"`
+}
+
+func TestGobSerializer(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&gobSession{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var dataType string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'gob_sessions' AND column_name = 'state'").Scan(&dataType).Error; err != nil || dataType != "BLOB" {
+		t.Errorf("expected a BLOB column, got %q, error %v", dataType, err)
+	}
+
+	state := gobState{Step: 3, Labels: map[string]string{"region": "eu"}, Payload: []byte{0, 0xff, 0x80}}
+	if err := db.Create(&gobSession{Name: "sql", State: state, Last: &state}).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	if err := db.Create(&gobSession{Name: "empty"}).Error; err != nil {
+		t.Fatalf("failed to create record with nil pointer, got error %v", err)
+	}
+	if _, err := AppendModels(db, []gobSession{{Name: "appender", State: state}}); err != nil {
+		t.Fatalf("failed to append record, got error %v", err)
+	}
+
+	for _, name := range []string{"sql", "appender"} {
+		var session gobSession
+		if err := db.Where("name = ?", name).Take(&session).Error; err != nil {
+			t.Fatalf("failed to find %s record, got error %v", name, err)
+		}
+		if !reflect.DeepEqual(session.State, state) {
+			t.Errorf("expected %s record to round-trip, got %+v", name, session.State)
+		}
+		if name == "sql" && (session.Last == nil || !reflect.DeepEqual(*session.Last, state)) {
+			t.Errorf("expected pointer field to round-trip, got %+v", session.Last)
+		}
+		if name == "appender" && session.Last != nil {
+			t.Errorf("expected nil pointer field to stay NULL, got %+v", session.Last)
+		}
+	}
+}
+
+func TestGobSerializer_gormGobLeftAlone(t *testing.T) {
+	if serializer, ok := schema.GetSerializer("gob"); !ok || reflect.TypeOf(serializer) != reflect.TypeOf(schema.GobSerializer{}) {
+		t.Errorf("expected gorm's gob serializer to be left registered, got %T", serializer)
+	}
+	if serializer, ok := schema.GetSerializer(GobSerializerName); !ok || reflect.TypeOf(serializer) != reflect.TypeOf(GobSerializer{}) {
+		t.Errorf("expected the gob serializer to be registered, got %T", serializer)
+	}
+}
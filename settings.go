@@ -0,0 +1,142 @@
+package duckdb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	scopedSettingsKey   = "duckdb:scoped_settings"
+	previousSettingsKey = "duckdb:previous_settings"
+	pinnedConnKey       = "duckdb:pinned_conn"
+)
+
+// WithSettings returns a scope that applies DuckDB settings for a single create,
+// query, update, delete or exec and restores their previous values afterwards:
+//
+//	db.Scopes(duckdb.WithSettings(map[string]string{"memory_limit": "2GB"})).Find(&rows)
+//
+// Outside of transactions the operation is pinned to one connection so that
+// local settings reach it. Global settings such as memory_limit and threads
+// affect the whole database while the operation runs.
+func WithSettings(settings map[string]string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.InstanceSet(scopedSettingsKey, settings)
+	}
+}
+
+// WithoutInsertionOrder returns a scope that sets preserve_insertion_order=false
+// for one operation, which reduces the memory used by large loads of unsorted
+// data, e.g. db.Scopes(duckdb.WithoutInsertionOrder()).CreateInBatches(rows, 10000)
+func WithoutInsertionOrder() func(*gorm.DB) *gorm.DB {
+	return WithSettings(map[string]string{"preserve_insertion_order": "false"})
+}
+
+func applyScopedSettings(db *gorm.DB) {
+	value, ok := db.InstanceGet(scopedSettingsKey)
+	if !ok || db.Error != nil || db.DryRun {
+		return
+	}
+	settings := value.(map[string]string)
+	for name := range settings {
+		if !settingNamePattern.MatchString(name) {
+			db.AddError(fmt.Errorf("invalid setting name %q", name))
+			return
+		}
+	}
+
+	if !pinConnection(db) {
+		return
+	}
+
+	previous := make(map[string]sql.NullString, len(settings))
+	for name := range settings {
+		var value sql.NullString
+		if err := db.Statement.ConnPool.QueryRowContext(
+			db.Statement.Context, "SELECT current_setting(?)::VARCHAR", name,
+		).Scan(&value); err != nil {
+			db.AddError(err)
+			return
+		}
+		previous[name] = value
+	}
+	db.InstanceSet(previousSettingsKey, previous)
+
+	db.AddError(applySettings(db.Statement.ConnPool, settings))
+}
+
+func restoreScopedSettings(db *gorm.DB) {
+	if value, ok := db.InstanceGet(previousSettingsKey); ok {
+		previous := value.(map[string]sql.NullString)
+		settings := make(map[string]string, len(previous))
+		for name, value := range previous {
+			if value.Valid {
+				settings[name] = value.String
+			} else if _, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, "RESET "+name); err != nil {
+				db.AddError(err)
+			}
+		}
+		db.AddError(applySettings(db.Statement.ConnPool, settings))
+	}
+
+	unpinConnection(db)
+}
+
+// pinnedConn is the connection a statement is pinned to, and the pool it was
+// taken from
+type pinnedConn struct {
+	pool gorm.ConnPool
+	conn *sql.Conn
+}
+
+// pinConnection runs the rest of the statement on a single connection of the
+// pool, for state local to a connection such as settings and temporary tables.
+// Transactions and pinned statements are left as they are. It reports whether the statement may go on.
+//
+// The connection is kept in the statement instance, as gorm sets the pool back
+// on the statement when it commits the transaction it begins for creates,
+// updates and deletes; see usePinnedConnection.
+func pinConnection(db *gorm.DB) bool {
+	pool := db.Statement.ConnPool
+	sqlDB, ok := pool.(*sql.DB)
+	if p, isPool := pool.(*connPool); isPool {
+		sqlDB, ok = p.DB, true
+	}
+	if ok {
+		conn, err := sqlDB.Conn(db.Statement.Context)
+		if err != nil {
+			db.AddError(err)
+			return false
+		}
+		db.InstanceSet(pinnedConnKey, &pinnedConn{pool: pool, conn: conn})
+		db.Statement.ConnPool = conn
+	}
+	return true
+}
+
+// usePinnedConnection runs the statement on the connection pinned by
+// pinConnection again, once its transaction is done, to clean up the state
+// left on it
+func usePinnedConnection(db *gorm.DB) {
+	if pinned := pinnedConnOf(db); pinned != nil {
+		db.Statement.ConnPool = pinned.conn
+	}
+}
+
+// unpinConnection returns the connection pinned by pinConnection to the pool
+func unpinConnection(db *gorm.DB) {
+	if pinned := pinnedConnOf(db); pinned != nil {
+		// the statement may run again, without pinning
+		db.InstanceSet(pinnedConnKey, (*pinnedConn)(nil))
+		db.AddError(pinned.conn.Close())
+		db.Statement.ConnPool = pinned.pool
+	}
+}
+
+func pinnedConnOf(db *gorm.DB) *pinnedConn {
+	value, _ := db.InstanceGet(pinnedConnKey)
+	pinned, _ := value.(*pinnedConn)
+	return pinned
+}
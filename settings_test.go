@@ -0,0 +1,101 @@
+package duckdb
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestWithSettings(t *testing.T) {
+	db := openTestDB(t, Config{Settings: map[string]string{"threads": "1"}})
+
+	var threads int
+	if err := db.Scopes(WithSettings(map[string]string{"threads": "2"})).
+		Raw("SELECT current_setting('threads')").Find(&threads).Error; err != nil {
+		t.Fatalf("failed to query with settings, got error %v", err)
+	}
+	if threads != 2 {
+		t.Errorf("expected threads to be 2 during the query, got %v", threads)
+	}
+
+	if err := db.Raw("SELECT current_setting('threads')").Scan(&threads).Error; err != nil || threads != 1 {
+		t.Errorf("expected threads to be restored to 1, got %v, error %v", threads, err)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Scopes(WithSettings(map[string]string{"threads": "3"})).
+			Raw("SELECT current_setting('threads')").Find(&threads).Error
+	})
+	if err != nil || threads != 3 {
+		t.Errorf("expected threads to be 3 inside the transaction, got %v, error %v", threads, err)
+	}
+
+	if err := db.Scopes(WithSettings(map[string]string{"threads; DROP TABLE x": "1"})).Exec("SELECT 1").Error; err == nil {
+		t.Errorf("expected invalid setting names to be rejected")
+	}
+}
+
+type insertionOrderRecord struct {
+	ID int
+}
+
+func TestWithoutInsertionOrder(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE TABLE insertion_order_records (id INTEGER)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	var preserve bool
+	records := []insertionOrderRecord{{ID: 1}, {ID: 2}, {ID: 3}}
+	if err := db.Scopes(WithoutInsertionOrder()).CreateInBatches(records, 2).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+	if err := db.Scopes(WithoutInsertionOrder()).Raw("SELECT current_setting('preserve_insertion_order')").Find(&preserve).Error; err != nil || preserve {
+		t.Errorf("expected preserve_insertion_order to be disabled, got %v, error %v", preserve, err)
+	}
+	if err := db.Raw("SELECT current_setting('preserve_insertion_order')").Find(&preserve).Error; err != nil || !preserve {
+		t.Errorf("expected preserve_insertion_order to be restored, got %v, error %v", preserve, err)
+	}
+
+	db = openTestDB(t, Config{DisableInsertionOrder: true})
+	if err := db.Raw("SELECT current_setting('preserve_insertion_order')").Find(&preserve).Error; err != nil || preserve {
+		t.Errorf("expected preserve_insertion_order to be disabled, got %v, error %v", preserve, err)
+	}
+}
+
+type settingsRecord struct {
+	ID   int
+	Name string
+}
+
+func TestWithSettings_writes(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&settingsRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get the pool, got error %v", err)
+	}
+	// a single connection, which the local setting must be restored on
+	sqlDB.SetMaxOpenConns(1)
+
+	scope := WithSettings(map[string]string{"errors_as_json": "true"})
+	writes := map[string]func() error{
+		"create": func() error { return db.Scopes(scope).Create(&settingsRecord{ID: 1, Name: "a"}).Error },
+		"update": func() error { return db.Scopes(scope).Model(&settingsRecord{ID: 1}).Update("name", "b").Error },
+		"delete": func() error { return db.Scopes(scope).Delete(&settingsRecord{ID: 1}).Error },
+	}
+	for _, name := range []string{"create", "update", "delete"} {
+		if err := writes[name](); err != nil {
+			t.Fatalf("failed to %s, got error %v", name, err)
+		}
+		if inUse := sqlDB.Stats().InUse; inUse != 0 {
+			t.Errorf("expected the connection of %s to be returned to the pool, got %d in use", name, inUse)
+		}
+		var asJSON bool
+		if err := db.Raw("SELECT current_setting('errors_as_json')").Scan(&asJSON).Error; err != nil || asJSON {
+			t.Errorf("expected errors_as_json to be restored after %s, got %v, error %v", name, asJSON, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package duckdb
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	queryStartKey    = "duckdb:query_start"
+	profileOutputKey = "duckdb:profile_output"
+)
+
+// QueryStats are the execution statistics of a statement, reported to
+// Config.QueryStats after each create, query, update, delete and exec
+type QueryStats struct {
+	// SQL is the statement with its placeholders
+	SQL      string
+	Duration time.Duration
+	// RowsAffected is the number of rows returned by a query or changed by
+	// other statements, as reported by gorm
+	RowsAffected int64
+	Error        error
+
+	// Profiled reports whether the following were read from the output of the
+	// DuckDB profiler, see Config.ProfileQueries
+	Profiled     bool
+	RowsReturned int64
+	RowsScanned  int64
+	// PeakMemory is the peak buffer memory in bytes, which DuckDB reports from
+	// v1.2.0 on
+	PeakMemory int64
+}
+
+// profilingOutput is the part of the JSON profiling output read into QueryStats
+type profilingOutput struct {
+	RowsReturned           int64 `json:"rows_returned"`
+	CumulativeRowsScanned  int64 `json:"cumulative_rows_scanned"`
+	SystemPeakBufferMemory int64 `json:"system_peak_buffer_memory"`
+}
+
+// prepareQueryStats starts timing the statement and, with Config.ProfileQueries,
+// enables the JSON profiler for it through the scoped settings
+func (dialector Dialector) prepareQueryStats(db *gorm.DB) {
+	if dialector.QueryStats == nil || db.Error != nil || db.DryRun {
+		return
+	}
+	db.InstanceSet(queryStartKey, time.Now())
+	if !dialector.ProfileQueries {
+		return
+	}
+
+	file, err := createTemp(db, "duckdb-profile-*.json")
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	file.Close()
+	db.InstanceSet(profileOutputKey, file.Name())
+
+	settings := map[string]string{"enable_profiling": "json", "profiling_output": file.Name()}
+	if dialector.Supports(FeaturePeakMemoryMetric) {
+		settings["custom_profiling_settings"] = `{"ROWS_RETURNED": "true", "CUMULATIVE_ROWS_SCANNED": "true", "SYSTEM_PEAK_BUFFER_MEMORY": "true"}`
+	}
+	if value, ok := db.InstanceGet(scopedSettingsKey); ok {
+		maps.Copy(settings, value.(map[string]string))
+	}
+	db.InstanceSet(scopedSettingsKey, settings)
+}
+
+// reportQueryStats passes the statistics of the statement to Config.QueryStats
+func (dialector Dialector) reportQueryStats(db *gorm.DB) {
+	start, ok := db.InstanceGet(queryStartKey)
+	if !ok {
+		return
+	}
+	stats := QueryStats{
+		SQL:          db.Statement.SQL.String(),
+		Duration:     time.Since(start.(time.Time)),
+		RowsAffected: db.RowsAffected,
+		Error:        db.Error,
+	}
+
+	if path, ok := db.InstanceGet(profileOutputKey); ok {
+		if data, err := os.ReadFile(path.(string)); err == nil && db.Error == nil {
+			var output profilingOutput
+			if json.Unmarshal(data, &output) == nil {
+				stats.Profiled = true
+				stats.RowsReturned = output.RowsReturned
+				stats.RowsScanned = output.CumulativeRowsScanned
+				stats.PeakMemory = output.SystemPeakBufferMemory
+			}
+		}
+		os.Remove(path.(string))
+	}
+	dialector.QueryStats(db.Statement.Context, stats)
+}
@@ -0,0 +1,90 @@
+package duckdb
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestQueryStats(t *testing.T) {
+	for _, profile := range []bool{false, true} {
+		var (
+			mu    sync.Mutex
+			stats []QueryStats
+		)
+		dir := t.TempDir()
+		db := openTestDB(t, Config{TempDirectory: dir, ProfileQueries: profile, QueryStats: func(ctx context.Context, s QueryStats) {
+			mu.Lock()
+			defer mu.Unlock()
+			stats = append(stats, s)
+		}})
+		if err := db.Exec("CREATE TABLE stats_records AS SELECT range AS id FROM range(1000)").Error; err != nil {
+			t.Fatalf("failed to create table, got error %v", err)
+		}
+
+		var ids []int64
+		if err := db.Table("stats_records").Where("id % 10 = 0").Pluck("id", &ids).Error; err != nil || len(ids) != 100 {
+			t.Fatalf("failed to query, got %d rows, error %v", len(ids), err)
+		}
+
+		if len(stats) != 2 {
+			t.Fatalf("expected 2 statements to be reported, got %+v", stats)
+		}
+		query := stats[1]
+		if !strings.HasPrefix(query.SQL, "SELECT") || query.RowsAffected != 100 || query.Duration <= 0 || query.Error != nil {
+			t.Errorf("expected the statistics of the query, got %+v", query)
+		}
+		if query.Profiled != profile {
+			t.Errorf("expected profiled to be %v, got %+v", profile, query)
+		}
+		if profile && (query.RowsReturned != 100 || query.RowsScanned != 1000) {
+			t.Errorf("expected the rows returned and scanned by the profiler, got %+v", query)
+		}
+
+		if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+			t.Errorf("expected profiles to be removed, got %v, error %v", entries, err)
+		}
+		var enabled *string
+		if err := db.Raw("SELECT current_setting('enable_profiling')::VARCHAR").Scan(&enabled).Error; err != nil || enabled != nil {
+			t.Errorf("expected the profiler to be disabled afterwards, got %v, error %v", enabled, err)
+		}
+	}
+}
+
+type statsRecord struct {
+	ID   int
+	Name string
+}
+
+func TestQueryStats_profileWrites(t *testing.T) {
+	var stats []QueryStats
+	db := openTestDB(t, Config{TempDirectory: t.TempDir(), ProfileQueries: true, QueryStats: func(ctx context.Context, s QueryStats) {
+		stats = append(stats, s)
+	}})
+	if err := db.AutoMigrate(&statsRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get the pool, got error %v", err)
+	}
+	// a single connection, which the profiler must be disabled on again
+	sqlDB.SetMaxOpenConns(1)
+
+	stats = nil
+	if err := db.Create(&statsRecord{ID: 1, Name: "a"}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	if len(stats) != 1 || !strings.HasPrefix(stats[0].SQL, "INSERT") || !stats[0].Profiled {
+		t.Errorf("expected the insert to be profiled, got %+v", stats)
+	}
+	if inUse := sqlDB.Stats().InUse; inUse != 0 {
+		t.Errorf("expected the connection to be returned to the pool, got %d in use", inUse)
+	}
+	var enabled *string
+	if err := db.Raw("SELECT current_setting('enable_profiling')::VARCHAR").Scan(&enabled).Error; err != nil || enabled != nil {
+		t.Errorf("expected the profiler to be disabled afterwards, got %v, error %v", enabled, err)
+	}
+}
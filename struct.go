@@ -0,0 +1,380 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// EmbeddedStructSerializerName is the serializer storing a struct field in a
+// single STRUCT column instead of a column per field, as gorm embeds structs:
+//
+//	type User struct {
+//		ID      uint
+//		Address Address `gorm:"serializer:embeddedStruct"`
+//	}
+//	// CREATE TABLE "users" ("id" UBIGINT, "address" STRUCT("street" VARCHAR, "zip_code" VARCHAR), ...)
+//
+// The fields of the struct are named like columns, by their column tag or in
+// snake case, and may be structs, slices and maps of string keys themselves.
+// Byte slices are kept as base64 text. The field must not be anonymous, as gorm
+// flattens anonymous structs. DuckDB rejects updates of STRUCT columns holding
+// lists in tables with a primary key as duplicate keys.
+//
+// Struct types implementing sql.Scanner and driver.Valuer themselves may take
+// the struct type tag instead, `gorm:"type:struct"`, to get a STRUCT column
+// they scan from maps and value as JSON text.
+const EmbeddedStructSerializerName = "embeddedStruct"
+
+func init() {
+	schema.RegisterSerializer(EmbeddedStructSerializerName, EmbeddedStructSerializer{})
+}
+
+// EmbeddedStructSerializer converts struct fields to and from STRUCT columns;
+// values are bound as JSON and cast to the column's type
+type EmbeddedStructSerializer struct{}
+
+// Scan assigns the STRUCT value read from the database to the field
+func (EmbeddedStructSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		if err := assignStructValue(fieldValue.Elem(), dbValue); err != nil {
+			return fmt.Errorf("failed to scan %s: %w", field.Name, err)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+// Value returns the field as JSON, leaving nil pointers NULL
+func (EmbeddedStructSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value := structJSONValue(reflect.ValueOf(fieldValue))
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// isEmbeddedStruct reports whether field is stored in a STRUCT column, as
+// fields with the embeddedStruct serializer or the struct type tag are. The
+// type tag names the column type of structs scanning and valuing themselves.
+func isEmbeddedStruct(field *schema.Field) bool {
+	_, ok := field.Serializer.(EmbeddedStructSerializer)
+	return ok || strings.EqualFold(string(field.DataType), "struct")
+}
+
+// castsJSON reports whether the values of field are bound as JSON cast to the
+// column's type, as those of the STRUCT, LIST, MAP and ARRAY serializers are
+func castsJSON(field *schema.Field) bool {
+	switch field.Serializer.(type) {
+	case EmbeddedStructSerializer, ListSerializer, MapSerializer, ArraySerializer:
+		return true
+	}
+	return false
+}
+
+// structField is a field of a struct stored in a STRUCT column
+type structField struct {
+	name  string
+	index int
+}
+
+func structFields(t reflect.Type) (fields []structField) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		settings := schema.ParseTagSetting(f.Tag.Get("gorm"), ";")
+		if _, ok := settings["-"]; ok {
+			continue
+		}
+		name := settings["COLUMN"]
+		if name == "" {
+			name = schema.NamingStrategy{}.ColumnName("", f.Name)
+		}
+		fields = append(fields, structField{name: name, index: i})
+	}
+	return fields
+}
+
+// structColumnType returns the DuckDB type of values of t in a STRUCT column
+func structColumnType(t reflect.Type) (string, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return "TIMESTAMP", nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return "VARCHAR", nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN", nil
+	case reflect.Int8:
+		return "TINYINT", nil
+	case reflect.Int16:
+		return "SMALLINT", nil
+	case reflect.Int32:
+		return "INTEGER", nil
+	case reflect.Int, reflect.Int64:
+		return "BIGINT", nil
+	case reflect.Uint8:
+		return "UTINYINT", nil
+	case reflect.Uint16:
+		return "USMALLINT", nil
+	case reflect.Uint32:
+		return "UINTEGER", nil
+	case reflect.Uint, reflect.Uint64:
+		return "UBIGINT", nil
+	case reflect.Float32:
+		return "FLOAT", nil
+	case reflect.Float64:
+		return "DOUBLE", nil
+	case reflect.String:
+		return "VARCHAR", nil
+	case reflect.Slice:
+		elem, err := structColumnType(t.Elem())
+		return elem + "[]", err
+	case reflect.Array:
+		elem, err := structColumnType(t.Elem())
+		return elem + "[" + strconv.Itoa(t.Len()) + "]", err
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			break
+		}
+		elem, err := structColumnType(t.Elem())
+		return "MAP(VARCHAR, " + elem + ")", err
+	case reflect.Struct:
+		fields := structFields(t)
+		if len(fields) == 0 {
+			return "", fmt.Errorf("struct %s has no exported fields", t)
+		}
+		members := make([]string, len(fields))
+		for i, field := range fields {
+			fieldType, err := structColumnType(t.Field(field.index).Type)
+			if err != nil {
+				return "", fmt.Errorf("%s.%s: %w", t, t.Field(field.index).Name, err)
+			}
+			members[i] = quoteIdentifier(field.name) + " " + fieldType
+		}
+		return "STRUCT(" + strings.Join(members, ", ") + ")", nil
+	}
+	return "", fmt.Errorf("unsupported type %s", t)
+}
+
+// structJSONValue returns v in the form marshaled to JSON for a STRUCT column,
+// with the keys of its fields and times in UTC
+func structJSONValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.UTC().Format("2006-01-02 15:04:05.999999")
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		object := make(map[string]interface{}, v.NumField())
+		for _, field := range structFields(v.Type()) {
+			object[field.name] = structJSONValue(v.Field(field.index))
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = structJSONValue(v.Index(i))
+		}
+		return list
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		object := make(map[string]interface{}, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			object[iter.Key().String()] = structJSONValue(iter.Value())
+		}
+		return object
+	}
+	return v.Interface()
+}
+
+// assignStructValue assigns src, a value of a STRUCT column or of one of its
+// members as returned by the driver, to dst
+func assignStructValue(dst reflect.Value, src interface{}) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Pointer {
+		value := reflect.New(dst.Type().Elem())
+		if err := assignStructValue(value.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(value)
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	switch {
+	case dst.Type() == reflect.TypeOf(time.Time{}):
+		if t, ok := src.(time.Time); ok {
+			dst.Set(reflect.ValueOf(t))
+			return nil
+		}
+	case dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Uint8:
+		if s, ok := src.(string); ok {
+			data, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return err
+			}
+			dst.SetBytes(data)
+			return nil
+		}
+	case dst.Kind() == reflect.Struct:
+		object, ok := src.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, field := range structFields(dst.Type()) {
+			if err := assignStructValue(dst.Field(field.index), object[field.name]); err != nil {
+				return fmt.Errorf("%s: %w", field.name, err)
+			}
+		}
+		return nil
+	case dst.Kind() == reflect.Slice && (sv.Kind() == reflect.Slice || sv.Kind() == reflect.Array):
+		list := reflect.MakeSlice(dst.Type(), sv.Len(), sv.Len())
+		for i := 0; i < sv.Len(); i++ {
+			if err := assignStructValue(list.Index(i), sv.Index(i).Interface()); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		dst.Set(list)
+		return nil
+	case dst.Kind() == reflect.Array && (sv.Kind() == reflect.Slice || sv.Kind() == reflect.Array):
+		if sv.Len() != dst.Len() {
+			return fmt.Errorf("cannot assign %d elements to %s", sv.Len(), dst.Type())
+		}
+		for i := 0; i < sv.Len(); i++ {
+			if err := assignStructValue(dst.Index(i), sv.Index(i).Interface()); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return nil
+	case dst.Kind() == reflect.Map && sv.Kind() == reflect.Map:
+		object := reflect.MakeMapWithSize(dst.Type(), sv.Len())
+		for iter := sv.MapRange(); iter.Next(); {
+			key, value := reflect.New(dst.Type().Key()).Elem(), reflect.New(dst.Type().Elem()).Elem()
+			if err := assignStructValue(key, iter.Key().Interface()); err != nil {
+				return err
+			}
+			if err := assignStructValue(value, iter.Value().Interface()); err != nil {
+				return err
+			}
+			object.SetMapIndex(key, value)
+		}
+		dst.Set(object)
+		return nil
+	case dst.Kind() == reflect.String:
+		if sv.Kind() == reflect.String {
+			dst.SetString(sv.String())
+			return nil
+		}
+	case sv.Kind() != reflect.String && sv.Type().ConvertibleTo(dst.Type()):
+		dst.Set(sv.Convert(dst.Type()))
+		return nil
+	}
+	return fmt.Errorf("cannot assign %T to %s", src, dst.Type())
+}
+
+// castStructValues binds the values of STRUCT, LIST, MAP and ARRAY columns as
+// JSON cast to the column's type, as the driver cannot bind these values
+func castStructValues(stmt *gorm.Statement, values clause.Values) clause.Values {
+	if stmt.Schema == nil {
+		return values
+	}
+	var indexes []int
+	for i, column := range values.Columns {
+		if field := stmt.Schema.LookUpField(column.Name); field != nil && castsJSON(field) {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 {
+		return values
+	}
+
+	rows := make([][]interface{}, len(values.Values))
+	for i, row := range values.Values {
+		rows[i] = append([]interface{}{}, row...)
+		for _, index := range indexes {
+			rows[i][index] = clause.Expr{SQL: "?::JSON", Vars: []interface{}{row[index]}}
+		}
+	}
+	values.Values = rows
+	return values
+}
+
+// buildSet writes the SET clause, binding values of STRUCT, LIST, MAP and ARRAY
+// columns like castStructValues and others like bindValues
+func (dialector Dialector) buildSet(c clause.Clause, builder clause.Builder) {
+	if set, ok := c.Expression.(clause.Set); ok {
+		if stmt, ok := builder.(*gorm.Statement); ok && stmt.Schema != nil {
+			assignments := make(clause.Set, len(set))
+			for i, assignment := range set {
+				assignments[i] = assignment
+				field := stmt.Schema.LookUpField(assignment.Column.Name)
+				value := assignment.Value
+				if field != nil {
+					value = bindValue(field, value)
+				}
+				assignments[i].Value = sensitive(field, value)
+				if field == nil || !castsJSON(field) {
+					continue
+				}
+				switch value := assignment.Value.(type) {
+				case clause.Expression:
+				case driver.Valuer:
+					assignments[i].Value = clause.Expr{SQL: "?::JSON", Vars: []interface{}{value}}
+				default:
+					data, err := field.Serializer.Value(stmt.Context, field, stmt.ReflectValue, value)
+					if err != nil {
+						stmt.AddError(err)
+						return
+					}
+					assignments[i].Value = clause.Expr{SQL: "?::JSON", Vars: []interface{}{data}}
+				}
+			}
+			c.Expression = assignments
+		}
+	}
+	c.Builder = nil
+	c.Build(builder)
+}
@@ -0,0 +1,166 @@
+package duckdb
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type structGeo struct {
+	Lat float64
+	Lng float64
+}
+
+type structAddress struct {
+	Street  string
+	ZipCode string `gorm:"column:zip"`
+	Geo     structGeo
+	Tags    []string
+	Since   time.Time
+	Note    *string
+	secret  string
+	Ignored string `gorm:"-"`
+}
+
+type structCustomer struct {
+	ID       uint
+	Name     string
+	Address  structAddress  `gorm:"serializer:embeddedStruct"`
+	Previous *structAddress `gorm:"serializer:embeddedStruct"`
+}
+
+func TestEmbeddedStructSerializer(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&structCustomer{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var dataType string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'struct_customers' AND column_name = 'address'").Scan(&dataType).Error; err != nil {
+		t.Fatalf("failed to query column type, got error %v", err)
+	}
+	if !strings.HasPrefix(dataType, "STRUCT(street VARCHAR, zip VARCHAR, geo STRUCT(lat DOUBLE, lng DOUBLE), tags VARCHAR[]") {
+		t.Errorf("expected a STRUCT column, got %q", dataType)
+	}
+
+	note := "ring twice"
+	address := structAddress{
+		Street:  "Main St",
+		ZipCode: "12345",
+		Geo:     structGeo{Lat: 52.5, Lng: 13.4},
+		Tags:    []string{"home", "billing"},
+		Since:   time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+		Note:    &note,
+	}
+	customers := []structCustomer{{Name: "alice", Address: address, Previous: &address}, {Name: "bob"}}
+	if err := db.Create(&customers).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+
+	var alice structCustomer
+	if err := db.Where("name = ?", "alice").Take(&alice).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if !reflect.DeepEqual(alice.Address, address) || alice.Previous == nil || !reflect.DeepEqual(*alice.Previous, address) {
+		t.Errorf("expected address to round-trip, got %+v and %+v", alice.Address, alice.Previous)
+	}
+	var zip string
+	if err := db.Raw("SELECT address.zip FROM struct_customers WHERE name = 'alice'").Scan(&zip).Error; err != nil || zip != "12345" {
+		t.Errorf("expected struct member to be queryable, got %q, error %v", zip, err)
+	}
+
+	var bob structCustomer
+	if err := db.Where("name = ?", "bob").Take(&bob).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if bob.Previous != nil || bob.Address.Tags != nil {
+		t.Errorf("expected nil values to stay NULL, got %+v and %+v", bob.Address, bob.Previous)
+	}
+}
+
+type structPlace struct {
+	ID   uint
+	Name string
+	Geo  structGeo  `gorm:"serializer:embeddedStruct"`
+	Last *structGeo `gorm:"serializer:embeddedStruct"`
+}
+
+func TestEmbeddedStructSerializer_update(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&structPlace{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	place := structPlace{Name: "office", Geo: structGeo{Lat: 1, Lng: 2}}
+	if err := db.Create(&place).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+
+	moved := structGeo{Lat: 3, Lng: 4}
+	if err := db.Model(&place).Update("last", moved).Error; err != nil {
+		t.Fatalf("failed to update record, got error %v", err)
+	}
+	place.Geo = moved
+	if err := db.Save(&place).Error; err != nil {
+		t.Fatalf("failed to save record, got error %v", err)
+	}
+	var found structPlace
+	if err := db.Take(&found, place.ID).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if found.Geo != moved || found.Last == nil || *found.Last != moved {
+		t.Errorf("expected updated values, got %+v and %+v", found.Geo, found.Last)
+	}
+}
+
+type structPoint struct {
+	X, Y float64
+}
+
+func (p *structPoint) Scan(src interface{}) error {
+	values, ok := src.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("cannot scan %T into structPoint", src)
+	}
+	p.X, _ = values["x"].(float64)
+	p.Y, _ = values["y"].(float64)
+	return nil
+}
+
+func (p structPoint) Value() (driver.Value, error) {
+	data, err := json.Marshal(map[string]float64{"x": p.X, "y": p.Y})
+	return string(data), err
+}
+
+type structShape struct {
+	ID     uint
+	Center structPoint `gorm:"type:struct"`
+}
+
+func TestStructTypeTag(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&structShape{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var dataType string
+	if err := db.Raw("SELECT data_type FROM duckdb_columns() WHERE table_name = 'struct_shapes' AND column_name = 'center'").Scan(&dataType).Error; err != nil {
+		t.Fatalf("failed to query column type, got error %v", err)
+	}
+	if dataType != "STRUCT(x DOUBLE, y DOUBLE)" {
+		t.Errorf("expected a STRUCT column, got %q", dataType)
+	}
+
+	shape := structShape{Center: structPoint{X: 1.5, Y: -2}}
+	if err := db.Create(&shape).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+	var found structShape
+	if err := db.First(&found, shape.ID).Error; err != nil {
+		t.Fatalf("failed to find record, got error %v", err)
+	}
+	if found != shape {
+		t.Errorf("expected %+v, got %+v", shape, found)
+	}
+}
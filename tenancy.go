@@ -0,0 +1,162 @@
+package duckdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TenantMode is where a Tenancy keeps the tables of a tenant
+type TenantMode int
+
+const (
+	// TenantSchema keeps each tenant in a schema of the database
+	TenantSchema TenantMode = iota
+	// TenantDatabase keeps each tenant in its own database file, attached on
+	// first use
+	TenantDatabase
+)
+
+// Tenancy routes the statements of a session to the tables of a tenant, for
+// backends serving each tenant from its own schema or database file:
+//
+//	tenancy := &duckdb.Tenancy{Mode: duckdb.TenantDatabase, Dir: "/var/lib/tenants"}
+//	if err := tenancy.AutoMigrate(db, []string{"acme", "globex"}, &Order{}); err != nil {
+//		...
+//	}
+//	tx, err := tenancy.Session(db, "acme")
+//	tx.Find(&orders) // SELECT * FROM "tenant_acme"."orders"
+//
+// The table of the model is rewritten for creates, queries, updates and
+// deletes; raw SQL and hand-written joins are not.
+type Tenancy struct {
+	// Mode is where tenants are kept, a schema by default
+	Mode TenantMode
+	// Dir is the directory of the database files of TenantDatabase, named
+	// <tenant>.duckdb
+	Dir string
+	// Prefix is the name of the schema or attached database of a tenant before
+	// its ID, tenant_ by default
+	Prefix string
+
+	mu       sync.Mutex
+	prepared map[string]bool
+}
+
+// tenantContextKey holds the qualifier of the tables of a tenant session
+type tenantContextKey struct{}
+
+// Session returns a session whose statements use the tables of tenant,
+// creating its schema or attaching its database first
+func (t *Tenancy) Session(db *gorm.DB, tenant string) (*gorm.DB, error) {
+	ctx, err := t.Context(db, db.Statement.Context, tenant)
+	if err != nil {
+		return nil, err
+	}
+	return db.Session(&gorm.Session{Context: ctx}), nil
+}
+
+// Context returns ctx routing the statements of sessions using it to the tables
+// of tenant, e.g. for a request context passed to WithContext
+func (t *Tenancy) Context(db *gorm.DB, ctx context.Context, tenant string) (context.Context, error) {
+	if err := t.prepare(db, tenant); err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, tenantContextKey{}, quoteIdentifier(t.namespace(tenant))), nil
+}
+
+// AutoMigrate migrates models in the schema or database of each tenant
+func (t *Tenancy) AutoMigrate(db *gorm.DB, tenants []string, models ...interface{}) error {
+	for _, tenant := range tenants {
+		if err := t.prepare(db, tenant); err != nil {
+			return err
+		}
+		// the Migrator resolves tables by the default catalog and schema, which
+		// USE changes for the pinned connection only
+		if err := db.Connection(func(tx *gorm.DB) (err error) {
+			tx = tx.Session(&gorm.Session{NewDB: true})
+			var catalog, schema string
+			if err := tx.Raw("SELECT CURRENT_DATABASE(), CURRENT_SCHEMA()").Row().Scan(&catalog, &schema); err != nil {
+				return err
+			}
+			if err := tx.Exec("USE " + t.useTarget(catalog, tenant)).Error; err != nil {
+				return err
+			}
+			defer func() {
+				if useErr := tx.Exec("USE " + quoteIdentifier(catalog) + "." + quoteIdentifier(schema)).Error; err == nil {
+					err = useErr
+				}
+			}()
+			return tx.AutoMigrate(models...)
+		}); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant, err)
+		}
+	}
+	return nil
+}
+
+func (t *Tenancy) namespace(tenant string) string {
+	if t.Prefix == "" {
+		return "tenant_" + tenant
+	}
+	return t.Prefix + tenant
+}
+
+func (t *Tenancy) useTarget(catalog, tenant string) string {
+	if t.Mode == TenantDatabase {
+		return quoteIdentifier(t.namespace(tenant)) + ".main"
+	}
+	return quoteIdentifier(catalog) + "." + quoteIdentifier(t.namespace(tenant))
+}
+
+// prepare creates the schema or attaches the database of tenant once
+func (t *Tenancy) prepare(db *gorm.DB, tenant string) error {
+	if tenant == "" || tenant == "." || tenant == ".." || strings.ContainsAny(tenant, `/\`) {
+		return fmt.Errorf("invalid tenant %q", tenant)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.prepared[tenant] {
+		return nil
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true})
+	switch t.Mode {
+	case TenantSchema:
+		if err := tx.Exec("CREATE SCHEMA IF NOT EXISTS " + quoteIdentifier(t.namespace(tenant))).Error; err != nil {
+			return err
+		}
+	case TenantDatabase:
+		if t.Dir == "" {
+			return errors.New("duckdb: Tenancy.Dir is required for TenantDatabase")
+		}
+		path := filepath.Join(t.Dir, tenant+".duckdb")
+		if err := tx.Exec("ATTACH IF NOT EXISTS " + quoteString(path) + " AS " + quoteIdentifier(t.namespace(tenant))).Error; err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown tenant mode %d", t.Mode)
+	}
+
+	if t.prepared == nil {
+		t.prepared = map[string]bool{}
+	}
+	t.prepared[tenant] = true
+	return nil
+}
+
+// routeTenant qualifies the table of the statement by the tenant of its context
+func routeTenant(db *gorm.DB) {
+	qualifier, ok := db.Statement.Context.Value(tenantContextKey{}).(string)
+	if !ok || db.Statement.TableExpr != nil || db.Statement.Table == "" {
+		return
+	}
+	db.Statement.TableExpr = &clause.Expr{SQL: qualifier + "." + db.Statement.Quote(db.Statement.Table)}
+}
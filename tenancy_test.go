@@ -0,0 +1,61 @@
+package duckdb
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+type tenantOrder struct {
+	ID     int `gorm:"primaryKey;autoIncrement:false"`
+	Amount float64
+}
+
+func TestTenancy(t *testing.T) {
+	for _, mode := range []TenantMode{TenantSchema, TenantDatabase} {
+		db := openTestDB(t, Config{})
+		tenancy := &Tenancy{Mode: mode, Dir: t.TempDir()}
+		if err := tenancy.AutoMigrate(db, []string{"acme", "globex"}, &tenantOrder{}); err != nil {
+			t.Fatalf("mode %d: failed to migrate tenants, got error %v", mode, err)
+		}
+		if db.Migrator().HasTable(&tenantOrder{}) {
+			t.Errorf("mode %d: expected no table outside the tenants", mode)
+		}
+
+		acme, err := tenancy.Session(db, "acme")
+		if err != nil {
+			t.Fatalf("mode %d: failed to open tenant, got error %v", mode, err)
+		}
+		globex, _ := tenancy.Session(db, "globex")
+		if err := acme.Create(&[]tenantOrder{{ID: 1, Amount: 10}, {ID: 2, Amount: 20}}).Error; err != nil {
+			t.Fatalf("mode %d: failed to create, got error %v", mode, err)
+		}
+		if err := globex.Create(&tenantOrder{ID: 1, Amount: 99}).Error; err != nil {
+			t.Fatalf("mode %d: failed to create, got error %v", mode, err)
+		}
+		if err := acme.Model(&tenantOrder{}).Where("id = ?", 2).Update("amount", 25).Error; err != nil {
+			t.Errorf("mode %d: failed to update, got error %v", mode, err)
+		}
+		if err := globex.Delete(&tenantOrder{ID: 1}).Error; err != nil {
+			t.Errorf("mode %d: failed to delete, got error %v", mode, err)
+		}
+
+		var orders []tenantOrder
+		if err := acme.Order("id").Find(&orders).Error; err != nil || len(orders) != 2 || orders[1].Amount != 25 {
+			t.Errorf("mode %d: expected the orders of acme, got %+v, error %v", mode, orders, err)
+		}
+		var count int64
+		if err := globex.Model(&tenantOrder{}).Count(&count).Error; err != nil || count != 0 {
+			t.Errorf("mode %d: expected no orders for globex, got %v, error %v", mode, count, err)
+		}
+
+		var catalog string
+		if err := db.Raw("SELECT CURRENT_DATABASE()").Scan(&catalog).Error; err != nil || catalog != "memory" {
+			t.Errorf("mode %d: expected the default catalog to be restored, got %q, error %v", mode, catalog, err)
+		}
+	}
+
+	tenancy := &Tenancy{Mode: TenantDatabase, Dir: t.TempDir()}
+	if _, err := tenancy.Session(openTestDB(t, Config{}), filepath.Join("..", "escape")); err == nil {
+		t.Errorf("expected an error for tenants with path separators")
+	}
+}
@@ -0,0 +1,75 @@
+package duckdb
+
+import (
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TPCOptions configure where GenerateTPCH and GenerateTPCDS create the tables
+type TPCOptions struct {
+	// Catalog and Schema are where the tables are created, the current ones by
+	// default
+	Catalog string
+	Schema  string
+	// Suffix is appended to the table names, e.g. _sf1 to keep several scale
+	// factors side by side
+	Suffix string
+	// Overwrite replaces existing tables of the same names
+	Overwrite bool
+}
+
+// GenerateTPCH creates the tables of the TPC-H benchmark, filled with data of
+// scaleFactor, e.g. 1 for about 1GB, through the tpch extension:
+//
+//	if err := duckdb.GenerateTPCH(db, 0.1); err != nil {
+//		...
+//	}
+//	db.Table("lineitem").Count(&count)
+//
+// The extension is installed first when it is missing, which needs network
+// access unless it is already in the extension directory.
+func GenerateTPCH(db *gorm.DB, scaleFactor float64, options ...TPCOptions) error {
+	return generateTPC(db, "tpch", "dbgen", scaleFactor, options)
+}
+
+// GenerateTPCDS creates the tables of the TPC-DS benchmark like GenerateTPCH,
+// through the tpcds extension
+func GenerateTPCDS(db *gorm.DB, scaleFactor float64, options ...TPCOptions) error {
+	return generateTPC(db, "tpcds", "dsdgen", scaleFactor, options)
+}
+
+func generateTPC(db *gorm.DB, extension, function string, scaleFactor float64, options []TPCOptions) error {
+	var opts TPCOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true})
+	if err := tx.Exec("INSTALL " + extension).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("LOAD " + extension).Error; err != nil {
+		return err
+	}
+	return tx.Exec(tpcGenerateSQL(function, scaleFactor, opts)).Error
+}
+
+// tpcGenerateSQL returns the call of the data generating function
+func tpcGenerateSQL(function string, scaleFactor float64, opts TPCOptions) string {
+	params := []string{"sf = " + strconv.FormatFloat(scaleFactor, 'f', -1, 64)}
+	if opts.Catalog != "" {
+		params = append(params, "catalog = "+quoteString(opts.Catalog))
+	}
+	if opts.Schema != "" {
+		params = append(params, "schema = "+quoteString(opts.Schema))
+	}
+	if opts.Suffix != "" {
+		params = append(params, "suffix = "+quoteString(opts.Suffix))
+	}
+	if opts.Overwrite {
+		params = append(params, "overwrite = true")
+	}
+	return "CALL " + function + "(" + strings.Join(params, ", ") + ")"
+}
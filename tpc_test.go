@@ -0,0 +1,27 @@
+package duckdb
+
+import "testing"
+
+func Test_tpcGenerateSQL(t *testing.T) {
+	tests := []struct {
+		name        string
+		function    string
+		scaleFactor float64
+		opts        TPCOptions
+		want        string
+	}{
+		{name: "it should pass the scale factor", function: "dbgen", scaleFactor: 0.01, want: "CALL dbgen(sf = 0.01)"},
+		{
+			name: "it should pass the options", function: "dsdgen", scaleFactor: 1,
+			opts: TPCOptions{Catalog: "bench", Schema: "tpc'ds", Suffix: "_sf1", Overwrite: true},
+			want: "CALL dsdgen(sf = 1, catalog = 'bench', schema = 'tpc''ds', suffix = '_sf1', overwrite = true)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tpcGenerateSQL(tt.function, tt.scaleFactor, tt.opts); got != tt.want {
+				t.Errorf("tpcGenerateSQL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// ErrNestedRollback is returned by nested transactions that fail, and by the
+// commit of the transactions enclosing them: DuckDB has no savepoints to roll
+// back to, so the work of the enclosing transaction is rolled back as a whole
+var ErrNestedRollback = errors.New("DuckDB does not support savepoints, the enclosing transaction is rolled back")
+
+// savePointName matches the names of savepoints, such as gorm's sp0xc000123456
+var savePointName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// checkSavePointName returns an error for names that aren't plain identifiers
+func checkSavePointName(name string) error {
+	if !savePointName.MatchString(name) {
+		return fmt.Errorf("invalid savepoint name %q", name)
+	}
+	return nil
+}
+
+// connPool is the pool of connections opened by the dialector. Its transactions
+// interrupt the statements still running in them when rolled back.
+type connPool struct {
+	*sql.DB
+	connector *connector
+	// readers are the connections queries are sent to, see Config.ReadConns
+	readers *sql.DB
+}
+
+// BeginTx starts a transaction, which gorm uses for Begin and Transaction
+func (p *connPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	conn, err := p.poolOf(opts).Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	done, cancel := context.WithCancel(context.Background())
+	return &interruptibleTx{Tx: tx, conn: conn, pool: p, done: done, cancel: cancel}, nil
+}
+
+// GetDBConn returns the pool for gorm's DB
+func (p *connPool) GetDBConn() (*sql.DB, error) {
+	return p.DB, nil
+}
+
+// interruptibleTx is a transaction whose statements run with contexts canceled
+// once it ends. database/sql waits for running statements before it rolls back,
+// so a query left running by a failed Transaction, e.g. in another goroutine,
+// would otherwise hold the rollback until it completes.
+type interruptibleTx struct {
+	*sql.Tx
+	// conn is the connection of the transaction, which appenders share to
+	// append rows in it
+	conn   *sql.Conn
+	pool   *connPool
+	done   context.Context
+	cancel context.CancelFunc
+	// aborted is the error of the nested transaction that failed in it, which
+	// keeps it from committing
+	aborted error
+}
+
+// releaseKey holds the function releasing the context of a query, which the
+// connection calls once the rows of the query are closed, see conn.QueryContext
+type releaseKey struct{}
+
+// statementContext returns ctx canceled when the transaction ends, which the
+// driver turns into an interrupt of the running statement, and the function
+// releasing it once the statement is done
+func (tx *interruptibleTx) statementContext(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(tx.done, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// queryContext returns the statement context of a query, released when its
+// rows are closed, as for sql.Row once it is scanned, or when it fails
+func (tx *interruptibleTx) queryContext(ctx context.Context) (context.Context, func()) {
+	ctx, release := tx.statementContext(ctx)
+	return withRelease(ctx, release), release
+}
+
+// withRelease returns ctx releasing release once the rows of the query are
+// closed, after any release ctx already holds
+func withRelease(ctx context.Context, release func()) context.Context {
+	if outer, ok := ctx.Value(releaseKey{}).(func()); ok {
+		inner := release
+		release = func() {
+			inner()
+			outer()
+		}
+	}
+	return context.WithValue(ctx, releaseKey{}, release)
+}
+
+func (tx *interruptibleTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, release := tx.statementContext(ctx)
+	defer release()
+	return tx.Tx.ExecContext(ctx, query, args...)
+}
+
+func (tx *interruptibleTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, release := tx.queryContext(ctx)
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		release()
+	}
+	return rows, err
+}
+
+func (tx *interruptibleTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, release := tx.queryContext(ctx)
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	if row.Err() != nil {
+		release()
+	}
+	return row
+}
+
+func (tx *interruptibleTx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, release := tx.statementContext(ctx)
+	defer release()
+	return tx.Tx.PrepareContext(ctx, query)
+}
+
+// abort makes the transaction roll back when committed, failing with err
+func (tx *interruptibleTx) abort(err error) {
+	if tx.aborted == nil {
+		tx.aborted = err
+	}
+}
+
+// Commit commits the transaction, after which its statements are done. Aborted
+// transactions are rolled back instead.
+func (tx *interruptibleTx) Commit() error {
+	if tx.aborted != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return tx.aborted
+	}
+	defer tx.conn.Close()
+	defer tx.cancel()
+	return tx.Tx.Commit()
+}
+
+// Rollback interrupts the running statements before rolling back
+func (tx *interruptibleTx) Rollback() error {
+	defer tx.conn.Close()
+	tx.cancel()
+	return tx.Tx.Rollback()
+}
+
+// GetDBConn returns the pool the transaction was started on for gorm's DB
+func (tx *interruptibleTx) GetDBConn() (*sql.DB, error) {
+	return tx.pool.DB, nil
+}
@@ -0,0 +1,210 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type txTestRecord struct {
+	ID   uint
+	Name string
+}
+
+func TestReadOnlyTransaction(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.Exec("CREATE TABLE tx_test_records (id INTEGER PRIMARY KEY, name VARCHAR)").Error; err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	if err := db.Create(&txTestRecord{ID: 1, Name: "a"}).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&txTestRecord{}).Count(&count).Error; err != nil || count != 1 {
+			t.Errorf("expected reads to succeed in a read-only transaction, got count %v, error %v", count, err)
+		}
+		if err := tx.Create(&txTestRecord{ID: 2, Name: "b"}).Error; err == nil {
+			t.Errorf("expected writes to fail in a read-only transaction")
+		}
+		return nil
+	}, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Errorf("failed to commit read-only transaction, got error %v", err)
+	}
+
+	// the connection is usable for regular transactions afterwards
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&txTestRecord{ID: 3, Name: "c"}).Error
+	}); err != nil {
+		t.Errorf("failed to run read-write transaction, got error %v", err)
+	}
+}
+
+func TestTransaction_rollbackInterruptsQueries(t *testing.T) {
+	db := openTestDB(t, Config{})
+
+	queryErr := make(chan error, 1)
+	start := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		started := make(chan struct{})
+		go func() {
+			close(started)
+			var sum float64
+			queryErr <- tx.Raw("SELECT sum(a.range * b.range)::DOUBLE FROM range(200000) a, range(200000) b").Scan(&sum).Error
+		}()
+		<-started
+		time.Sleep(100 * time.Millisecond)
+		if _, err := tx.DB(); err != nil {
+			t.Errorf("failed to get pool of transaction, got error %v", err)
+		}
+		return errors.New("failed")
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("expected the transaction error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected rollback to interrupt the running query, took %v", elapsed)
+	}
+	select {
+	case err := <-queryErr:
+		if err == nil {
+			t.Errorf("expected the interrupted query to fail")
+		}
+	case <-time.After(10 * time.Second):
+		t.Errorf("expected the interrupted query to return")
+	}
+
+	// committed transactions are unaffected
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Exec("CREATE TABLE tx_interrupt_records (id INTEGER)").Error
+	}); err != nil || !db.Migrator().HasTable("tx_interrupt_records") {
+		t.Errorf("failed to commit transaction, got error %v", err)
+	}
+}
+
+// countingContext counts the contexts derived from it that are not released
+// yet, which context.WithCancel registers through its AfterFunc method
+type countingContext struct {
+	context.Context
+	done    chan struct{}
+	pending atomic.Int64
+}
+
+func (c *countingContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *countingContext) AfterFunc(f func()) func() bool {
+	c.pending.Add(1)
+	var once sync.Once
+	return func() (stopped bool) {
+		once.Do(func() {
+			c.pending.Add(-1)
+			stopped = true
+		})
+		return stopped
+	}
+}
+
+func TestTransaction_releasesStatementContexts(t *testing.T) {
+	db := openTestDB(t, Config{})
+	tx := db.Begin()
+	defer tx.Rollback()
+	pool, ok := tx.Statement.ConnPool.(*interruptibleTx)
+	if !ok {
+		t.Fatalf("expected an interruptible transaction, got %T", tx.Statement.ConnPool)
+	}
+
+	ctx := &countingContext{Context: context.Background(), done: make(chan struct{})}
+	for i := 0; i < 3; i++ {
+		if _, err := pool.ExecContext(ctx, "CREATE OR REPLACE TABLE tx_release_records (id INTEGER)"); err != nil {
+			t.Fatalf("failed to execute, got error %v", err)
+		}
+		var n int
+		if err := pool.QueryRowContext(ctx, "SELECT 1").Scan(&n); err != nil {
+			t.Fatalf("failed to query row, got error %v", err)
+		}
+		rows, err := pool.QueryContext(ctx, "SELECT * FROM range(3)")
+		if err != nil {
+			t.Fatalf("failed to query, got error %v", err)
+		}
+		rows.Close()
+		if _, err := pool.QueryContext(ctx, "SELECT * FROM no_such_table"); err == nil {
+			t.Fatalf("expected the query of a missing table to fail")
+		}
+	}
+	if pending := ctx.pending.Load(); pending != 0 {
+		t.Errorf("expected the statement contexts to be released, got %d pending", pending)
+	}
+}
+
+func TestTransaction_nested(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&txTestRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	count := func() (count int64) {
+		if err := db.Model(&txTestRecord{}).Count(&count).Error; err != nil {
+			t.Fatalf("failed to count, got error %v", err)
+		}
+		return count
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&txTestRecord{Name: "outer"}).Error; err != nil {
+			return err
+		}
+		return tx.Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&txTestRecord{Name: "nested"}).Error
+		})
+	}); err != nil {
+		t.Fatalf("failed to run nested transactions, got error %v", err)
+	}
+	if n := count(); n != 2 {
+		t.Errorf("expected nested transactions to commit 2 records, got %d", n)
+	}
+
+	// failed nested transactions can't be rolled back on their own, so the
+	// enclosing transaction fails too, even when the error is ignored
+	failure := errors.New("nested failure")
+	var nestedErr error
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&txTestRecord{Name: "kept"}).Error; err != nil {
+			return err
+		}
+		nestedErr = tx.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&txTestRecord{Name: "discarded"}).Error; err != nil {
+				return err
+			}
+			return failure
+		})
+		return nil
+	})
+	if !errors.Is(nestedErr, failure) {
+		t.Errorf("expected the error of the nested transaction, got %v", nestedErr)
+	}
+	if !errors.Is(err, ErrNestedRollback) {
+		t.Errorf("expected ErrNestedRollback, got %v", err)
+	}
+	if n := count(); n != 2 {
+		t.Errorf("expected the enclosing transaction to roll back, got %d records", n)
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Transaction(func(tx *gorm.DB) error { return failure })
+	}); !errors.Is(err, failure) {
+		t.Errorf("expected the error of the nested transaction, got %v", err)
+	}
+
+	if err := (Dialector{}).SavePoint(db, "sp; DROP TABLE tx_test_records"); err == nil {
+		t.Errorf("expected invalid savepoint names to fail")
+	}
+}
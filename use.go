@@ -0,0 +1,53 @@
+package duckdb
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrUseUnsupported is returned by Use for connection pools not opened by the
+// dialector
+var ErrUseUnsupported = errors.New("Use requires the connection pool opened by the DuckDB dialector")
+
+// Use makes schema of catalog the default for tables and other objects named
+// without qualification, like DuckDB's USE statement but for every connection
+// of the pool, including the ones opened later:
+//
+//	duckdb.Attach(db, "archive.duckdb", "archive")
+//	duckdb.Use(db, "archive", "main")
+//	db.AutoMigrate(&Order{}) // creates "archive"."main"."orders"
+//
+// An empty catalog is the current one and an empty schema its default. The
+// Migrator and naming resolve tables in the new target. Connections switch
+// before their next statement, except those in a transaction, which switch
+// once it ends.
+func Use(db *gorm.DB, catalog, schema string) error {
+	var c *connector
+	switch pool := db.Statement.ConnPool.(type) {
+	case *connPool:
+		c = pool.connector
+	case *interruptibleTx:
+		c = pool.pool.connector
+	}
+	if c == nil {
+		return ErrUseUnsupported
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true})
+	if catalog == "" {
+		if err := tx.Raw("SELECT CURRENT_DATABASE()").Row().Scan(&catalog); err != nil {
+			return err
+		}
+	}
+	target := quoteIdentifier(catalog)
+	if schema != "" {
+		target += "." + quoteIdentifier(schema)
+	}
+	// fails for unknown catalogs and schemas before any connection switches
+	if err := tx.Exec("USE " + target).Error; err != nil {
+		return err
+	}
+	c.use.Store(&target)
+	return nil
+}
@@ -0,0 +1,73 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+type useRecord struct {
+	Code string `gorm:"primaryKey"`
+}
+
+func TestUse(t *testing.T) {
+	db := openTestDB(t, Config{})
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get pool, got error %v", err)
+	}
+	sqlDB.SetMaxIdleConns(3)
+	if err := db.Exec("CREATE SCHEMA analytics").Error; err != nil {
+		t.Fatalf("failed to create schema, got error %v", err)
+	}
+
+	// open connections before switching, which must switch too
+	conns := make([]*sql.Conn, 3)
+	openConns := func() {
+		for i := range conns {
+			if conns[i], err = sqlDB.Conn(context.Background()); err != nil {
+				t.Fatalf("failed to open connection, got error %v", err)
+			}
+		}
+	}
+	closeConns := func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+	openConns()
+	closeConns()
+
+	if err := Use(db, "", "analytics"); err != nil {
+		t.Fatalf("failed to use schema, got error %v", err)
+	}
+	openConns()
+	for i, conn := range conns {
+		var current string
+		if err := conn.QueryRowContext(context.Background(), "SELECT CURRENT_SCHEMA()").Scan(&current); err != nil || current != "analytics" {
+			t.Errorf("expected connection %d to use analytics, got %s, error %v", i, current, err)
+		}
+	}
+	closeConns()
+
+	if err := db.AutoMigrate(&useRecord{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var schema string
+	if err := db.Raw("SELECT schema_name FROM duckdb_tables() WHERE table_name = 'use_records'").Scan(&schema).Error; err != nil || schema != "analytics" {
+		t.Errorf("expected the table in analytics, got %s, error %v", schema, err)
+	}
+	if !db.Migrator().HasTable(&useRecord{}) {
+		t.Errorf("expected the table to be found in analytics")
+	}
+
+	if err := Use(db, "", "missing"); err == nil {
+		t.Errorf("expected an unknown schema to fail")
+	}
+	if err := Use(db, "memory", "main"); err != nil {
+		t.Fatalf("failed to use main, got error %v", err)
+	}
+	if db.Migrator().HasTable(&useRecord{}) {
+		t.Errorf("expected the table not to be found in main")
+	}
+}
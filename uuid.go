@@ -0,0 +1,96 @@
+package duckdb
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// isUUIDField reports whether field holds a UUID: it has the uuid type tag or a
+// type named UUID, such as github.com/google/uuid.UUID. Such fields get UUID
+// columns, whose values are read as text, see convertRows.
+func isUUIDField(field *schema.Field) bool {
+	if strings.EqualFold(string(field.DataType), "uuid") {
+		return true
+	}
+	t := field.IndirectFieldType
+	return t.Name() == "UUID" && (t.Kind() == reflect.String || t.Kind() == reflect.Array && t.Len() == 16)
+}
+
+// generatedUUIDKey returns the primary key of s when it is a UUID without a
+// default, which DuckDB then generates with uuid()
+func generatedUUIDKey(s *schema.Schema) *schema.Field {
+	if s == nil || s.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	if field := s.PrioritizedPrimaryField; !field.HasDefaultValue && isUUIDField(field) {
+		return field
+	}
+	return nil
+}
+
+// returnUUIDKeys makes creates return the primary keys generated by DuckDB for
+// records without one, see buildValues. String fields get the key as text, as
+// DuckDB returns UUIDs as bytes.
+func returnUUIDKeys(db *gorm.DB) {
+	field := generatedUUIDKey(db.Statement.Schema)
+	if field == nil || db.Error != nil {
+		return
+	}
+	if _, ok := db.Statement.Clauses["RETURNING"]; ok {
+		return
+	}
+
+	key := clause.Column{Name: field.DBName}
+	if field.IndirectFieldType.Kind() == reflect.String {
+		quoted := db.Statement.Quote(field.DBName)
+		key = clause.Column{Name: quoted + "::VARCHAR AS " + quoted, Raw: true}
+	}
+	columns := []clause.Column{key}
+	for _, field := range db.Statement.Schema.FieldsWithDefaultDBValue {
+		columns = append(columns, clause.Column{Name: field.DBName})
+	}
+	db.Statement.AddClause(clause.Returning{Columns: columns})
+}
+
+// defaultUUIDKeys replaces zero primary keys generated by DuckDB in values by
+// DEFAULT
+func defaultUUIDKeys(stmt *gorm.Statement, values clause.Values) clause.Values {
+	field := generatedUUIDKey(stmt.Schema)
+	if field == nil {
+		return values
+	}
+	index := -1
+	for i, column := range values.Columns {
+		if column.Name == field.DBName {
+			index = i
+		}
+	}
+	if index < 0 {
+		return values
+	}
+
+	rows := make([][]interface{}, len(values.Values))
+	for i, row := range values.Values {
+		rows[i] = row
+		if value := reflect.ValueOf(row[index]); !value.IsValid() || value.IsZero() {
+			rows[i] = append([]interface{}{}, row...)
+			rows[i][index] = clause.Expr{SQL: "DEFAULT"}
+		}
+	}
+	values.Values = rows
+	return values
+}
+
+// uuidText returns the 16 bytes of a UUID value read from the database as text
+func uuidText(value driver.Value) driver.Value {
+	if b, ok := value.([]byte); ok && len(b) == 16 {
+		return uuid.UUID(b).String()
+	}
+	return value
+}
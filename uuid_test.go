@@ -0,0 +1,134 @@
+package duckdb
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type uuidOrder struct {
+	ID   uuid.UUID
+	Name string
+}
+
+type uuidTicket struct {
+	Code  string `gorm:"primaryKey;type:uuid"`
+	Title string
+}
+
+func TestUUIDPrimaryKey(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&uuidOrder{}, &uuidTicket{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var columnDefault string
+	if err := db.Raw("SELECT column_default FROM duckdb_columns() WHERE table_name = 'uuid_orders' AND column_name = 'id'").Scan(&columnDefault).Error; err != nil || columnDefault != "uuid()" {
+		t.Errorf("expected the key to default to uuid(), got %q, error %v", columnDefault, err)
+	}
+
+	t.Run("uuid type", func(t *testing.T) {
+		given := uuid.New()
+		orders := []uuidOrder{{Name: "a"}, {ID: given, Name: "b"}, {Name: "c"}}
+		if err := db.Create(&orders).Error; err != nil {
+			t.Fatalf("failed to create records, got error %v", err)
+		}
+		if orders[0].ID == uuid.Nil || orders[2].ID == uuid.Nil || orders[0].ID == orders[2].ID || orders[1].ID != given {
+			t.Errorf("expected generated keys to be returned and given ones kept, got %+v", orders)
+		}
+
+		var found uuidOrder
+		if err := db.First(&found, "id = ?", orders[2].ID).Error; err != nil || found.Name != "c" {
+			t.Errorf("expected to find the record by its generated key, got %+v, error %v", found, err)
+		}
+	})
+
+	t.Run("string", func(t *testing.T) {
+		ticket := uuidTicket{Title: "first"}
+		if err := db.Create(&ticket).Error; err != nil {
+			t.Fatalf("failed to create record, got error %v", err)
+		}
+		if _, err := uuid.Parse(ticket.Code); err != nil {
+			t.Errorf("expected a generated key as text, got %q, error %v", ticket.Code, err)
+		}
+		var count int64
+		if err := db.Model(&uuidTicket{}).Where("code = ?", ticket.Code).Count(&count).Error; err != nil || count != 1 {
+			t.Errorf("expected the returned key to match the row, got %d, error %v", count, err)
+		}
+	})
+}
+
+type uuidDocument struct {
+	Code     string    `gorm:"primaryKey"`
+	Ref      uuid.UUID `gorm:"default:uuid()"`
+	Text     string    `gorm:"type:uuid"`
+	Optional *uuid.UUID
+}
+
+func TestUUIDColumns(t *testing.T) {
+	db := openTestDB(t, Config{})
+	if err := db.AutoMigrate(&uuidDocument{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	var columns []struct {
+		ColumnName string
+		DataType   string
+	}
+	if err := db.Raw("SELECT column_name, data_type FROM duckdb_columns() WHERE table_name = 'uuid_documents' AND column_name <> 'code'").Scan(&columns).Error; err != nil {
+		t.Fatalf("failed to load columns, got error %v", err)
+	}
+	for _, column := range columns {
+		if column.DataType != "UUID" {
+			t.Errorf("expected %s to be UUID, got %s", column.ColumnName, column.DataType)
+		}
+	}
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	if err := db.Session(&gorm.Session{Logger: recorder}).AutoMigrate(&uuidDocument{}); err != nil {
+		t.Fatalf("failed to migrate again, got error %v", err)
+	}
+	if recorder.contains("ALTER TABLE") {
+		t.Errorf("expected UUID columns not to be altered, got %v", recorder.sql)
+	}
+
+	text := uuid.NewString()
+	optional := uuid.New()
+	documents := []uuidDocument{{Code: "a", Text: text, Optional: &optional}, {Code: "b", Text: text}}
+	if err := db.Create(&documents).Error; err != nil {
+		t.Fatalf("failed to create records, got error %v", err)
+	}
+	if documents[0].Ref == uuid.Nil || documents[0].Ref == documents[1].Ref {
+		t.Errorf("expected the defaults to be returned, got %v and %v", documents[0].Ref, documents[1].Ref)
+	}
+
+	var found []uuidDocument
+	if err := db.Order("code").Find(&found).Error; err != nil {
+		t.Fatalf("failed to find records, got error %v", err)
+	}
+	if len(found) != 2 || found[0].Ref != documents[0].Ref || found[0].Text != text || found[0].Optional == nil || *found[0].Optional != optional ||
+		found[1].Optional != nil {
+		t.Errorf("expected the UUIDs to be read back, got %+v", found)
+	}
+
+	var refs []string
+	if err := db.Model(&uuidDocument{}).Where("ref = ?", documents[1].Ref).Pluck("ref", &refs).Error; err != nil || len(refs) != 1 || refs[0] != documents[1].Ref.String() {
+		t.Errorf("expected to find the record by its UUID as text, got %v, error %v", refs, err)
+	}
+}
+
+func TestUUIDColumns_prepareStmt(t *testing.T) {
+	db := openTestDB(t, Config{}).Session(&gorm.Session{PrepareStmt: true})
+	if err := db.AutoMigrate(&uuidTicket{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	code := uuid.NewString()
+	if err := db.Create(&uuidTicket{Code: code, Title: "prepared"}).Error; err != nil {
+		t.Fatalf("failed to create record, got error %v", err)
+	}
+
+	var found uuidTicket
+	if err := db.First(&found, "code = ?", code).Error; err != nil || found.Code != code {
+		t.Errorf("expected the UUID to be read back as text, got %q, error %v", found.Code, err)
+	}
+}
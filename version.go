@@ -0,0 +1,70 @@
+package duckdb
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Feature is a DuckDB capability that is only available from a certain version on
+type Feature int
+
+const (
+	// FeatureCommentOn is COMMENT ON TABLE/COLUMN
+	FeatureCommentOn Feature = iota
+	// FeatureSecrets is CREATE SECRET and the secrets manager
+	FeatureSecrets
+	// FeatureArrayType is the fixed-size ARRAY type, e.g. FLOAT[384]
+	FeatureArrayType
+	// FeaturePeakMemoryMetric is the SYSTEM_PEAK_BUFFER_MEMORY profiling metric
+	FeaturePeakMemoryMetric
+)
+
+var featureVersions = map[Feature]string{
+	FeatureCommentOn:        "v0.10.1",
+	FeatureSecrets:          "v0.10.0",
+	FeatureArrayType:        "v0.10.0",
+	FeaturePeakMemoryMetric: "v1.2.0",
+}
+
+// Supports reports whether the linked DuckDB version supports feature. It
+// assumes support when the version is unknown.
+func (dialector Dialector) Supports(feature Feature) bool {
+	if dialector.Config == nil || dialector.Version == "" {
+		return true
+	}
+	return compareVersion(dialector.Version, featureVersions[feature]) >= 0
+}
+
+func detectVersion(conn gorm.ConnPool) (version string, err error) {
+	err = conn.QueryRowContext(context.Background(), "SELECT library_version FROM pragma_version()").Scan(&version)
+	return
+}
+
+// compareVersion compares two versions such as v1.1.3 or v1.2.0-dev42,
+// ignoring pre-release suffixes
+func compareVersion(a, b string) int {
+	pa, pb := parseVersion(a), parseVersion(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseVersion(version string) (parts [3]int) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	for i, s := range strings.SplitN(version, ".", 3) {
+		parts[i], _ = strconv.Atoi(s)
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package duckdb
+
+import "testing"
+
+func Test_compareVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{name: "it should treat equal versions as equal", a: "v1.1.3", b: "v1.1.3", want: 0},
+		{name: "it should compare patch versions", a: "v1.1.3", b: "v1.1.10", want: -1},
+		{name: "it should compare minor versions", a: "v0.10.1", b: "v0.9.2", want: 1},
+		{name: "it should ignore the v prefix", a: "1.2.0", b: "v1.2.0", want: 0},
+		{name: "it should ignore pre-release suffixes", a: "v1.2.0-dev42", b: "v1.2.0", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareVersion(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareVersion(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialector_Supports(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		feature Feature
+		want    bool
+	}{
+		{name: "it should assume support for unknown versions", version: "", feature: FeatureCommentOn, want: true},
+		{name: "it should support COMMENT ON from v0.10.1", version: "v1.1.3", feature: FeatureCommentOn, want: true},
+		{name: "it should not support COMMENT ON before v0.10.1", version: "v0.10.0", feature: FeatureCommentOn, want: false},
+		{name: "it should not support secrets before v0.10.0", version: "v0.9.2", feature: FeatureSecrets, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialector := Dialector{Config: &Config{Version: tt.version}}
+			if got := dialector.Supports(tt.feature); got != tt.want {
+				t.Errorf("Supports() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}